@@ -0,0 +1,331 @@
+package extractor
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Annotation 注解解析器 的解析结果
+// - // @rpc(id=12, timeout=3s) -> Name: rpc, Args: [{id 12} {timeout 3s}]
+type Annotation struct {
+	// 注解名称
+	Name string
+
+	// 注解相对于注释文本的字节偏移
+	Offset int
+
+	// 注解的参数，按照源码顺序
+	Args []*AnnotationArg
+}
+
+// AnnotationArg 注解的参数
+// - key=value 形式的参数: Key 与 Value 均不为空
+// - value 形式的参数: Key 为空
+type AnnotationArg struct {
+	Key, Value string
+
+	// 参数相对于注释文本的字节偏移
+	Offset int
+}
+
+// AnnotationSyntaxError 注解解析器 的语法错误
+type AnnotationSyntaxError struct {
+	// 错误相对于注释文本的字节偏移
+	Offset int
+
+	Msg string
+}
+
+func (e *AnnotationSyntaxError) Error() string { return e.Msg }
+
+// AnnotationParser 注解解析器，可替换为自定义的注解语法
+type AnnotationParser interface {
+	// Parse 解析去除 // 后的单行注释文本中的所有注解
+	// - 存在语法错误时返回已解析的注解以及 *AnnotationSyntaxError
+	Parse(text string) ([]*Annotation, error)
+}
+
+// defaultAnnotationParser 默认的注解解析器: @name 或 @name(key=value, value, key="quoted value")
+// - @ 必须位于注释文本的开头或空白之后
+type defaultAnnotationParser struct{}
+
+// DefaultAnnotationParser 默认的注解解析器
+var DefaultAnnotationParser AnnotationParser = defaultAnnotationParser{}
+
+func (defaultAnnotationParser) Parse(text string) ([]*Annotation, error) {
+	annotations := make([]*Annotation, 0)
+	for index := 0; index < len(text); index++ {
+		if text[index] != '@' || (index > 0 && !IsSpaceRune(rune(text[index-1]))) {
+			continue
+		}
+		nameEnd := index + 1
+		for nameEnd < len(text) && isAnnotationNameByte(text[nameEnd]) {
+			nameEnd++
+		}
+		if nameEnd == index+1 {
+			continue
+		}
+		annotation := &Annotation{Name: text[index+1 : nameEnd], Offset: index}
+		annotations = append(annotations, annotation)
+		index = nameEnd - 1
+		if nameEnd >= len(text) || text[nameEnd] != '(' {
+			continue
+		}
+		argsEnd, err := parseAnnotationArgs(text, nameEnd+1, annotation)
+		if err != nil {
+			return annotations, err
+		}
+		index = argsEnd
+	}
+	return annotations, nil
+}
+
+// parseAnnotationArgs 从 begin 开始解析注解的参数直到 )，返回 ) 的偏移
+func parseAnnotationArgs(text string, begin int, annotation *Annotation) (int, error) {
+	index := begin
+	skipSpace := func() {
+		for index < len(text) && IsSpaceRune(rune(text[index])) {
+			index++
+		}
+	}
+	// scanValue 扫描一个值，支持 "..." 和 `...` 形式
+	scanValue := func() (string, error) {
+		if index < len(text) && (text[index] == '"' || text[index] == '`') {
+			quote, err := strconv.QuotedPrefix(text[index:])
+			if err != nil {
+				return "", &AnnotationSyntaxError{Offset: index, Msg: fmt.Sprintf("annotation @%v: invalid quoted value", annotation.Name)}
+			}
+			value, _ := strconv.Unquote(quote)
+			index += len(quote)
+			return value, nil
+		}
+		valueBegin := index
+		for index < len(text) && !strings.ContainsRune(",=)", rune(text[index])) {
+			index++
+		}
+		return strings.TrimSpace(text[valueBegin:index]), nil
+	}
+
+	for {
+		skipSpace()
+		if index >= len(text) {
+			return index, &AnnotationSyntaxError{Offset: begin - 1, Msg: fmt.Sprintf("annotation @%v: missing )", annotation.Name)}
+		}
+		if text[index] == ')' && len(annotation.Args) == 0 {
+			return index, nil
+		}
+		arg := &AnnotationArg{Offset: index}
+		value, err := scanValue()
+		if err != nil {
+			return index, err
+		}
+		skipSpace()
+		if index < len(text) && text[index] == '=' {
+			if len(value) == 0 {
+				return index, &AnnotationSyntaxError{Offset: arg.Offset, Msg: fmt.Sprintf("annotation @%v: empty key", annotation.Name)}
+			}
+			index++
+			skipSpace()
+			arg.Key = value
+			if value, err = scanValue(); err != nil {
+				return index, err
+			}
+			skipSpace()
+		}
+		arg.Value = value
+		if len(arg.Key) == 0 && len(arg.Value) == 0 {
+			return index, &AnnotationSyntaxError{Offset: arg.Offset, Msg: fmt.Sprintf("annotation @%v: empty argument", annotation.Name)}
+		}
+		annotation.Args = append(annotation.Args, arg)
+		if index >= len(text) {
+			continue // missing )
+		}
+		switch text[index] {
+		case ',':
+			index++
+		case ')':
+			return index, nil
+		default:
+			return index, &AnnotationSyntaxError{Offset: index, Msg: fmt.Sprintf("annotation @%v: unexpected %q", annotation.Name, text[index])}
+		}
+	}
+}
+
+func isAnnotationNameByte(b byte) bool {
+	return b == '_' || b == '.' || b == '-' || unicode.IsLetter(rune(b)) || unicode.IsDigit(rune(b))
+}
+
+// GoAnnotationMeta 文档注释内的 注解 的 meta 数据
+type GoAnnotationMeta struct {
+	// 组合基本 meta 数据
+	// ast 节点，要求为注解所在的 *ast.Comment
+	// 以 ast 节点 为单位执行 AST/PrintAST/Expression/Format
+	*meta
+
+	// 注解所属的 meta 数据: *GoStructMeta，*GoFuncMeta，*GoMethodMeta，*GoVarMeta
+	owner Meta
+
+	// 注解名称
+	name string
+
+	// 注解的参数
+	args []*GoAnnotationArgMeta
+
+	// 注解在文件中的位置
+	pos token.Position
+}
+
+// GoAnnotationArgMeta 注解的参数 的 meta 数据
+type GoAnnotationArgMeta struct {
+	key, value string
+
+	// 参数在文件中的位置
+	pos token.Position
+}
+
+// GoAnnotationError 注解的语法错误，带有在文件中的位置
+type GoAnnotationError struct {
+	pos token.Position
+	err error
+}
+
+func (e *GoAnnotationError) Error() string       { return fmt.Sprintf("%v: %v", e.pos, e.err) }
+func (e *GoAnnotationError) Unwrap() error       { return e.err }
+func (e *GoAnnotationError) Pos() token.Position { return e.pos }
+
+// extractAnnotationMetas 使用注解解析器提取文档注释内所有 注解 的 meta 数据
+// - 仅解析 // 形式的注释
+// - 未指定注解解析器时使用 DefaultAnnotationParser
+func extractAnnotationMetas(m *meta, owner Meta, doc *ast.CommentGroup, parsers []AnnotationParser) ([]*GoAnnotationMeta, []*GoAnnotationError) {
+	if doc == nil {
+		return nil, nil
+	}
+	if len(parsers) == 0 {
+		parsers = []AnnotationParser{DefaultAnnotationParser}
+	}
+	annotationMetas, annotationErrors := make([]*GoAnnotationMeta, 0), make([]*GoAnnotationError, 0)
+	for _, c := range doc.List {
+		text, ok := strings.CutPrefix(c.Text, "//")
+		if !ok {
+			continue
+		}
+		// positionOf 将相对于注释文本的偏移转换为在文件中的位置，注释文本位于 // 之后
+		var commentPos *token.Position
+		positionOf := func(offset int) token.Position {
+			if commentPos == nil {
+				position := m.position(c.Pos())
+				commentPos = &position
+			}
+			position := *commentPos
+			if position.IsValid() {
+				position.Offset += 2 + offset
+				position.Column += 2 + offset
+			}
+			return position
+		}
+		for _, parser := range parsers {
+			annotations, err := parser.Parse(text)
+			for _, annotation := range annotations {
+				gam := &GoAnnotationMeta{
+					meta:  m.copyMeta(c),
+					owner: owner,
+					name:  annotation.Name,
+					args:  make([]*GoAnnotationArgMeta, 0, len(annotation.Args)),
+					pos:   positionOf(annotation.Offset),
+				}
+				for _, arg := range annotation.Args {
+					gam.args = append(gam.args, &GoAnnotationArgMeta{key: arg.Key, value: arg.Value, pos: positionOf(arg.Offset)})
+				}
+				annotationMetas = append(annotationMetas, gam)
+			}
+			if err != nil {
+				offset := 0
+				if syntaxError, ok := err.(*AnnotationSyntaxError); ok {
+					offset = syntaxError.Offset
+				}
+				annotationErrors = append(annotationErrors, &GoAnnotationError{pos: positionOf(offset), err: err})
+			}
+		}
+	}
+	return annotationMetas, annotationErrors
+}
+
+// -------------------------------- extractor --------------------------------
+
+// Arg 根据 key 获取注解的参数值
+func (gam *GoAnnotationMeta) Arg(key string) (string, bool) {
+	for _, arg := range gam.args {
+		if arg.key == key {
+			return arg.value, true
+		}
+	}
+	return "", false
+}
+
+// -------------------------------- extractor --------------------------------
+
+// FindAnnotated 搜索项目内所有带有指定名称注解的 struct，func，method，struct member
+// - 按照文件路径以及注解在文件中的位置排序
+// - 同一行声明的多个 struct member 的注解属于第一个 member
+// - 未指定注解解析器时使用 DefaultAnnotationParser
+func (gpm *GoProjectMeta) FindAnnotated(name string, parsers ...AnnotationParser) ([]*GoAnnotationMeta, []*GoAnnotationError) {
+	annotationMetas, annotationErrors := make([]*GoAnnotationMeta, 0), make([]*GoAnnotationError, 0)
+	collect := func(gams []*GoAnnotationMeta, gaes []*GoAnnotationError) {
+		for _, gam := range gams {
+			if gam.name == name {
+				annotationMetas = append(annotationMetas, gam)
+			}
+		}
+		annotationErrors = append(annotationErrors, gaes...)
+	}
+	for _, packageMeta := range gpm.Packages() {
+		for _, gfm := range packageMeta.Funcs() {
+			collect(gfm.Annotations(parsers...))
+		}
+		for _, gsm := range packageMeta.Structs() {
+			collect(gsm.Annotations(parsers...))
+			for _, gvm := range gsm.Members() {
+				// 同一行声明的多个 member 共享文档注释，只以第一个 member 解析一次
+				if field, ok := gvm.node.(*ast.Field); ok && len(field.Names) > 1 && field.Names[0].Name != gvm.ident {
+					continue
+				}
+				collect(gvm.Annotations(parsers...))
+			}
+			for _, gmm := range gsm.Methods() {
+				collect(gmm.Annotations(parsers...))
+			}
+		}
+	}
+	sort.Slice(annotationMetas, func(i, j int) bool {
+		return comparePosition(annotationMetas[i].pos, annotationMetas[j].pos)
+	})
+	sort.Slice(annotationErrors, func(i, j int) bool {
+		return comparePosition(annotationErrors[i].pos, annotationErrors[j].pos)
+	})
+	return annotationMetas, annotationErrors
+}
+
+// comparePosition 按照文件路径以及文件内的偏移比较位置
+func comparePosition(i, j token.Position) bool {
+	if i.Filename != j.Filename {
+		return i.Filename < j.Filename
+	}
+	return i.Offset < j.Offset
+}
+
+// -------------------------------- unit test --------------------------------
+
+func (gam *GoAnnotationMeta) Owner() Meta                  { return gam.owner }
+func (gam *GoAnnotationMeta) Name() string                 { return gam.name }
+func (gam *GoAnnotationMeta) Args() []*GoAnnotationArgMeta { return gam.args }
+func (gam *GoAnnotationMeta) Pos() token.Position          { return gam.pos }
+func (gaam *GoAnnotationArgMeta) Key() string              { return gaam.key }
+func (gaam *GoAnnotationArgMeta) Value() string            { return gaam.value }
+func (gaam *GoAnnotationArgMeta) Pos() token.Position      { return gaam.pos }
+
+// -------------------------------- unit test --------------------------------
@@ -1,242 +1,217 @@
 package extractor
 
-// import (
-// 	"go/ast"
-// )
-
-// type GoArgMeta struct {
-// 	*meta
-// }
-
-// func (gam *GoArgMeta) Head() *GoVariableMeta {
-// 	n := gam.node
-// SWITCH:
-// 	switch headExpr := n.(type) {
-// 	case *ast.SelectorExpr:
-// 		n = headExpr.X
-// 		goto SWITCH
-// 	case *ast.CallExpr:
-// 		n = headExpr.Fun
-// 		goto SWITCH
-// 	case *ast.Ident:
-// 		// 没有 Obj：包名
-// 		if headExpr.Obj == nil {
-// 			gim, err := ExtractGoImportMeta(gam.path, headExpr.String())
-// 			if err != nil {
-// 				// fmt.Printf("extract go import meta from path %v by alias %v occurs error: %v", gam.path, headExpr.String(), err)
-// 				return nil
-// 			}
-// 			return &GoVariableMeta{
-// 				meta:     gam.newMeta(headExpr),
-// 				name:     headExpr.String(),
-// 				typeMeta: gim,
-// 				typeEnum: TYPE_PKG_ALIAS,
-// 			}
-// 		}
-
-// 		// Obj 的类型：
-// 		// - *ast.Field：父级函数/方法的接收器或参数表
-// 		//   - Names 是签名
-// 		//   - Type 是类型名
-// 		// - *ast.AssignStmt：函数内局部变量
-// 		//   - Lhs 是左值
-// 		//   - Rhs 是右值
-// 		// - *ast.ValueSpec：包级全局变量
-// 		// - *ast.FuncDecl：包级函数
-// 		// gvm := &GoVariableMeta{meta: gam.newMeta(gam.node)}
-// 		// fmt.Printf("headExpr = %v\n", headExpr.String())
-// 		// if headExpr.Obj != nil {
-// 		// 	// 	gvm.node = headExpr.Obj
-// 		// 	fmt.Printf("headExpr.Obj = %+v\n", headExpr.Obj)
-// 		// }
-// 		// return gvm
-
-// 		switch obj := headExpr.Obj.Decl.(type) {
-// 		case *ast.ValueSpec:
-// 			return &GoVariableMeta{
-// 				meta:     gam.newMeta(obj),
-// 				name:     headExpr.String(),
-// 				typeMeta: gam.newMeta(obj.Type),
-// 				typeEnum: TYPE_VAR_FIELD,
-// 			}
-// 		case *ast.Field:
-// 			return &GoVariableMeta{
-// 				meta:     gam.newMeta(obj),
-// 				name:     headExpr.String(),
-// 				typeMeta: gam.newMeta(obj.Type),
-// 				typeEnum: TYPE_VAR_FIELD,
-// 			}
-// 		case *ast.AssignStmt:
-// 			ll, rl := len(obj.Lhs), len(obj.Rhs)
-// 			if ll > rl && rl == 1 { // := 在右值为多返回值函数时有且仅有一个
-// 				return &GoVariableMeta{
-// 					meta:     gam.newMeta(obj),
-// 					name:     headExpr.String(),
-// 					typeMeta: gam.newMeta(obj.Rhs[0]),
-// 					typeEnum: TYPE_ASSIGNMENT,
-// 				}
-// 			} else {
-// 				for index, lh := range obj.Lhs {
-// 					if lh.(*ast.Ident).String() == headExpr.String() {
-// 						return &GoVariableMeta{
-// 							meta:     gam.newMeta(obj),
-// 							name:     headExpr.String(),
-// 							typeMeta: gam.newMeta(obj.Rhs[index]),
-// 							typeEnum: TYPE_ASSIGNMENT,
-// 						}
-// 					}
-// 				}
-// 			}
-// 		case *ast.FuncDecl:
-// 			return &GoVariableMeta{
-// 				meta:     gam.newMeta(obj),
-// 				name:     headExpr.String(),
-// 				typeMeta: gam.newMeta(obj.Type.Results.List[0].Type),
-// 				typeEnum: TYPE_FUNC_CALL,
-// 			}
-// 		}
-// 		return nil
-// 	case *ast.BasicLit:
-// 		constantMeta := gam.newMeta(headExpr)
-// 		return &GoVariableMeta{
-// 			meta:     constantMeta,
-// 			name:     headExpr.Value,
-// 			typeMeta: constantMeta,
-// 			typeEnum: TYPE_CONSTANTS,
-// 		}
-// 	default:
-// 		return nil
-// 	}
-// }
-
-// func (gam *GoArgMeta) Slice() []*GoVariableMeta {
-// 	s := make([]*GoVariableMeta, 0, 8)
-// 	var joinFunc func(ast.Node) bool
-// 	joinFunc = func(n ast.Node) bool {
-// 		switch node := n.(type) {
-// 		case *ast.Ident:
-// 			if node.Obj == nil {
-// 				if len(s) == 0 {
-// 					gim, err := ExtractGoImportMeta(gam.path, node.String())
-// 					if err != nil {
-// 						return false
-// 					}
-// 					s = append(s, &GoVariableMeta{
-// 						meta:     gam.newMeta(node),
-// 						name:     node.String(),
-// 						typeMeta: gim,
-// 						typeEnum: TYPE_PKG_ALIAS,
-// 					})
-// 				} else {
-// 					m := gam.newMeta(node)
-// 					s = append(s, &GoVariableMeta{
-// 						meta:     m,
-// 						name:     node.String(),
-// 						typeMeta: m,
-// 						typeEnum: TYPE_VAR_FIELD,
-// 					})
-// 				}
-// 			} else {
-// 				switch obj := node.Obj.Decl.(type) {
-// 				case *ast.ValueSpec:
-// 					s = append(s, &GoVariableMeta{
-// 						meta:     gam.newMeta(obj),
-// 						name:     node.String(),
-// 						typeMeta: gam.newMeta(obj.Type),
-// 						typeEnum: TYPE_VAR_FIELD,
-// 					})
-// 				case *ast.Field:
-// 					s = append(s, &GoVariableMeta{
-// 						meta:     gam.newMeta(obj),
-// 						name:     node.String(),
-// 						typeMeta: gam.newMeta(obj.Type),
-// 						typeEnum: TYPE_VAR_FIELD,
-// 					})
-// 				case *ast.AssignStmt:
-// 					ll, rl := len(obj.Lhs), len(obj.Rhs)
-// 					if ll > rl && rl == 1 { // := 在右值为多返回值函数时有且仅有一个
-// 						s = append(s, &GoVariableMeta{
-// 							meta:     gam.newMeta(obj),
-// 							name:     node.String(),
-// 							typeMeta: gam.newMeta(obj.Rhs[0]),
-// 							typeEnum: TYPE_ASSIGNMENT,
-// 						})
-// 					} else {
-// 						for index, lh := range obj.Lhs {
-// 							if lh.(*ast.Ident).String() == node.String() {
-// 								s = append(s, &GoVariableMeta{
-// 									meta:     gam.newMeta(obj),
-// 									name:     node.String(),
-// 									typeMeta: gam.newMeta(obj.Rhs[index]),
-// 									typeEnum: TYPE_ASSIGNMENT,
-// 								})
-// 							}
-// 						}
-// 					}
-// 				case *ast.FuncDecl:
-// 					s = append(s, &GoVariableMeta{
-// 						meta:     gam.newMeta(obj),
-// 						name:     node.String(),
-// 						typeMeta: gam.newMeta(obj.Type.Results.List[0].Type),
-// 						typeEnum: TYPE_FUNC_CALL,
-// 					})
-// 				}
-// 			}
-// 			// typeEnum = TYPE_VAR_FIELD
-// 		case *ast.BasicLit:
-// 			constantMeta := gam.newMeta(node)
-// 			s = append(s, &GoVariableMeta{
-// 				meta:     constantMeta,
-// 				name:     node.Value,
-// 				typeMeta: constantMeta,
-// 				typeEnum: TYPE_CONSTANTS,
-// 			})
-// 		case *ast.CallExpr:
-// 			// typeEnum = TYPE_FUNC_CALL
-// 			ast.Inspect(node.Fun, joinFunc)
-// 			if l := len(s); l > 1 {
-// 				s[l-1].typeEnum = TYPE_FUNC_CALL
-// 			}
-// 			// typeEnum = TYPE_VAR_FIELD
-// 			return false
-// 		}
-// 		return true
-// 	}
-// 	ast.Inspect(gam.node, joinFunc)
-// 	return s
-// }
-
-// func (gam *GoArgMeta) next(last ast.Node) *GoVariableMeta {
-// 	var nextExpr ast.Node
-// 	var nextExprName string
-// 	var nextTypeEnum VariableTypeEnum
-// 	ast.Inspect(gam.node, func(n ast.Node) bool {
-// 		if IsSelectorNode(n) {
-// 			expr := n.(*ast.SelectorExpr)
-// 			if expr.X == last {
-// 				nextExpr = expr
-// 				nextExprName = expr.Sel.String()
-// 			}
-// 		}
-// 		return nextExpr == nil
-// 	})
-
-// 	// 再遍历一遍，把节点往上提升一层，为了处理 a.B() 的情况
-// 	ast.Inspect(gam.node, func(n ast.Node) bool {
-// 		if IsCallNode(n) {
-// 			expr := n.(*ast.CallExpr)
-// 			if expr.Fun == nextExpr {
-// 				nextExpr = expr
-// 				nextTypeEnum = TYPE_FUNC_CALL
-// 				return false
-// 			}
-// 		}
-// 		return true
-// 	})
-
-// 	return &GoVariableMeta{
-// 		meta:     gam.newMeta(nextExpr),
-// 		name:     nextExprName,
-// 		typeEnum: nextTypeEnum,
-// 	}
-// }
+import (
+	"go/ast"
+	"go/token"
+)
+
+type ArgOriginType int
+
+const (
+	ARG_ORIGIN_PACKAGE     = iota + 1 // import 的 package: pkg.Default
+	ARG_ORIGIN_RECEIVER               // method 的 receiver: s.cfg
+	ARG_ORIGIN_PARAM                  // 参数或命名返回值: ctx
+	ARG_ORIGIN_LOCAL                  // 局部变量或局部常量: v.Name
+	ARG_ORIGIN_PACKAGE_VAR            // 当前 package 的 var 或 const: defaultConfig
+	ARG_ORIGIN_FUNC                   // package 级 func 或内置 func 的返回值: newCtx()，len(s)
+	ARG_ORIGIN_LITERAL                // 字面量: 1，"s"，T{}，func() {}，nil，true
+	ARG_ORIGIN_OTHER                  // 其他: a + b，无法解析的标识
+)
+
+// GoArgMeta go 调用的参数 的 meta 数据
+type GoArgMeta struct {
+	// 组合基本 meta 数据
+	// ast 节点，要求为参数的表达式 ast.Expr
+	// 以 ast 节点 为单位执行 AST/PrintAST/Expression/Format
+	*meta
+
+	// 参数在调用中的下标
+	index int
+
+	// 参数所属的调用的 meta 数据
+	callMeta *GoCallMeta
+}
+
+// GoArgOriginMeta 调用的参数的源头 的 meta 数据
+// - s.cfg.Timeout() -> originType: ARG_ORIGIN_RECEIVER, ident: s
+// - pkg.Default -> originType: ARG_ORIGIN_PACKAGE, ident: pkg, importPath: pkg 的导入路径
+type GoArgOriginMeta struct {
+	// 组合基本 meta 数据
+	// ast 节点，要求为源头的 *ast.Ident 或字面量表达式
+	// 以 ast 节点 为单位执行 AST/PrintAST/Expression/Format
+	*meta
+
+	// 源头所属的参数的 meta 数据
+	argMeta *GoArgMeta
+
+	// 源头的种类
+	originType ArgOriginType
+
+	// 源头的标识，字面量为空
+	ident string
+
+	// import 的 package 的导入路径
+	importPath string
+
+	// 源头为局部标识时的作用域分析结果
+	object *scopeObject
+}
+
+// -------------------------------- extractor --------------------------------
+
+// Head 沿着 selector，调用，下标，取地址，解引用，类型断言追溯参数的源头
+// - s.cfg.ctx -> s
+// - f(x).Y -> f
+// - int(x) 类型转换追溯到 x
+func (gam *GoArgMeta) Head() *GoArgOriginMeta {
+	ctx := gam.callMeta.ctx
+	expr := gam.node.(ast.Expr)
+	for {
+		switch e := expr.(type) {
+		case *ast.ParenExpr:
+			expr = e.X
+			continue
+		case *ast.SelectorExpr:
+			if ident, ok := e.X.(*ast.Ident); ok && ctx.isImport(ident) {
+				expr = ident
+			} else {
+				expr = e.X
+			}
+			continue
+		case *ast.CallExpr:
+			if gcm := newGoCallMeta(gam.copyMeta(e), ctx); gcm.callType == CALL_TYPE_CONVERSION && len(e.Args) == 1 {
+				expr = e.Args[0]
+			} else {
+				expr = e.Fun
+			}
+			continue
+		case *ast.IndexExpr:
+			expr = e.X
+			continue
+		case *ast.IndexListExpr:
+			expr = e.X
+			continue
+		case *ast.SliceExpr:
+			expr = e.X
+			continue
+		case *ast.StarExpr:
+			expr = e.X
+			continue
+		case *ast.TypeAssertExpr:
+			expr = e.X
+			continue
+		case *ast.UnaryExpr:
+			if e.Op == token.AND || e.Op == token.ARROW {
+				expr = e.X
+				continue
+			}
+		}
+		break
+	}
+
+	gaom := &GoArgOriginMeta{meta: gam.copyMeta(expr), argMeta: gam, originType: ARG_ORIGIN_OTHER}
+	switch e := expr.(type) {
+	case *ast.BasicLit, *ast.CompositeLit, *ast.FuncLit:
+		gaom.originType = ARG_ORIGIN_LITERAL
+	case *ast.Ident:
+		gaom.ident = e.Name
+		gaom.resolveIdent(e, ctx)
+	}
+	return gaom
+}
+
+// resolveIdent 解析源头标识的种类
+func (gaom *GoArgOriginMeta) resolveIdent(ident *ast.Ident, ctx *callContext) {
+	if obj := ctx.scopeInfo.uses[ident]; obj != nil {
+		gaom.object = obj
+		switch obj.kind {
+		case scopeObjectReceiver:
+			gaom.originType = ARG_ORIGIN_RECEIVER
+		case scopeObjectParam, scopeObjectResult:
+			gaom.originType = ARG_ORIGIN_PARAM
+		case scopeObjectVar, scopeObjectConst:
+			gaom.originType = ARG_ORIGIN_LOCAL
+		}
+		return
+	}
+	if ctx.isImport(ident) {
+		gaom.originType, gaom.importPath = ARG_ORIGIN_PACKAGE, ctx.fileMeta.SearchImport(ident.Name).importPath
+		return
+	}
+	if ctx.packageMeta != nil {
+		if ctx.packageMeta.SearchVarMeta(ident.Name) != nil || ctx.packageMeta.SearchConstMeta(ident.Name) != nil {
+			gaom.originType = ARG_ORIGIN_PACKAGE_VAR
+			return
+		}
+		if ctx.packageMeta.SearchFuncMeta(ident.Name) != nil {
+			gaom.originType = ARG_ORIGIN_FUNC
+			return
+		}
+	}
+	switch {
+	case ident.Name == "nil" || ident.Name == "true" || ident.Name == "false" || ident.Name == "iota":
+		gaom.originType = ARG_ORIGIN_LITERAL
+	case isPredeclaredFunc(ident.Name):
+		gaom.originType = ARG_ORIGIN_FUNC
+	}
+}
+
+// IsPassthrough 参数是否原样传递了未被修改过的参数或 receiver: f(ctx)
+// - 参数表达式仅为该标识，且该标识在参数之前没有被重新赋值
+func (gaom *GoArgOriginMeta) IsPassthrough() bool {
+	if gaom.object == nil || (gaom.originType != ARG_ORIGIN_PARAM && gaom.originType != ARG_ORIGIN_RECEIVER) {
+		return false
+	}
+	ident, ok := ast.Unparen(gaom.argMeta.node.(ast.Expr)).(*ast.Ident)
+	if !ok || ident != gaom.node {
+		return false
+	}
+	for _, write := range gaom.object.writes {
+		if write.Pos() < ident.Pos() {
+			return false
+		}
+	}
+	return true
+}
+
+// DeclPos 源头为局部标识时其声明的位置，否则为无效位置
+func (gaom *GoArgOriginMeta) DeclPos() token.Position {
+	if gaom.object == nil {
+		return token.Position{}
+	}
+	return gaom.position(gaom.object.ident.Pos())
+}
+
+// Chain 参数的 receiver/selector 链: s.cfg.ctx -> [s cfg ctx]
+func (gam *GoArgMeta) Chain() []string {
+	return selectorChain(ast.Unparen(gam.node.(ast.Expr)))
+}
+
+// ArgOrigins 获取调用的所有参数的源头，按照参数顺序
+func (gcm *GoCallMeta) ArgOrigins() []*GoArgOriginMeta {
+	origins := make([]*GoArgOriginMeta, 0, len(gcm.args))
+	for _, gam := range gcm.args {
+		origins = append(origins, gam.Head())
+	}
+	return origins
+}
+
+// IsEllipsis 参数是否以 ... 展开: f(args...)
+func (gam *GoArgMeta) IsEllipsis() bool {
+	callExpr := gam.callMeta.node.(*ast.CallExpr)
+	return callExpr.Ellipsis.IsValid() && gam.index == len(callExpr.Args)-1
+}
+
+// -------------------------------- extractor --------------------------------
+
+// -------------------------------- unit test --------------------------------
+
+func (gam *GoArgMeta) Index() int                       { return gam.index }
+func (gam *GoArgMeta) CallMeta() *GoCallMeta            { return gam.callMeta }
+func (gam *GoArgMeta) Pos() token.Position              { return gam.position(gam.node.Pos()) }
+func (gaom *GoArgOriginMeta) ArgMeta() *GoArgMeta       { return gaom.argMeta }
+func (gaom *GoArgOriginMeta) OriginType() ArgOriginType { return gaom.originType }
+func (gaom *GoArgOriginMeta) Ident() string             { return gaom.ident }
+func (gaom *GoArgOriginMeta) ImportPath() string        { return gaom.importPath }
+
+// -------------------------------- unit test --------------------------------
@@ -1,5 +1,441 @@
 package extractor
 
-// type GoAssignmentMeta struct {
-// 	*meta
-// }
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+type AssignmentType int
+
+const (
+	ASSIGNMENT_TYPE_DEFINE      = iota + 1 // 短变量声明: v := x
+	ASSIGNMENT_TYPE_VAR                    // var 声明: var v T = x
+	ASSIGNMENT_TYPE_RANGE                  // range 变量: for k, v := range x
+	ASSIGNMENT_TYPE_TYPE_SWITCH            // type switch 绑定: switch v := x.(type)，每个 case 一个
+)
+
+// GoAssignmentMeta go func 内的局部变量声明 的 meta 数据
+// - 每个声明的标识对应一个，a, b := f() 对应 a，b 两个
+// - 包含闭包内声明的局部变量，不包含参数，返回值，局部常量和局部类型
+type GoAssignmentMeta struct {
+	// 组合基本 meta 数据
+	// ast 节点，要求为声明所在的 *ast.AssignStmt，*ast.ValueSpec，*ast.RangeStmt 或 *ast.TypeSwitchStmt
+	// 以 ast 节点 为单位执行 AST/PrintAST/Expression/Format
+	*meta
+
+	// 声明所在的 func 的 meta 数据
+	funcMeta *GoFuncMeta
+
+	// 局部变量标识
+	ident string
+
+	// 声明的种类
+	assignmentType AssignmentType
+
+	// 局部变量的作用域分析结果
+	object *scopeObject
+
+	// 类型表达式，声明了类型时为声明的类型，否则为根据初始值推断的类型，无法推断时为 nil
+	typeExpr ast.Expr
+
+	// 类型是否是推断得到的
+	inferred bool
+}
+
+// newGoAssignmentMeta 通过局部标识构造 局部变量声明 的 meta 数据
+func newGoAssignmentMeta(m *meta, gfm *GoFuncMeta, obj *scopeObject, ctx *callContext) *GoAssignmentMeta {
+	gam := &GoAssignmentMeta{meta: m, funcMeta: gfm, ident: obj.ident.Name, object: obj}
+	switch obj.decl.(type) {
+	case *ast.AssignStmt:
+		gam.assignmentType = ASSIGNMENT_TYPE_DEFINE
+	case *ast.ValueSpec:
+		gam.assignmentType = ASSIGNMENT_TYPE_VAR
+	case *ast.RangeStmt:
+		gam.assignmentType = ASSIGNMENT_TYPE_RANGE
+	case *ast.TypeSwitchStmt:
+		gam.assignmentType = ASSIGNMENT_TYPE_TYPE_SWITCH
+	}
+	if obj.typeExpr != nil {
+		gam.typeExpr = obj.typeExpr
+	} else {
+		gam.typeExpr, gam.inferred = ctx.inferObjectType(obj, 0), true
+	}
+	return gam
+}
+
+// -------------------------------- extractor --------------------------------
+
+// Assignments 获取 func 内所有局部变量声明的 meta 数据，按照声明顺序
+func (gfm *GoFuncMeta) Assignments() []*GoAssignmentMeta {
+	info := gfm.scopeInfo()
+	ctx := newCallContext(gfm.packageMeta, gfm.path, info)
+	ctx.funcMeta = gfm
+	assignmentMetas := make([]*GoAssignmentMeta, 0)
+	for _, obj := range info.objects {
+		if obj.kind != scopeObjectVar {
+			continue
+		}
+		assignmentMetas = append(assignmentMetas, newGoAssignmentMeta(gfm.copyMeta(obj.decl), gfm, obj, ctx))
+	}
+	return assignmentMetas
+}
+
+// SearchAssignmentMeta 根据标识搜索 func 内的局部变量声明，同名的遮蔽声明按照声明顺序全部返回
+func (gfm *GoFuncMeta) SearchAssignmentMeta(ident string) []*GoAssignmentMeta {
+	assignmentMetas := make([]*GoAssignmentMeta, 0)
+	for _, gam := range gfm.Assignments() {
+		if gam.ident == ident {
+			assignmentMetas = append(assignmentMetas, gam)
+		}
+	}
+	return assignmentMetas
+}
+
+// TypeExpression 局部变量的类型表达式，无法推断时为空
+// - 推断的类型中其他 package 的类型使用调用时的包限定符: v := pkg.New() -> *pkg.T
+func (gam *GoAssignmentMeta) TypeExpression() string {
+	if gam.typeExpr == nil {
+		return ""
+	}
+	return types.ExprString(gam.typeExpr)
+}
+
+// ScopePos 局部变量作用域的起始位置
+// - :=，var: 声明语句的结束位置
+// - range: 循环体的起始位置
+// - type switch: case 的 : 之后
+func (gam *GoAssignmentMeta) ScopePos() token.Position {
+	var pos token.Pos
+	switch decl := gam.object.decl.(type) {
+	case *ast.AssignStmt:
+		pos = decl.End()
+	case *ast.ValueSpec:
+		pos = decl.End()
+	case *ast.RangeStmt:
+		pos = decl.Body.Pos()
+	case *ast.TypeSwitchStmt:
+		pos = gam.object.scope.node.(*ast.CaseClause).Colon + 1
+	}
+	return gam.position(pos)
+}
+
+// ScopeEnd 局部变量作用域的结束位置，即所在 block 的结束位置
+func (gam *GoAssignmentMeta) ScopeEnd() token.Position {
+	return gam.position(gam.object.scope.end())
+}
+
+// Reads 读取局部变量的所有位置，按照源码顺序
+func (gam *GoAssignmentMeta) Reads() []token.Position {
+	return identPositions(gam.meta, gam.object.reads)
+}
+
+// Writes 写入局部变量的所有位置，按照源码顺序，不包含声明
+// - v = x，v += x，v++，a, v := f() 中的重复声明
+func (gam *GoAssignmentMeta) Writes() []token.Position {
+	return identPositions(gam.meta, gam.object.writes)
+}
+
+func identPositions(m *meta, idents []*ast.Ident) []token.Position {
+	positions := make([]token.Position, 0, len(idents))
+	for _, ident := range idents {
+		positions = append(positions, m.position(ident.Pos()))
+	}
+	return positions
+}
+
+// inferObjectType 根据初始值推断局部标识的类型
+func (ctx *callContext) inferObjectType(obj *scopeObject, depth int) ast.Expr {
+	if obj.typeExpr != nil {
+		return obj.typeExpr
+	}
+	if obj.value == nil || depth > maxInferDepth {
+		return nil
+	}
+	switch decl := obj.decl.(type) {
+	case *ast.RangeStmt:
+		return ctx.inferRangeType(decl, obj.valueIndex, depth)
+	case *ast.TypeSwitchStmt:
+		// 多个类型的 case 中绑定的变量为 x 的类型
+		return ctx.inferExprType(obj.value, 0, depth)
+	}
+	return ctx.inferExprType(obj.value, obj.valueIndex, depth)
+}
+
+// maxInferDepth 类型推断的最大递归深度
+const maxInferDepth = 16
+
+// inferRangeType 推断 range 变量的类型
+func (ctx *callContext) inferRangeType(rangeStmt *ast.RangeStmt, index, depth int) ast.Expr {
+	switch xType := ctx.inferExprType(rangeStmt.X, 0, depth+1).(type) {
+	case *ast.ArrayType:
+		if index == 0 {
+			return ast.NewIdent("int")
+		}
+		return xType.Elt
+	case *ast.StarExpr:
+		// range 数组指针
+		if arrayType, ok := xType.X.(*ast.ArrayType); ok && arrayType.Len != nil {
+			if index == 0 {
+				return ast.NewIdent("int")
+			}
+			return arrayType.Elt
+		}
+	case *ast.MapType:
+		if index == 0 {
+			return xType.Key
+		}
+		return xType.Value
+	case *ast.ChanType:
+		if index == 0 {
+			return xType.Value
+		}
+	case *ast.Ident:
+		switch xType.Name {
+		case "string":
+			if index == 0 {
+				return ast.NewIdent("int")
+			}
+			return ast.NewIdent("rune")
+		case "int":
+			if index == 0 {
+				return ast.NewIdent("int")
+			}
+		}
+	}
+	return nil
+}
+
+// inferExprType 根据源码推断表达式的类型，多返回值的表达式推断第 index 个返回值
+// - 字面量: 1 -> int，"s" -> string，T{} -> T，&T{} -> *T，func() {} -> func()
+// - 局部变量以及 package 级变量: 声明的类型或推断的类型
+// - 调用: 类型转换，内置 func，项目内 func 和 method 的返回值
+// - 运算: 比较和逻辑运算 -> bool，其他运算 -> 左操作数的类型
+func (ctx *callContext) inferExprType(expr ast.Expr, index, depth int) ast.Expr {
+	if depth > maxInferDepth {
+		return nil
+	}
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return ctx.inferExprType(e.X, index, depth+1)
+	case *ast.BasicLit:
+		switch e.Kind {
+		case token.INT:
+			return ast.NewIdent("int")
+		case token.FLOAT:
+			return ast.NewIdent("float64")
+		case token.IMAG:
+			return ast.NewIdent("complex128")
+		case token.CHAR:
+			return ast.NewIdent("rune")
+		case token.STRING:
+			return ast.NewIdent("string")
+		}
+	case *ast.CompositeLit:
+		return e.Type
+	case *ast.FuncLit:
+		return e.Type
+	case *ast.UnaryExpr:
+		switch e.Op {
+		case token.AND:
+			if xType := ctx.inferExprType(e.X, 0, depth+1); xType != nil {
+				return &ast.StarExpr{X: xType}
+			}
+		case token.NOT:
+			return ast.NewIdent("bool")
+		case token.ARROW:
+			if index == 1 {
+				return ast.NewIdent("bool")
+			}
+			if chanType, ok := ctx.inferExprType(e.X, 0, depth+1).(*ast.ChanType); ok {
+				return chanType.Value
+			}
+		default:
+			return ctx.inferExprType(e.X, 0, depth+1)
+		}
+	case *ast.StarExpr:
+		if starExpr, ok := ctx.inferExprType(e.X, 0, depth+1).(*ast.StarExpr); ok {
+			return starExpr.X
+		}
+	case *ast.BinaryExpr:
+		switch e.Op {
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ, token.LAND, token.LOR:
+			return ast.NewIdent("bool")
+		case token.SHL, token.SHR:
+			return ctx.inferExprType(e.X, 0, depth+1)
+		}
+		// 无类型常量与有类型的操作数运算时为有类型的操作数的类型
+		if _, isLit := ast.Unparen(e.X).(*ast.BasicLit); isLit {
+			if yType := ctx.inferExprType(e.Y, 0, depth+1); yType != nil {
+				return yType
+			}
+		}
+		return ctx.inferExprType(e.X, 0, depth+1)
+	case *ast.TypeAssertExpr:
+		if index == 1 {
+			return ast.NewIdent("bool")
+		}
+		return e.Type
+	case *ast.IndexExpr:
+		xType := ctx.inferExprType(e.X, 0, depth+1)
+		if mapType, ok := xType.(*ast.MapType); ok {
+			if index == 1 {
+				return ast.NewIdent("bool")
+			}
+			return mapType.Value
+		}
+		if arrayType, ok := xType.(*ast.ArrayType); ok {
+			return arrayType.Elt
+		}
+		if ident, ok := xType.(*ast.Ident); ok && ident.Name == "string" {
+			return ast.NewIdent("byte")
+		}
+	case *ast.SliceExpr:
+		return ctx.inferExprType(e.X, 0, depth+1)
+	case *ast.Ident:
+		if obj := ctx.scopeInfo.uses[e]; obj != nil {
+			if obj.kind == scopeObjectVar || obj.kind == scopeObjectParam || obj.kind == scopeObjectResult || obj.kind == scopeObjectReceiver {
+				return ctx.inferObjectType(obj, depth+1)
+			}
+			return nil
+		}
+		switch e.Name {
+		case "true", "false":
+			return ast.NewIdent("bool")
+		}
+		if ctx.packageMeta != nil {
+			if gvm := ctx.packageMeta.SearchVarMeta(e.Name); gvm != nil && gvm.typeExpr != nil {
+				return gvm.typeExpr.node.(ast.Expr)
+			}
+		}
+	case *ast.SelectorExpr:
+		xType := ctx.typeOfExpr(e.X)
+		if gsm := xType.structMeta(); gsm != nil {
+			if gvm := gsm.memberMetaMap[e.Sel.Name]; gvm != nil && gvm.typeExpr != nil {
+				return ctx.qualifyTypeExpr(gvm.typeExpr.node.(ast.Expr), xType.packageMeta)
+			}
+		}
+		if ident, ok := e.X.(*ast.Ident); ok && ctx.isImport(ident) {
+			if gpm := ctx.fileMeta.SearchImport(ident.Name).packageMeta; gpm != nil {
+				if gvm := gpm.SearchVarMeta(e.Sel.Name); gvm != nil && gvm.typeExpr != nil {
+					return qualifyTypeExpr(gvm.typeExpr.node.(ast.Expr), ident.Name, gpm.typeIdents())
+				}
+			}
+			return nil
+		}
+		// 匿名 struct 的字段: q := struct{ P *T }{}; q.P
+		xTypeExpr := ctx.inferExprType(e.X, 0, depth+1)
+		if starExpr, ok := xTypeExpr.(*ast.StarExpr); ok {
+			xTypeExpr = starExpr.X
+		}
+		if structType, ok := xTypeExpr.(*ast.StructType); ok {
+			for _, field := range structType.Fields.List {
+				for _, name := range field.Names {
+					if name.Name == e.Sel.Name {
+						return field.Type
+					}
+				}
+			}
+		}
+	case *ast.CallExpr:
+		return ctx.inferCallType(e, index, depth)
+	}
+	return nil
+}
+
+// inferCallType 推断调用的第 index 个返回值的类型
+func (ctx *callContext) inferCallType(callExpr *ast.CallExpr, index, depth int) ast.Expr {
+	gcm := newGoCallMeta(ctx.newMeta(callExpr), ctx)
+	switch gcm.callType {
+	case CALL_TYPE_CONVERSION:
+		if index == 0 {
+			return ast.Unparen(callExpr.Fun)
+		}
+	case CALL_TYPE_BUILTIN:
+		switch gcm.callee {
+		case "new":
+			if len(callExpr.Args) == 1 {
+				return &ast.StarExpr{X: callExpr.Args[0]}
+			}
+		case "make":
+			if len(callExpr.Args) > 0 {
+				return callExpr.Args[0]
+			}
+		case "len", "cap", "copy":
+			return ast.NewIdent("int")
+		case "append":
+			if len(callExpr.Args) > 0 {
+				return ctx.inferExprType(callExpr.Args[0], 0, depth+1)
+			}
+		case "recover":
+			return ast.NewIdent("any")
+		case "real", "imag":
+			return ast.NewIdent("float64")
+		case "complex":
+			return ast.NewIdent("complex128")
+		}
+	case CALL_TYPE_FUNC, CALL_TYPE_METHOD:
+		gfm, _ := gcm.resolve()
+		if gfm == nil || index >= len(gfm.returns) || gfm.returns[index].typeExpr == nil {
+			return nil
+		}
+		return ctx.qualifyTypeExpr(gfm.returns[index].typeExpr.node.(ast.Expr), gfm.packageMeta)
+	}
+	return nil
+}
+
+// qualifyTypeExpr 类型表达式声明在其他 package 时，使用当前文件导入该 package 的标识作为包限定符
+func (ctx *callContext) qualifyTypeExpr(typeExpr ast.Expr, gpm *GoPackageMeta) ast.Expr {
+	if gpm == nil || gpm == ctx.packageMeta || ctx.fileMeta == nil {
+		return typeExpr
+	}
+	for _, gim := range ctx.fileMeta.importMetas {
+		if gim.packageMeta == gpm && !gim.IsBlank() && !gim.IsDot() {
+			return qualifyTypeExpr(typeExpr, gim.Ident(), gpm.typeIdents())
+		}
+	}
+	return typeExpr
+}
+
+// qualifyTypeExpr 为类型表达式中属于 typeIdents 的未限定类型加上包限定符: *T -> *pkg.T
+func qualifyTypeExpr(typeExpr ast.Expr, qualifier string, typeIdents map[string]struct{}) ast.Expr {
+	switch e := typeExpr.(type) {
+	case *ast.Ident:
+		if _, has := typeIdents[e.Name]; has {
+			return &ast.SelectorExpr{X: ast.NewIdent(qualifier), Sel: ast.NewIdent(e.Name)}
+		}
+	case *ast.StarExpr:
+		return &ast.StarExpr{X: qualifyTypeExpr(e.X, qualifier, typeIdents)}
+	case *ast.ArrayType:
+		return &ast.ArrayType{Len: e.Len, Elt: qualifyTypeExpr(e.Elt, qualifier, typeIdents)}
+	case *ast.MapType:
+		return &ast.MapType{Key: qualifyTypeExpr(e.Key, qualifier, typeIdents), Value: qualifyTypeExpr(e.Value, qualifier, typeIdents)}
+	case *ast.ChanType:
+		return &ast.ChanType{Dir: e.Dir, Value: qualifyTypeExpr(e.Value, qualifier, typeIdents)}
+	case *ast.Ellipsis:
+		return &ast.Ellipsis{Elt: qualifyTypeExpr(e.Elt, qualifier, typeIdents)}
+	case *ast.IndexExpr:
+		return &ast.IndexExpr{X: qualifyTypeExpr(e.X, qualifier, typeIdents), Index: qualifyTypeExpr(e.Index, qualifier, typeIdents)}
+	case *ast.IndexListExpr:
+		indices := make([]ast.Expr, 0, len(e.Indices))
+		for _, index := range e.Indices {
+			indices = append(indices, qualifyTypeExpr(index, qualifier, typeIdents))
+		}
+		return &ast.IndexListExpr{X: qualifyTypeExpr(e.X, qualifier, typeIdents), Indices: indices}
+	}
+	return typeExpr
+}
+
+// -------------------------------- extractor --------------------------------
+
+// -------------------------------- unit test --------------------------------
+
+func (gam *GoAssignmentMeta) FuncMeta() *GoFuncMeta          { return gam.funcMeta }
+func (gam *GoAssignmentMeta) Ident() string                  { return gam.ident }
+func (gam *GoAssignmentMeta) AssignmentType() AssignmentType { return gam.assignmentType }
+func (gam *GoAssignmentMeta) IsInferred() bool               { return gam.inferred && gam.typeExpr != nil }
+func (gam *GoAssignmentMeta) Pos() token.Position {
+	return gam.position(gam.object.ident.Pos())
+}
+
+// -------------------------------- unit test --------------------------------
@@ -1,107 +1,640 @@
 package extractor
 
-// import (
-// 	"fmt"
-// 	"go/ast"
-// 	"go/parser"
-// 	"go/token"
-// 	"strings"
-// )
-
-// type GoCallMeta struct {
-// 	*meta
-// 	from *GoFromMeta // TODO: 改成 GoVariableMeta
-// 	args []*GoArgMeta
-// }
-
-// func ParseGoCallMeta(expression string) *GoCallMeta {
-// 	callAST, err := parser.ParseExpr(expression)
-// 	if err != nil {
-// 		panic(err)
-// 	}
-// 	callExpr, ok := callAST.(*ast.CallExpr)
-// 	if !ok {
-// 		ast.Print(token.NewFileSet(), callAST)
-// 		panic(callAST)
-// 	}
-// 	return &GoCallMeta{
-// 		meta: &meta{node: callExpr},
-// 		args: make([]*GoArgMeta, 0),
-// 	}
-// }
-
-// func SearchGoCallMeta(m *meta, call string) []*GoCallMeta {
-// 	lastSelectorIndex := strings.LastIndex(call, ".")
-// 	searchSelector := lastSelectorIndex >= 0 && lastSelectorIndex < len(call)
-// 	var fromMeta *GoFromMeta
-// 	callMetaSlice := make([]*GoCallMeta, 0)
-// 	ast.Inspect(m.node, func(n ast.Node) bool {
-// 		if searchSelector && IsSelectorCallNode(n) {
-// 			fromMeta = &GoFromMeta{meta: m.newMeta(n.(*ast.CallExpr).Fun.(*ast.SelectorExpr).X)}
-// 			if fromMeta.Expression() == call[:lastSelectorIndex] && n.(*ast.CallExpr).Fun.(*ast.SelectorExpr).Sel.String() == call[lastSelectorIndex+1:] {
-// 				callMetaSlice = append(callMetaSlice, &GoCallMeta{
-// 					meta: m.newMeta(n.(*ast.CallExpr)),
-// 					from: fromMeta,
-// 				})
-// 			}
-// 		} else if !searchSelector && IsNonSelectorCallNode(n) {
-// 			if n.(*ast.CallExpr).Fun.(*ast.Ident).String() == call {
-// 				callMetaSlice = append(callMetaSlice, &GoCallMeta{
-// 					meta: m.newMeta(n.(*ast.CallExpr)),
-// 					from: fromMeta,
-// 				})
-// 			}
-// 		}
-// 		return true
-// 	})
-// 	return callMetaSlice
-// }
-
-// func ExtractGoCallMeta(m *meta) map[string][]*GoCallMeta {
-// 	callMetaMap := make(map[string][]*GoCallMeta, 0)
-// 	ast.Inspect(m.node, func(n ast.Node) bool {
-// 		if IsSelectorCallNode(n) {
-// 			fromMeta := &GoFromMeta{meta: m.newMeta(n.(*ast.CallExpr).Fun.(*ast.SelectorExpr).X)}
-// 			callExpression := fmt.Sprintf("%v.%v", fromMeta.Expression(), n.(*ast.CallExpr).Fun.(*ast.SelectorExpr).Sel.String())
-// 			callMetaMap[callExpression] = append(callMetaMap[callExpression], &GoCallMeta{
-// 				meta: m.newMeta(n.(*ast.CallExpr)),
-// 				from: fromMeta,
-// 			})
-// 		} else if IsNonSelectorCallNode(n) {
-// 			callExpression := n.(*ast.CallExpr).Fun.(*ast.Ident).String()
-// 			callMetaMap[callExpression] = append(callMetaMap[callExpression], &GoCallMeta{
-// 				meta: m.newMeta(n.(*ast.CallExpr)),
-// 			})
-// 		}
-// 		return true
-// 	})
-// 	return callMetaMap
-// }
-
-// func (gcm *GoCallMeta) From() string {
-// 	if gcm.from == nil {
-// 		return ""
-// 	}
-// 	return gcm.from.Expression()
-// }
-
-// func (gcm *GoCallMeta) Call() string {
-// 	if IsNonSelectorCallNode(gcm.node) {
-// 		return gcm.node.(*ast.CallExpr).Fun.(*ast.Ident).String()
-// 	} else if IsSelectorCallNode(gcm.node) {
-// 		return gcm.node.(*ast.CallExpr).Fun.(*ast.SelectorExpr).Sel.String()
-// 	}
-// 	return ""
-// }
-
-// func (gcm *GoCallMeta) Args() []*GoArgMeta {
-// 	callExpr := gcm.node.(*ast.CallExpr)
-// 	if len(callExpr.Args) == 0 {
-// 		return nil
-// 	}
-// 	args := make([]*GoArgMeta, 0, len(callExpr.Args))
-// 	for _, argExpr := range callExpr.Args {
-// 		args = append(args, &GoArgMeta{meta: gcm.meta.newMeta(argExpr)})
-// 	}
-// 	return args
-// }
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+)
+
+type CallType int
+
+const (
+	CALL_TYPE_FUNC       = iota + 1 // package 级 func: F()，pkg.F()，F[int]()
+	CALL_TYPE_METHOD                // method: x.M()，s.conn.Write()，f().M()
+	CALL_TYPE_CLOSURE               // 闭包或 func 类型的值: fn()，func() {}()，s.handler()
+	CALL_TYPE_BUILTIN               // 内置 func: len()，append()，panic()
+	CALL_TYPE_CONVERSION            // 类型转换: int(x)，[]byte(s)，pkg.T(x)
+)
+
+type CallReceiverType int
+
+const (
+	CALL_RECEIVER_LOCAL       = iota + 1 // 局部变量: v.M()
+	CALL_RECEIVER_PARAM                  // 参数或命名返回值: p.M()
+	CALL_RECEIVER_RECV                   // method 的 receiver: s.M()
+	CALL_RECEIVER_FIELD                  // 字段: s.conn.Write()
+	CALL_RECEIVER_PACKAGE_VAR            // package 级变量: v.M()，pkg.V.M()
+	CALL_RECEIVER_CALL                   // 调用的返回值: f().M()
+	CALL_RECEIVER_OTHER                  // 其他: a[i].M()，(*p).M()
+)
+
+// GoCallMeta go func 内的调用 的 meta 数据
+// - s.conn.Write(b) -> callee: Write, from: s.conn, chain: [s conn], args: [b]
+// - pkg.F(x) -> callee: F, from: pkg, chain: [pkg], importPath: pkg 的导入路径
+type GoCallMeta struct {
+	// 组合基本 meta 数据
+	// ast 节点，要求为 *ast.CallExpr
+	// 以 ast 节点 为单位执行 AST/PrintAST/Expression/Format
+	*meta
+
+	// 调用所在的 func 的 meta 数据
+	funcMeta *GoFuncMeta
+
+	// 调用的种类
+	callType CallType
+
+	// method 调用的 receiver 的种类，非 method 调用时为 0
+	receiverType CallReceiverType
+
+	// 被调用的标识: F，M，len，int，闭包字面量为空
+	callee string
+
+	// selector 调用的 X 表达式: pkg.F() -> pkg，s.conn.Write() -> s.conn
+	from string
+
+	// selector 调用的 receiver/selector 链，调用以 () 结尾，下标以 [] 结尾
+	// - s.conn.Write() -> [s conn]
+	// - a.b().c[i].M() -> [a b() c[]]
+	chain []string
+
+	// 被调用的 func 所属 package 的导入路径
+	// - pkg.F() 为 pkg 的导入路径
+	// - F() 为当前 package 的导入路径
+	importPath string
+
+	// 调用的参数
+	args []*GoArgMeta
+
+	// 解析调用时使用的上下文
+	ctx *callContext
+}
+
+// callContext 解析调用时使用的 func 或 package 级声明的上下文
+type callContext struct {
+	// 所在的 func 的 meta 数据，package 级声明时为 nil
+	funcMeta *GoFuncMeta
+
+	// 所在文件的绝对路径
+	path string
+
+	packageMeta *GoPackageMeta
+	fileMeta    *GoFileMeta
+	scopeInfo   *scopeInfo
+	typeIdents  map[string]struct{}
+}
+
+// newCallContext 构造上下文，不属于任何 package 时仅解析局部标识和内置标识
+// - scopeInfo 为 nil 时表示 package 级声明，没有局部标识
+func newCallContext(gpm *GoPackageMeta, path string, info *scopeInfo) *callContext {
+	if info == nil {
+		info = &scopeInfo{defs: make(map[*ast.Ident]*scopeObject), uses: make(map[*ast.Ident]*scopeObject)}
+	}
+	ctx := &callContext{path: path, packageMeta: gpm, scopeInfo: info}
+	if gpm != nil {
+		ctx.fileMeta = gpm.fileMetaMap[filepath.Base(path)]
+		ctx.typeIdents = gpm.typeIdents()
+	}
+	return ctx
+}
+
+// newMeta 构造所在文件内 ast 节点的 meta 数据
+func (ctx *callContext) newMeta(node ast.Node) *meta {
+	switch {
+	case ctx.funcMeta != nil:
+		return ctx.funcMeta.copyMeta(node)
+	case ctx.fileMeta != nil:
+		return ctx.fileMeta.copyMeta(node)
+	}
+	return newMeta(node, ctx.path)
+}
+
+// position 获取所在文件内 ast 节点位置的行列号
+func (ctx *callContext) position(pos token.Pos) token.Position { return ctx.newMeta(nil).position(pos) }
+
+// isImport 标识是否是文件中 import 的 package
+func (ctx *callContext) isImport(ident *ast.Ident) bool {
+	return ctx.scopeInfo.uses[ident] == nil && ctx.fileMeta != nil && ctx.fileMeta.SearchImport(ident.Name) != nil
+}
+
+// newGoCallMeta 通过 ast 构造 调用 的 meta 数据
+func newGoCallMeta(m *meta, ctx *callContext) *GoCallMeta {
+	callExpr := m.node.(*ast.CallExpr)
+	gcm := &GoCallMeta{meta: m, funcMeta: ctx.funcMeta, args: make([]*GoArgMeta, 0, len(callExpr.Args)), ctx: ctx}
+	for index, argExpr := range callExpr.Args {
+		gcm.args = append(gcm.args, &GoArgMeta{meta: m.copyMeta(argExpr), index: index, callMeta: gcm})
+	}
+	gcm.classify(ast.Unparen(callExpr.Fun), ctx)
+	return gcm
+}
+
+// -------------------------------- extractor --------------------------------
+
+// Calls 获取 func 内所有调用的 meta 数据，按照源码顺序，包含闭包内的调用
+// - f(g()) -> [f g]
+func (gfm *GoFuncMeta) Calls() []*GoCallMeta {
+	funcDecl, ok := gfm.node.(*ast.FuncDecl)
+	if !ok || funcDecl.Body == nil {
+		return nil
+	}
+	ctx := newCallContext(gfm.packageMeta, gfm.path, gfm.scopeInfo())
+	ctx.funcMeta = gfm
+	callMetas := make([]*GoCallMeta, 0)
+	ast.Inspect(funcDecl.Body, func(n ast.Node) bool {
+		if IsCallNode(n) {
+			callMetas = append(callMetas, newGoCallMeta(gfm.copyMeta(n), ctx))
+		}
+		return true
+	})
+	return callMetas
+}
+
+// SearchCallMeta 搜索 func 内所有指定的调用
+// - call 为 from.callee 形式时搜索 selector 调用: pkg.F，s.conn.Write
+// - call 为 callee 形式时搜索非 selector 调用: F，len
+func (gfm *GoFuncMeta) SearchCallMeta(call string) []*GoCallMeta {
+	from, callee := "", call
+	if index := strings.LastIndex(call, "."); index >= 0 {
+		from, callee = call[:index], call[index+1:]
+	}
+	callMetas := make([]*GoCallMeta, 0)
+	for _, gcm := range gfm.Calls() {
+		if gcm.callee == callee && gcm.from == from {
+			callMetas = append(callMetas, gcm)
+		}
+	}
+	return callMetas
+}
+
+// classify 根据被调用的表达式解析调用的种类
+func (gcm *GoCallMeta) classify(fun ast.Expr, ctx *callContext) {
+	switch funExpr := fun.(type) {
+	case *ast.Ident:
+		gcm.callee = funExpr.Name
+		gcm.classifyIdent(funExpr, ctx)
+	case *ast.SelectorExpr:
+		gcm.callee = funExpr.Sel.Name
+		gcm.from = types.ExprString(funExpr.X)
+		gcm.chain = selectorChain(funExpr.X)
+		gcm.classifySelector(funExpr, ctx)
+	case *ast.IndexExpr:
+		gcm.classifyIndex(funExpr, funExpr.X, ctx)
+	case *ast.IndexListExpr:
+		gcm.classifyIndex(funExpr, funExpr.X, ctx)
+	case *ast.ArrayType, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.InterfaceType, *ast.StructType, *ast.StarExpr:
+		gcm.callee = types.ExprString(funExpr)
+		gcm.callType = CALL_TYPE_CONVERSION
+	default:
+		// func() {}()，f()()，v.(func())()
+		gcm.callType = CALL_TYPE_CLOSURE
+	}
+}
+
+func (gcm *GoCallMeta) classifyIdent(ident *ast.Ident, ctx *callContext) {
+	if obj := ctx.scopeInfo.uses[ident]; obj != nil {
+		gcm.callType = CALL_TYPE_CLOSURE
+		if obj.kind == scopeObjectType {
+			gcm.callType = CALL_TYPE_CONVERSION
+		}
+		return
+	}
+	if ctx.packageMeta != nil {
+		if _, has := ctx.packageMeta.funcMetaMap[ident.Name]; has {
+			gcm.callType, gcm.importPath = CALL_TYPE_FUNC, ctx.packageMeta.importPath
+			return
+		}
+		if _, has := ctx.typeIdents[ident.Name]; has {
+			gcm.callType, gcm.importPath = CALL_TYPE_CONVERSION, ctx.packageMeta.importPath
+			return
+		}
+		if ctx.packageMeta.SearchVarMeta(ident.Name) != nil {
+			gcm.callType = CALL_TYPE_CLOSURE
+			return
+		}
+	}
+	switch {
+	case isPredeclaredFunc(ident.Name):
+		gcm.callType = CALL_TYPE_BUILTIN
+	case isPredeclaredType(ident.Name):
+		gcm.callType = CALL_TYPE_CONVERSION
+	default:
+		// . 导入或无法解析的 package 级 func
+		gcm.callType = CALL_TYPE_FUNC
+		if ctx.packageMeta != nil {
+			gcm.importPath = ctx.packageMeta.importPath
+		}
+		if ctx.fileMeta == nil {
+			return
+		}
+		for _, gim := range ctx.fileMeta.dotImports() {
+			if gim.packageMeta == nil {
+				continue
+			}
+			if _, has := gim.packageMeta.funcMetaMap[ident.Name]; has {
+				gcm.importPath = gim.importPath
+				return
+			}
+		}
+	}
+}
+
+func (gcm *GoCallMeta) classifySelector(selectorExpr *ast.SelectorExpr, ctx *callContext) {
+	gcm.callType = CALL_TYPE_METHOD
+	switch x := ast.Unparen(selectorExpr.X).(type) {
+	case *ast.Ident:
+		if obj := ctx.scopeInfo.uses[x]; obj != nil {
+			switch obj.kind {
+			case scopeObjectReceiver:
+				gcm.receiverType = CALL_RECEIVER_RECV
+			case scopeObjectParam, scopeObjectResult:
+				gcm.receiverType = CALL_RECEIVER_PARAM
+			case scopeObjectVar, scopeObjectConst:
+				gcm.receiverType = CALL_RECEIVER_LOCAL
+			default:
+				// 方法表达式: T.M(v)
+				gcm.receiverType = CALL_RECEIVER_OTHER
+			}
+			gcm.classifyFieldCall(localTypeIdent(obj), ctx)
+			return
+		}
+		if ctx.packageMeta != nil {
+			if gvm := ctx.packageMeta.SearchVarMeta(x.Name); gvm != nil {
+				gcm.receiverType = CALL_RECEIVER_PACKAGE_VAR
+				gcm.classifyFieldCall(gvm.typeIdent, ctx)
+				return
+			}
+			if _, has := ctx.typeIdents[x.Name]; has {
+				gcm.receiverType = CALL_RECEIVER_OTHER
+				return
+			}
+		}
+		if ctx.fileMeta != nil {
+			if gim := ctx.fileMeta.SearchImport(x.Name); gim != nil {
+				gcm.callType, gcm.receiverType, gcm.importPath = CALL_TYPE_FUNC, 0, gim.importPath
+				if gim.packageMeta != nil {
+					if _, has := gim.packageMeta.typeIdents()[gcm.callee]; has {
+						gcm.callType = CALL_TYPE_CONVERSION
+					} else if gim.packageMeta.SearchVarMeta(gcm.callee) != nil {
+						gcm.callType = CALL_TYPE_CLOSURE
+					}
+				}
+				return
+			}
+		}
+		gcm.receiverType = CALL_RECEIVER_OTHER
+	case *ast.SelectorExpr:
+		// pkg.V.M() 或 s.conn.Write()
+		gcm.receiverType = CALL_RECEIVER_FIELD
+		if head, ok := ast.Unparen(x.X).(*ast.Ident); ok && ctx.isImport(head) {
+			gcm.receiverType = CALL_RECEIVER_PACKAGE_VAR
+		}
+	case *ast.CallExpr:
+		gcm.receiverType = CALL_RECEIVER_CALL
+	default:
+		gcm.receiverType = CALL_RECEIVER_OTHER
+	}
+}
+
+// classifyFieldCall receiver 为当前 package 的 struct 且被调用的是 struct 的字段时视为闭包: s.handler()
+func (gcm *GoCallMeta) classifyFieldCall(typeIdent string, ctx *callContext) {
+	if ctx.packageMeta == nil || len(typeIdent) == 0 {
+		return
+	}
+	gsm := ctx.packageMeta.SearchStructMeta(typeIdent)
+	if gsm == nil {
+		return
+	}
+	if _, isMethod := gsm.methodMetaMap[gcm.callee]; isMethod {
+		return
+	}
+	if _, isMember := gsm.memberMetaMap[gcm.callee]; isMember {
+		gcm.callType = CALL_TYPE_CLOSURE
+	}
+}
+
+// classifyIndex 泛型 func 的实例化: F[int]()，pkg.F[int, string]()，否则为下标取到的 func 值: handlers[i]()
+func (gcm *GoCallMeta) classifyIndex(indexExpr, x ast.Expr, ctx *callContext) {
+	switch xExpr := ast.Unparen(x).(type) {
+	case *ast.Ident:
+		if ctx.scopeInfo.uses[xExpr] == nil && (ctx.packageMeta == nil || ctx.packageMeta.SearchVarMeta(xExpr.Name) == nil) {
+			gcm.classify(xExpr, ctx)
+			return
+		}
+	case *ast.SelectorExpr:
+		if head, ok := xExpr.X.(*ast.Ident); ok && ctx.isImport(head) {
+			gcm.classify(xExpr, ctx)
+			return
+		}
+	}
+	gcm.callType = CALL_TYPE_CLOSURE
+	gcm.chain = selectorChain(indexExpr)
+}
+
+// selectorChain 将表达式拆分为 receiver/selector 链
+func selectorChain(expr ast.Expr) []string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return []string{e.Name}
+	case *ast.SelectorExpr:
+		return append(selectorChain(e.X), e.Sel.Name)
+	case *ast.ParenExpr:
+		return selectorChain(e.X)
+	case *ast.StarExpr:
+		return selectorChain(e.X)
+	case *ast.CallExpr:
+		return appendChainSuffix(selectorChain(ast.Unparen(e.Fun)), "()")
+	case *ast.IndexExpr:
+		return appendChainSuffix(selectorChain(e.X), "[]")
+	case *ast.IndexListExpr:
+		return appendChainSuffix(selectorChain(e.X), "[]")
+	}
+	return []string{types.ExprString(expr)}
+}
+
+func appendChainSuffix(chain []string, suffix string) []string {
+	chain[len(chain)-1] += suffix
+	return chain
+}
+
+// localTypeIdent 获取局部标识的类型标识，无法从源码得到时为空
+// - 声明了类型: s *S，var s S
+// - 初始值为字面量: s := &S{}，s := S{}，s := new(S)
+func localTypeIdent(obj *scopeObject) string {
+	typeExpr := obj.typeExpr
+	if typeExpr == nil && obj.value != nil && obj.valueIndex == 0 {
+		switch value := ast.Unparen(obj.value).(type) {
+		case *ast.CompositeLit:
+			typeExpr = value.Type
+		case *ast.UnaryExpr:
+			if compositeLit, ok := value.X.(*ast.CompositeLit); ok && value.Op == token.AND {
+				typeExpr = compositeLit.Type
+			}
+		case *ast.CallExpr:
+			if ident, ok := value.Fun.(*ast.Ident); ok && ident.Name == "new" && len(value.Args) == 1 {
+				typeExpr = value.Args[0]
+			}
+		}
+	}
+	if typeExpr == nil {
+		return ""
+	}
+	for {
+		switch expr := typeExpr.(type) {
+		case *ast.Ident:
+			return expr.Name
+		case *ast.StarExpr:
+			typeExpr = expr.X
+		case *ast.IndexExpr:
+			typeExpr = expr.X
+		case *ast.IndexListExpr:
+			typeExpr = expr.X
+		case *ast.ParenExpr:
+			typeExpr = expr.X
+		default:
+			return ""
+		}
+	}
+}
+
+// namedType 命名类型所属的 package 以及标识，无法解析时 packageMeta 为 nil
+type namedType struct {
+	packageMeta *GoPackageMeta
+	ident       string
+}
+
+// newNamedType 通过已解析包限定符的类型表达式构造命名类型: *pkg.T -> pkg.T
+func newNamedType(gte *GoTypeExpr) namedType {
+	if gte == nil {
+		return namedType{}
+	}
+	if named := gte.Named(); named != nil {
+		return namedType{packageMeta: named.packageMeta, ident: named.ident}
+	}
+	return namedType{}
+}
+
+func (nt namedType) structMeta() *GoStructMeta {
+	if nt.packageMeta == nil {
+		return nil
+	}
+	return nt.packageMeta.structMetaMap[nt.ident]
+}
+
+func (nt namedType) interfaceMeta() *GoInterfaceMeta {
+	if nt.packageMeta == nil {
+		return nil
+	}
+	return nt.packageMeta.interfaceMetaMap[nt.ident]
+}
+
+// resolve 静态解析调用的 func 或 method 的 meta 数据
+// - 返回 method 调用的 receiver 的命名类型，receiver 为 interface 时无法静态解析 method
+// - 闭包，内置 func，类型转换以及项目外的 func 无法解析
+func (gcm *GoCallMeta) resolve() (*GoFuncMeta, namedType) {
+	ctx := gcm.ctx
+	fun := ast.Unparen(gcm.node.(*ast.CallExpr).Fun)
+	switch indexExpr := fun.(type) {
+	case *ast.IndexExpr:
+		fun = ast.Unparen(indexExpr.X)
+	case *ast.IndexListExpr:
+		fun = ast.Unparen(indexExpr.X)
+	}
+	switch gcm.callType {
+	case CALL_TYPE_FUNC:
+		gpm := ctx.packageMeta
+		if selectorExpr, ok := fun.(*ast.SelectorExpr); ok {
+			gpm = nil
+			if ident, ok := selectorExpr.X.(*ast.Ident); ok && ctx.isImport(ident) {
+				gpm = ctx.fileMeta.SearchImport(ident.Name).packageMeta
+			}
+		} else if gpm != nil && gcm.importPath != gpm.importPath && ctx.fileMeta != nil {
+			// . 导入的 func
+			for _, gim := range ctx.fileMeta.dotImports() {
+				if gim.importPath == gcm.importPath {
+					gpm = gim.packageMeta
+				}
+			}
+		}
+		if gpm == nil {
+			return nil, namedType{}
+		}
+		return gpm.funcMetaMap[gcm.callee], namedType{}
+	case CALL_TYPE_METHOD:
+		selectorExpr, ok := fun.(*ast.SelectorExpr)
+		if !ok {
+			return nil, namedType{}
+		}
+		receiverType := ctx.typeOfExpr(selectorExpr.X)
+		if gsm := receiverType.structMeta(); gsm != nil {
+			if gmm := gsm.searchPromotedMethodMeta(gcm.callee); gmm != nil {
+				return gmm.GoFuncMeta, receiverType
+			}
+		}
+		return nil, receiverType
+	}
+	return nil, namedType{}
+}
+
+// typeOfTypeExpr 解析类型表达式对应的命名类型，类型参数以及局部类型无法解析
+func (ctx *callContext) typeOfTypeExpr(typeExpr ast.Expr) namedType {
+	for {
+		switch expr := typeExpr.(type) {
+		case *ast.StarExpr:
+			typeExpr = expr.X
+		case *ast.ParenExpr:
+			typeExpr = expr.X
+		case *ast.IndexExpr:
+			typeExpr = expr.X
+		case *ast.IndexListExpr:
+			typeExpr = expr.X
+		case *ast.Ident:
+			if _, has := ctx.typeIdents[expr.Name]; has && ctx.scopeInfo.uses[expr] == nil {
+				return namedType{packageMeta: ctx.packageMeta, ident: expr.Name}
+			}
+			return namedType{}
+		case *ast.SelectorExpr:
+			if ident, ok := expr.X.(*ast.Ident); ok && ctx.isImport(ident) {
+				return namedType{packageMeta: ctx.fileMeta.SearchImport(ident.Name).packageMeta, ident: expr.Sel.Name}
+			}
+			return namedType{}
+		default:
+			return namedType{}
+		}
+	}
+}
+
+// typeOfExpr 根据源码推断表达式的命名类型
+// - 局部标识: 声明的类型，初始值的类型
+// - package 级变量: 声明的类型，初始值的类型
+// - 字段: struct 成员声明的类型
+// - 调用: 项目内 func 的第一个返回值的类型，类型转换的类型
+// - 其他: 通过 inferExprType 推断，包括下标，类型断言，接收 channel 以及匿名 struct 的字段
+func (ctx *callContext) typeOfExpr(expr ast.Expr) namedType {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return ctx.typeOfExpr(e.X)
+	case *ast.StarExpr:
+		return ctx.typeOfExpr(e.X)
+	case *ast.UnaryExpr:
+		if e.Op == token.AND {
+			return ctx.typeOfExpr(e.X)
+		}
+	case *ast.CompositeLit:
+		return ctx.typeOfTypeExpr(e.Type)
+	case *ast.CallExpr:
+		return ctx.typeOfResult(e, 0)
+	case *ast.Ident:
+		if obj := ctx.scopeInfo.uses[e]; obj != nil {
+			return ctx.typeOfObject(obj)
+		}
+		if ctx.packageMeta != nil {
+			if gvm := ctx.packageMeta.SearchVarMeta(e.Name); gvm != nil {
+				return typeOfVar(ctx.packageMeta, gvm)
+			}
+		}
+	case *ast.SelectorExpr:
+		if ident, ok := e.X.(*ast.Ident); ok && ctx.isImport(ident) {
+			if gpm := ctx.fileMeta.SearchImport(ident.Name).packageMeta; gpm != nil {
+				if gvm := gpm.SearchVarMeta(e.Sel.Name); gvm != nil {
+					return typeOfVar(gpm, gvm)
+				}
+			}
+			return namedType{}
+		}
+		if gsm := ctx.typeOfExpr(e.X).structMeta(); gsm != nil {
+			if gvm := gsm.memberMetaMap[e.Sel.Name]; gvm != nil {
+				return newNamedType(gvm.typeExpr)
+			}
+		}
+	}
+	return ctx.typeOfTypeExpr(ctx.inferExprType(expr, 0, 0))
+}
+
+// typeOfObject 推断局部标识的命名类型
+func (ctx *callContext) typeOfObject(obj *scopeObject) namedType {
+	if obj.kind == scopeObjectType {
+		return namedType{}
+	}
+	if obj.typeExpr != nil {
+		return ctx.typeOfTypeExpr(obj.typeExpr)
+	}
+	if obj.value == nil {
+		return namedType{}
+	}
+	if rangeStmt, isRange := obj.decl.(*ast.RangeStmt); isRange {
+		return ctx.typeOfTypeExpr(ctx.inferRangeType(rangeStmt, obj.valueIndex, 0))
+	}
+	if callExpr, ok := ast.Unparen(obj.value).(*ast.CallExpr); ok {
+		return ctx.typeOfResult(callExpr, obj.valueIndex)
+	}
+	if obj.valueIndex > 0 {
+		// v, ok := m[k]，v, ok := x.(T)
+		return namedType{}
+	}
+	return ctx.typeOfExpr(obj.value)
+}
+
+// typeOfResult 推断调用的第 index 个返回值的命名类型
+func (ctx *callContext) typeOfResult(callExpr *ast.CallExpr, index int) namedType {
+	gcm := newGoCallMeta(ctx.newMeta(callExpr), ctx)
+	switch gcm.callType {
+	case CALL_TYPE_BUILTIN:
+		if gcm.callee == "new" && len(callExpr.Args) == 1 {
+			return ctx.typeOfTypeExpr(callExpr.Args[0])
+		}
+	case CALL_TYPE_CONVERSION:
+		if index == 0 {
+			return ctx.typeOfTypeExpr(callExpr.Fun)
+		}
+	case CALL_TYPE_FUNC, CALL_TYPE_METHOD:
+		if gfm, _ := gcm.resolve(); gfm != nil && index < len(gfm.returns) {
+			return newNamedType(gfm.returns[index].typeExpr)
+		}
+	}
+	return namedType{}
+}
+
+// typeOfVar 推断 package 级变量的命名类型
+func typeOfVar(gpm *GoPackageMeta, gvm *GoVarMeta) namedType {
+	if gvm.typeExpr != nil {
+		return newNamedType(gvm.typeExpr)
+	}
+	valueSpec, ok := gvm.node.(*ast.ValueSpec)
+	if !ok {
+		return namedType{}
+	}
+	ctx := newCallContext(gpm, gvm.path, nil)
+	for index, name := range valueSpec.Names {
+		if name.Name != gvm.ident {
+			continue
+		}
+		if len(valueSpec.Values) == len(valueSpec.Names) {
+			return ctx.typeOfExpr(valueSpec.Values[index])
+		}
+		if len(valueSpec.Values) == 1 {
+			if callExpr, ok := ast.Unparen(valueSpec.Values[0]).(*ast.CallExpr); ok {
+				return ctx.typeOfResult(callExpr, index)
+			}
+		}
+	}
+	return namedType{}
+}
+
+// -------------------------------- extractor --------------------------------
+
+// -------------------------------- unit test --------------------------------
+
+func (gcm *GoCallMeta) FuncMeta() *GoFuncMeta          { return gcm.funcMeta }
+func (gcm *GoCallMeta) CallType() CallType             { return gcm.callType }
+func (gcm *GoCallMeta) ReceiverType() CallReceiverType { return gcm.receiverType }
+func (gcm *GoCallMeta) Callee() string                 { return gcm.callee }
+func (gcm *GoCallMeta) From() string                   { return gcm.from }
+func (gcm *GoCallMeta) Chain() []string                { return gcm.chain }
+func (gcm *GoCallMeta) ImportPath() string             { return gcm.importPath }
+func (gcm *GoCallMeta) Args() []*GoArgMeta             { return gcm.args }
+func (gcm *GoCallMeta) Pos() token.Position            { return gcm.position(gcm.node.Pos()) }
+func (gcm *GoCallMeta) End() token.Position            { return gcm.position(gcm.node.End()) }
+func (gcm *GoCallMeta) IsSelector() bool               { return len(gcm.from) > 0 }
+
+// -------------------------------- unit test --------------------------------
@@ -0,0 +1,366 @@
+package extractor
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CallGraph go 项目的静态调用图
+// - 节点为项目内的 func 和 struct 的 method
+// - 边为能够静态解析的调用，receiver 为 interface 的调用连接到项目内所有实现了该 interface 的 struct 的 method
+// - 闭包，func 类型的值，内置 func 以及项目外的 func 和 method 的调用不会产生边
+type CallGraph struct {
+	// 所有节点，按照节点标识排序
+	nodes []*CallGraphNode
+
+	// - key: 节点的 func 的 meta 数据，method 为其组合的 *GoFuncMeta
+	nodeMap map[*GoFuncMeta]*CallGraphNode
+
+	// - key: 节点标识
+	idMap map[string]*CallGraphNode
+
+	// 所有边，按照调用方节点标识以及调用在源码中的顺序排序
+	edges []*CallGraphEdge
+}
+
+// CallGraphNode 调用图的节点
+type CallGraphNode struct {
+	// 节点标识
+	// - func: importPath.F
+	// - method: importPath.(*T).M 或 importPath.T.M
+	// - main package 的 importPath 为 main
+	id string
+
+	// 节点所属 package 的 meta 数据
+	packageMeta *GoPackageMeta
+
+	// 节点的 func 的 meta 数据
+	funcMeta *GoFuncMeta
+
+	// 节点的 method 的 meta 数据，func 时为 nil
+	methodMeta *GoMethodMeta
+
+	// 调用该节点的边以及该节点发出的边
+	in, out []*CallGraphEdge
+}
+
+// CallGraphEdge 调用图的边，对应一个调用
+type CallGraphEdge struct {
+	caller, callee *CallGraphNode
+
+	// 边对应的调用的 meta 数据
+	callMeta *GoCallMeta
+
+	// 是否是通过 interface 的动态调用
+	dynamic bool
+}
+
+// -------------------------------- extractor --------------------------------
+
+// CallGraph 构造项目的静态调用图
+// - 同一 package 内的同名 func 只保留一个，多个 init 只会有一个节点
+func (gpm *GoProjectMeta) CallGraph() *CallGraph {
+	cg := &CallGraph{
+		nodes:   make([]*CallGraphNode, 0),
+		nodeMap: make(map[*GoFuncMeta]*CallGraphNode),
+		idMap:   make(map[string]*CallGraphNode),
+		edges:   make([]*CallGraphEdge, 0),
+	}
+
+	// 节点
+	structMetas := make([]*GoStructMeta, 0)
+	for _, packageMeta := range gpm.Packages() {
+		for _, gfm := range packageMeta.Funcs() {
+			cg.addNode(packageMeta, gfm, nil)
+		}
+		for _, gsm := range packageMeta.Structs() {
+			structMetas = append(structMetas, gsm)
+			for _, gmm := range gsm.Methods() {
+				cg.addNode(packageMeta, gmm.GoFuncMeta, gmm)
+			}
+		}
+	}
+	sort.Slice(cg.nodes, func(i, j int) bool { return cg.nodes[i].id < cg.nodes[j].id })
+
+	// 边
+	for _, caller := range cg.nodes {
+		for _, gcm := range caller.funcMeta.Calls() {
+			gfm, receiverType := gcm.resolve()
+			if callee := cg.nodeMap[gfm]; gfm != nil && callee != nil {
+				cg.addEdge(caller, callee, gcm, false)
+				continue
+			}
+			gim := receiverType.interfaceMeta()
+			if gcm.callType != CALL_TYPE_METHOD || gim == nil {
+				continue
+			}
+			for _, gsm := range structMetas {
+				if !gsm.implements(gim) {
+					continue
+				}
+				if gmm := gsm.searchPromotedMethodMeta(gcm.callee); gmm != nil && cg.nodeMap[gmm.GoFuncMeta] != nil {
+					cg.addEdge(caller, cg.nodeMap[gmm.GoFuncMeta], gcm, true)
+				}
+			}
+		}
+	}
+	return cg
+}
+
+// funcMetaID func 的唯一标识: importPath.F，importPath.(*T).M，importPath.T.M
+func funcMetaID(gpm *GoPackageMeta, gfm *GoFuncMeta, isMethod bool) string {
+	packageIdent := gpm.importPath
+	if len(packageIdent) == 0 {
+		packageIdent = gpm.ident
+	}
+	if !isMethod {
+		return fmt.Sprintf("%v.%v", packageIdent, gfm.ident)
+	}
+	receiverIdent, pointerReceiver := extractMethodRecvStruct(gfm.funcDecl())
+	if pointerReceiver {
+		receiverIdent = fmt.Sprintf("(*%v)", receiverIdent)
+	}
+	return fmt.Sprintf("%v.%v.%v", packageIdent, receiverIdent, gfm.ident)
+}
+
+func (cg *CallGraph) addNode(gpm *GoPackageMeta, gfm *GoFuncMeta, gmm *GoMethodMeta) {
+	id := funcMetaID(gpm, gfm, gmm != nil)
+	node := &CallGraphNode{id: id, packageMeta: gpm, funcMeta: gfm, methodMeta: gmm}
+	cg.nodes = append(cg.nodes, node)
+	cg.nodeMap[gfm] = node
+	cg.idMap[id] = node
+}
+
+func (cg *CallGraph) addEdge(caller, callee *CallGraphNode, gcm *GoCallMeta, dynamic bool) {
+	edge := &CallGraphEdge{caller: caller, callee: callee, callMeta: gcm, dynamic: dynamic}
+	cg.edges = append(cg.edges, edge)
+	caller.out = append(caller.out, edge)
+	callee.in = append(callee.in, edge)
+}
+
+// SearchNode 根据节点标识搜索节点
+func (cg *CallGraph) SearchNode(id string) *CallGraphNode {
+	return cg.idMap[id]
+}
+
+// NodeOf 获取 func 或 method 对应的节点
+func (cg *CallGraph) NodeOf(gfm *GoFuncMeta) *CallGraphNode {
+	return cg.nodeMap[gfm]
+}
+
+// Callers 获取调用该节点的所有节点，按照节点标识排序
+func (cgn *CallGraphNode) Callers() []*CallGraphNode {
+	return uniqueCallGraphNodes(cgn.in, func(edge *CallGraphEdge) *CallGraphNode { return edge.caller })
+}
+
+// Callees 获取该节点调用的所有节点，按照节点标识排序
+func (cgn *CallGraphNode) Callees() []*CallGraphNode {
+	return uniqueCallGraphNodes(cgn.out, func(edge *CallGraphEdge) *CallGraphNode { return edge.callee })
+}
+
+func uniqueCallGraphNodes(edges []*CallGraphEdge, f func(*CallGraphEdge) *CallGraphNode) []*CallGraphNode {
+	nodeSet := make(map[*CallGraphNode]struct{})
+	nodes := make([]*CallGraphNode, 0)
+	for _, edge := range edges {
+		node := f(edge)
+		if _, has := nodeSet[node]; has {
+			continue
+		}
+		nodeSet[node] = struct{}{}
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].id < nodes[j].id })
+	return nodes
+}
+
+// Roots 获取调用图的入口节点，按照节点标识排序
+// - main package 的 main
+// - 所有 init
+// - _test.go 文件中的 TestXxx，BenchmarkXxx，FuzzXxx，ExampleXxx
+func (cg *CallGraph) Roots() []*CallGraphNode {
+	roots := make([]*CallGraphNode, 0)
+	for _, node := range cg.nodes {
+		if node.methodMeta != nil {
+			continue
+		}
+		ident := node.funcMeta.ident
+		switch {
+		case ident == "main" && node.packageMeta.ident == "main",
+			ident == "init",
+			strings.HasSuffix(node.funcMeta.path, "_test.go") && isTestFuncIdent(ident):
+			roots = append(roots, node)
+		}
+	}
+	return roots
+}
+
+// isTestFuncIdent 是否是 go test 识别的测试函数标识，与 go test 的判断规则一致
+func isTestFuncIdent(ident string) bool {
+	for _, prefix := range []string{"Test", "Benchmark", "Fuzz", "Example"} {
+		suffix, ok := strings.CutPrefix(ident, prefix)
+		if !ok {
+			continue
+		}
+		if len(suffix) == 0 {
+			return true
+		}
+		r, _ := utf8.DecodeRuneInString(suffix)
+		return !unicode.IsLower(r)
+	}
+	return false
+}
+
+// Reachable 获取从指定节点出发可以到达的所有节点，包括指定节点自身，按照节点标识排序
+// - 未指定节点时从 Roots 出发
+func (cg *CallGraph) Reachable(roots ...*CallGraphNode) []*CallGraphNode {
+	if len(roots) == 0 {
+		roots = cg.Roots()
+	}
+	visited := make(map[*CallGraphNode]struct{})
+	queue := make([]*CallGraphNode, 0, len(roots))
+	for _, root := range roots {
+		if _, has := visited[root]; !has {
+			visited[root] = struct{}{}
+			queue = append(queue, root)
+		}
+	}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, edge := range node.out {
+			if _, has := visited[edge.callee]; !has {
+				visited[edge.callee] = struct{}{}
+				queue = append(queue, edge.callee)
+			}
+		}
+	}
+	reachable := make([]*CallGraphNode, 0, len(visited))
+	for _, node := range cg.nodes {
+		if _, has := visited[node]; has {
+			reachable = append(reachable, node)
+		}
+	}
+	return reachable
+}
+
+// DOT 以 graphviz 的 dot 格式输出调用图
+// - 同一对节点之间的多次调用只输出一条边
+// - 通过 interface 的动态调用以虚线表示
+func (cg *CallGraph) DOT() string {
+	builder := &strings.Builder{}
+	builder.WriteString("digraph callgraph {\n")
+	for _, node := range cg.nodes {
+		fmt.Fprintf(builder, "\t%v;\n", strconv.Quote(node.id))
+	}
+	type edgeKey struct {
+		caller, callee *CallGraphNode
+		dynamic        bool
+	}
+	written := make(map[edgeKey]struct{})
+	for _, edge := range cg.edges {
+		key := edgeKey{edge.caller, edge.callee, edge.dynamic}
+		if _, has := written[key]; has {
+			continue
+		}
+		written[key] = struct{}{}
+		fmt.Fprintf(builder, "\t%v -> %v", strconv.Quote(edge.caller.id), strconv.Quote(edge.callee.id))
+		if edge.dynamic {
+			builder.WriteString(" [style=dashed]")
+		}
+		builder.WriteString(";\n")
+	}
+	builder.WriteString("}\n")
+	return builder.String()
+}
+
+// callGraphJSONNode 调用图的节点的 JSON 格式
+type callGraphJSONNode struct {
+	ID       string `json:"id"`
+	Package  string `json:"package"`
+	Name     string `json:"name"`
+	Receiver string `json:"receiver,omitempty"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// callGraphJSONEdge 调用图的边的 JSON 格式
+type callGraphJSONEdge struct {
+	Caller  string `json:"caller"`
+	Callee  string `json:"callee"`
+	Dynamic bool   `json:"dynamic,omitempty"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+}
+
+// JSON 以 JSON 格式输出调用图，每个调用对应一条边，文件路径为相对于 root 的路径
+// - root 为空时输出绝对路径
+func (cg *CallGraph) JSON(root string) ([]byte, error) {
+	relPath := func(path string) string {
+		if len(root) == 0 {
+			return path
+		}
+		if rel, err := filepath.Rel(root, path); err == nil {
+			return filepath.ToSlash(rel)
+		}
+		return path
+	}
+	output := struct {
+		Nodes []*callGraphJSONNode `json:"nodes"`
+		Edges []*callGraphJSONEdge `json:"edges"`
+	}{
+		Nodes: make([]*callGraphJSONNode, 0, len(cg.nodes)),
+		Edges: make([]*callGraphJSONEdge, 0, len(cg.edges)),
+	}
+	for _, node := range cg.nodes {
+		jsonNode := &callGraphJSONNode{
+			ID:      node.id,
+			Package: node.packageMeta.importPath,
+			Name:    node.funcMeta.ident,
+			File:    relPath(node.funcMeta.path),
+			Line:    node.funcMeta.position(node.funcMeta.node.Pos()).Line,
+		}
+		if node.methodMeta != nil {
+			jsonNode.Receiver = types.ExprString(node.funcMeta.funcDecl().Recv.List[0].Type)
+		}
+		output.Nodes = append(output.Nodes, jsonNode)
+	}
+	for _, edge := range cg.edges {
+		position := edge.callMeta.Pos()
+		output.Edges = append(output.Edges, &callGraphJSONEdge{
+			Caller:  edge.caller.id,
+			Callee:  edge.callee.id,
+			Dynamic: edge.dynamic,
+			File:    relPath(position.Filename),
+			Line:    position.Line,
+			Column:  position.Column,
+		})
+	}
+	return json.MarshalIndent(output, "", "  ")
+}
+
+// -------------------------------- extractor --------------------------------
+
+// -------------------------------- unit test --------------------------------
+
+func (cg *CallGraph) Nodes() []*CallGraphNode          { return cg.nodes }
+func (cg *CallGraph) Edges() []*CallGraphEdge          { return cg.edges }
+func (cgn *CallGraphNode) ID() string                  { return cgn.id }
+func (cgn *CallGraphNode) PackageMeta() *GoPackageMeta { return cgn.packageMeta }
+func (cgn *CallGraphNode) FuncMeta() *GoFuncMeta       { return cgn.funcMeta }
+func (cgn *CallGraphNode) MethodMeta() *GoMethodMeta   { return cgn.methodMeta }
+func (cgn *CallGraphNode) In() []*CallGraphEdge        { return cgn.in }
+func (cgn *CallGraphNode) Out() []*CallGraphEdge       { return cgn.out }
+func (cge *CallGraphEdge) Caller() *CallGraphNode      { return cge.caller }
+func (cge *CallGraphEdge) Callee() *CallGraphNode      { return cge.callee }
+func (cge *CallGraphEdge) CallMeta() *GoCallMeta       { return cge.callMeta }
+func (cge *CallGraphEdge) IsDynamic() bool             { return cge.dynamic }
+
+// -------------------------------- unit test --------------------------------
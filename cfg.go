@@ -0,0 +1,728 @@
+package extractor
+
+import (
+	"go/ast"
+	"go/token"
+	"sort"
+)
+
+type CFGBlockType int
+
+const (
+	CFG_BLOCK_ENTRY       = iota + 1 // func 的入口
+	CFG_BLOCK_EXIT                   // func 的出口，所有 return 和 panic 都指向该 block
+	CFG_BLOCK_IF_THEN                // if 成立的分支
+	CFG_BLOCK_IF_ELSE                // if 不成立的分支
+	CFG_BLOCK_IF_DONE                // if 之后的语句
+	CFG_BLOCK_FOR_LOOP               // for/range 的循环条件
+	CFG_BLOCK_FOR_BODY               // for/range 的循环体
+	CFG_BLOCK_FOR_POST               // for 的 post 语句
+	CFG_BLOCK_FOR_DONE               // for/range 之后的语句
+	CFG_BLOCK_SWITCH_CASE            // switch/type switch 的 case 分支
+	CFG_BLOCK_SWITCH_DONE            // switch/type switch 之后的语句
+	CFG_BLOCK_SELECT_CASE            // select 的 case 分支
+	CFG_BLOCK_SELECT_DONE            // select 之后的语句
+	CFG_BLOCK_LABEL                  // label 语句
+	CFG_BLOCK_UNREACHABLE            // return，goto，break，continue 之后不可达的语句
+)
+
+// GoCFGBlock 控制流图的基本块
+type GoCFGBlock struct {
+	// 基本块在控制流图中的序号
+	index int
+
+	// 基本块的种类
+	blockType CFGBlockType
+
+	// 基本块内按顺序执行的语句和条件表达式，不展开 func 字面量
+	nodes []ast.Node
+
+	// 后继基本块
+	succs []*GoCFGBlock
+
+	// 前驱基本块
+	preds []*GoCFGBlock
+}
+
+// GoCFG func 的控制流图
+type GoCFG struct {
+	// 控制流图所属的 func 的 meta 数据
+	funcMeta *GoFuncMeta
+
+	// 所有基本块，按照构造顺序
+	blocks []*GoCFGBlock
+
+	// 入口和出口基本块
+	entry, exit *GoCFGBlock
+
+	// func 内的所有 defer 语句，不包括 func 字面量内的
+	defers []*ast.DeferStmt
+
+	// func 内的所有 return 语句，不包括 func 字面量内的
+	returns []*ast.ReturnStmt
+
+	// func 体末尾的基本块，可达时表示执行到 func 体末尾的隐式 return
+	end *GoCFGBlock
+}
+
+// cfgTargets break/continue 的目标
+type cfgTargets struct {
+	tail           *cfgTargets
+	label          string
+	_break         *GoCFGBlock
+	_continue      *GoCFGBlock
+	_fallthrough   *GoCFGBlock
+	isFallthrough  bool
+	hasBreakTarget bool
+}
+
+// cfgBuilder 控制流图的构造器
+type cfgBuilder struct {
+	cfg     *GoCFG
+	current *GoCFGBlock
+	targets *cfgTargets
+	labels  map[string]*GoCFGBlock
+}
+
+// -------------------------------- extractor --------------------------------
+
+// CFG 构造 func 的控制流图
+// - goto，带 label 的 break/continue 指向对应的 block
+// - defer 仅记录在语句所在的 block 内，其执行视为发生在出口
+// - 调用内置 func panic 视为跳转到出口
+func (gfm *GoFuncMeta) CFG() *GoCFG {
+	b := &cfgBuilder{
+		cfg:    &GoCFG{funcMeta: gfm},
+		labels: make(map[string]*GoCFGBlock),
+	}
+	b.cfg.entry = b.newBlock(CFG_BLOCK_ENTRY)
+	b.cfg.exit = &GoCFGBlock{blockType: CFG_BLOCK_EXIT}
+	b.current = b.cfg.entry
+	if body := funcBody(gfm.node); body != nil {
+		b.stmtList(body.List)
+	}
+	b.cfg.end = b.current
+	b.jump(b.cfg.exit)
+	b.cfg.exit.index = len(b.cfg.blocks)
+	b.cfg.blocks = append(b.cfg.blocks, b.cfg.exit)
+	return b.cfg
+}
+
+// funcBody 获取 func 声明或 func 字面量的 block
+func funcBody(node ast.Node) *ast.BlockStmt {
+	switch n := node.(type) {
+	case *ast.FuncDecl:
+		return n.Body
+	case *ast.FuncLit:
+		return n.Body
+	}
+	return nil
+}
+
+func (b *cfgBuilder) newBlock(blockType CFGBlockType) *GoCFGBlock {
+	block := &GoCFGBlock{index: len(b.cfg.blocks), blockType: blockType}
+	b.cfg.blocks = append(b.cfg.blocks, block)
+	return block
+}
+
+func (b *cfgBuilder) add(node ast.Node) {
+	b.current.nodes = append(b.current.nodes, node)
+}
+
+// jump 连接当前 block 与目标 block
+func (b *cfgBuilder) jump(target *GoCFGBlock) {
+	b.current.succs = append(b.current.succs, target)
+	target.preds = append(target.preds, b.current)
+}
+
+// jumpAway 跳转到目标 block 之后的语句均不可达
+func (b *cfgBuilder) jumpAway(target *GoCFGBlock) {
+	b.jump(target)
+	b.current = b.newBlock(CFG_BLOCK_UNREACHABLE)
+}
+
+func (b *cfgBuilder) labelBlock(label string) *GoCFGBlock {
+	block, has := b.labels[label]
+	if !has {
+		block = b.newBlock(CFG_BLOCK_LABEL)
+		b.labels[label] = block
+	}
+	return block
+}
+
+func (b *cfgBuilder) stmtList(list []ast.Stmt) {
+	for _, s := range list {
+		b.stmt(s, "")
+	}
+}
+
+func (b *cfgBuilder) stmt(s ast.Stmt, label string) {
+	switch s := s.(type) {
+	case *ast.BlockStmt:
+		b.stmtList(s.List)
+
+	case *ast.LabeledStmt:
+		block := b.labelBlock(s.Label.Name)
+		b.jump(block)
+		b.current = block
+		b.stmt(s.Stmt, s.Label.Name)
+
+	case *ast.ReturnStmt:
+		b.add(s)
+		b.cfg.returns = append(b.cfg.returns, s)
+		b.jumpAway(b.cfg.exit)
+
+	case *ast.DeferStmt:
+		b.add(s)
+		b.cfg.defers = append(b.cfg.defers, s)
+
+	case *ast.ExprStmt:
+		b.add(s)
+		if call, ok := s.X.(*ast.CallExpr); ok {
+			if ident, ok := ast.Unparen(call.Fun).(*ast.Ident); ok && ident.Name == "panic" {
+				b.jumpAway(b.cfg.exit)
+			}
+		}
+
+	case *ast.BranchStmt:
+		b.branchStmt(s)
+
+	case *ast.IfStmt:
+		b.ifStmt(s)
+
+	case *ast.ForStmt:
+		b.forStmt(s, label)
+
+	case *ast.RangeStmt:
+		b.rangeStmt(s, label)
+
+	case *ast.SwitchStmt:
+		if s.Init != nil {
+			b.stmt(s.Init, "")
+		}
+		if s.Tag != nil {
+			b.add(s.Tag)
+		}
+		b.caseClauses(s.Body, label)
+
+	case *ast.TypeSwitchStmt:
+		if s.Init != nil {
+			b.stmt(s.Init, "")
+		}
+		b.add(s.Assign)
+		b.caseClauses(s.Body, label)
+
+	case *ast.SelectStmt:
+		b.selectStmt(s, label)
+
+	default:
+		// AssignStmt，DeclStmt，GoStmt，SendStmt，IncDecStmt，EmptyStmt
+		b.add(s)
+	}
+}
+
+func (b *cfgBuilder) branchStmt(s *ast.BranchStmt) {
+	var target *GoCFGBlock
+	switch s.Tok {
+	case token.BREAK:
+		for t := b.targets; t != nil; t = t.tail {
+			if t.hasBreakTarget && (s.Label == nil || t.label == s.Label.Name) {
+				target = t._break
+				break
+			}
+		}
+	case token.CONTINUE:
+		for t := b.targets; t != nil; t = t.tail {
+			if t._continue != nil && (s.Label == nil || t.label == s.Label.Name) {
+				target = t._continue
+				break
+			}
+		}
+	case token.FALLTHROUGH:
+		for t := b.targets; t != nil; t = t.tail {
+			if t.isFallthrough {
+				target = t._fallthrough
+				break
+			}
+		}
+	case token.GOTO:
+		if s.Label != nil {
+			target = b.labelBlock(s.Label.Name)
+		}
+	}
+	b.add(s)
+	if target == nil {
+		// 非法的跳转
+		target = b.cfg.exit
+	}
+	b.jumpAway(target)
+}
+
+func (b *cfgBuilder) ifStmt(s *ast.IfStmt) {
+	if s.Init != nil {
+		b.stmt(s.Init, "")
+	}
+	b.add(s.Cond)
+	then := b.newBlock(CFG_BLOCK_IF_THEN)
+	done := b.newBlock(CFG_BLOCK_IF_DONE)
+	_else := done
+	if s.Else != nil {
+		_else = b.newBlock(CFG_BLOCK_IF_ELSE)
+	}
+	b.jump(then)
+	b.jump(_else)
+
+	b.current = then
+	b.stmtList(s.Body.List)
+	b.jump(done)
+
+	if s.Else != nil {
+		b.current = _else
+		b.stmt(s.Else, "")
+		b.jump(done)
+	}
+	b.current = done
+}
+
+func (b *cfgBuilder) forStmt(s *ast.ForStmt, label string) {
+	if s.Init != nil {
+		b.stmt(s.Init, "")
+	}
+	loop := b.newBlock(CFG_BLOCK_FOR_LOOP)
+	body := b.newBlock(CFG_BLOCK_FOR_BODY)
+	done := b.newBlock(CFG_BLOCK_FOR_DONE)
+	_continue := loop
+	if s.Post != nil {
+		_continue = b.newBlock(CFG_BLOCK_FOR_POST)
+	}
+	b.jump(loop)
+
+	b.current = loop
+	if s.Cond != nil {
+		b.add(s.Cond)
+		b.jump(done)
+	}
+	b.jump(body)
+
+	b.current = body
+	b.targets = &cfgTargets{tail: b.targets, label: label, _break: done, _continue: _continue, hasBreakTarget: true}
+	b.stmtList(s.Body.List)
+	b.targets = b.targets.tail
+	b.jump(_continue)
+
+	if s.Post != nil {
+		b.current = _continue
+		b.stmt(s.Post, "")
+		b.jump(loop)
+	}
+	b.current = done
+}
+
+func (b *cfgBuilder) rangeStmt(s *ast.RangeStmt, label string) {
+	b.add(s.X)
+	loop := b.newBlock(CFG_BLOCK_FOR_LOOP)
+	body := b.newBlock(CFG_BLOCK_FOR_BODY)
+	done := b.newBlock(CFG_BLOCK_FOR_DONE)
+	b.jump(loop)
+
+	b.current = loop
+	b.add(s)
+	b.jump(body)
+	b.jump(done)
+
+	b.current = body
+	b.targets = &cfgTargets{tail: b.targets, label: label, _break: done, _continue: loop, hasBreakTarget: true}
+	b.stmtList(s.Body.List)
+	b.targets = b.targets.tail
+	b.jump(loop)
+
+	b.current = done
+}
+
+func (b *cfgBuilder) caseClauses(body *ast.BlockStmt, label string) {
+	done := b.newBlock(CFG_BLOCK_SWITCH_DONE)
+	clauses := make([]*GoCFGBlock, 0, len(body.List))
+	hasDefault := false
+	for _, clause := range body.List {
+		block := b.newBlock(CFG_BLOCK_SWITCH_CASE)
+		clauses = append(clauses, block)
+		if clause.(*ast.CaseClause).List == nil {
+			hasDefault = true
+		}
+	}
+	for _, block := range clauses {
+		b.jump(block)
+	}
+	if !hasDefault {
+		b.jump(done)
+	}
+
+	for index, clause := range body.List {
+		caseClause := clause.(*ast.CaseClause)
+		b.current = clauses[index]
+		for _, expr := range caseClause.List {
+			b.add(expr)
+		}
+		t := &cfgTargets{tail: b.targets, label: label, _break: done, hasBreakTarget: true}
+		if index+1 < len(clauses) {
+			t.isFallthrough, t._fallthrough = true, clauses[index+1]
+		}
+		b.targets = t
+		b.stmtList(caseClause.Body)
+		b.targets = b.targets.tail
+		b.jump(done)
+	}
+	b.current = done
+}
+
+func (b *cfgBuilder) selectStmt(s *ast.SelectStmt, label string) {
+	done := b.newBlock(CFG_BLOCK_SELECT_DONE)
+	clauses := make([]*GoCFGBlock, 0, len(s.Body.List))
+	for range s.Body.List {
+		block := b.newBlock(CFG_BLOCK_SELECT_CASE)
+		clauses = append(clauses, block)
+		b.jump(block)
+	}
+
+	for index, clause := range s.Body.List {
+		commClause := clause.(*ast.CommClause)
+		b.current = clauses[index]
+		if commClause.Comm != nil {
+			b.add(commClause.Comm)
+		}
+		b.targets = &cfgTargets{tail: b.targets, label: label, _break: done, hasBreakTarget: true}
+		b.stmtList(commClause.Body)
+		b.targets = b.targets.tail
+		b.jump(done)
+	}
+	// 没有 case 的 select 永久阻塞
+	b.current = done
+}
+
+// Reachable 获取从入口可达的所有基本块，按照序号排序
+func (cfg *GoCFG) Reachable() []*GoCFGBlock {
+	visited := make(map[*GoCFGBlock]struct{})
+	queue := []*GoCFGBlock{cfg.entry}
+	visited[cfg.entry] = struct{}{}
+	for len(queue) > 0 {
+		block := queue[0]
+		queue = queue[1:]
+		for _, succ := range block.succs {
+			if _, has := visited[succ]; !has {
+				visited[succ] = struct{}{}
+				queue = append(queue, succ)
+			}
+		}
+	}
+	blocks := make([]*GoCFGBlock, 0, len(visited))
+	for _, block := range cfg.blocks {
+		if _, has := visited[block]; has {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+// CyclomaticComplexity 圈复杂度，通过可达的基本块计算: E - N + 2
+// - 每个 && 与 || 产生一个隐式分支 +1，不包括 func 字面量内的
+func (cfg *GoCFG) CyclomaticComplexity() int {
+	blocks := cfg.Reachable()
+	edges := 0
+	for _, block := range blocks {
+		edges += len(block.succs)
+	}
+	complexity := edges - len(blocks) + 2
+	if body := funcBody(cfg.funcMeta.node); body != nil {
+		ast.Inspect(body, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.FuncLit:
+				return false
+			case *ast.BinaryExpr:
+				if isLogicalOp(node.Op) {
+					complexity++
+				}
+			}
+			return true
+		})
+	}
+	return complexity
+}
+
+// ReturnCount func 内 return 的数量，不包括 func 字面量内的
+// - 显式的 return 语句
+// - 执行到 func 体末尾时的隐式 return
+func (cfg *GoCFG) ReturnCount() int {
+	for _, block := range cfg.Reachable() {
+		if block == cfg.end {
+			return len(cfg.returns) + 1
+		}
+	}
+	return len(cfg.returns)
+}
+
+// cognitiveWalker 认知复杂度的计算器
+// - if，else if，else，switch，select，for，range，goto，带 label 的 break/continue 各 +1
+// - if，switch，select，for，range 额外加上当前嵌套层数，func 字面量增加嵌套层数
+// - 每一组连续且相同的 && 或 || +1
+// - 直接递归 +1
+type cognitiveWalker struct {
+	funcMeta   *GoFuncMeta
+	recvIdent  string
+	complexity int
+	maxDepth   int
+}
+
+// CognitiveComplexity 认知复杂度
+func (gfm *GoFuncMeta) CognitiveComplexity() int {
+	w := newCognitiveWalker(gfm)
+	return w.complexity
+}
+
+// MaxNestingDepth 控制语句的最大嵌套深度，else if 不增加深度，func 字面量内的控制语句从外部的深度继续计算
+func (gfm *GoFuncMeta) MaxNestingDepth() int {
+	w := newCognitiveWalker(gfm)
+	return w.maxDepth
+}
+
+func newCognitiveWalker(gfm *GoFuncMeta) *cognitiveWalker {
+	w := &cognitiveWalker{funcMeta: gfm}
+	if decl, ok := gfm.node.(*ast.FuncDecl); ok && decl.Recv != nil && len(decl.Recv.List) > 0 && len(decl.Recv.List[0].Names) > 0 {
+		w.recvIdent = decl.Recv.List[0].Names[0].Name
+	}
+	if body := funcBody(gfm.node); body != nil {
+		w.stmtList(body.List, 0, 0)
+	}
+	return w
+}
+
+func (w *cognitiveWalker) nest(depth int) {
+	if depth > w.maxDepth {
+		w.maxDepth = depth
+	}
+}
+
+func (w *cognitiveWalker) stmtList(list []ast.Stmt, nesting, depth int) {
+	for _, s := range list {
+		w.stmt(s, nesting, depth)
+	}
+}
+
+func (w *cognitiveWalker) stmt(s ast.Stmt, nesting, depth int) {
+	switch s := s.(type) {
+	case *ast.BlockStmt:
+		w.stmtList(s.List, nesting, depth)
+	case *ast.LabeledStmt:
+		w.stmt(s.Stmt, nesting, depth)
+	case *ast.IfStmt:
+		w.complexity += 1 + nesting
+		w.ifStmt(s, nesting, depth)
+	case *ast.ForStmt:
+		w.complexity += 1 + nesting
+		w.nest(depth + 1)
+		w.optStmt(s.Init, nesting, depth)
+		w.expr(s.Cond, nesting, depth)
+		w.optStmt(s.Post, nesting, depth)
+		w.stmtList(s.Body.List, nesting+1, depth+1)
+	case *ast.RangeStmt:
+		w.complexity += 1 + nesting
+		w.nest(depth + 1)
+		w.expr(s.X, nesting, depth)
+		w.stmtList(s.Body.List, nesting+1, depth+1)
+	case *ast.SwitchStmt:
+		w.complexity += 1 + nesting
+		w.nest(depth + 1)
+		w.optStmt(s.Init, nesting, depth)
+		w.expr(s.Tag, nesting, depth)
+		for _, clause := range s.Body.List {
+			for _, expr := range clause.(*ast.CaseClause).List {
+				w.expr(expr, nesting, depth)
+			}
+			w.stmtList(clause.(*ast.CaseClause).Body, nesting+1, depth+1)
+		}
+	case *ast.TypeSwitchStmt:
+		w.complexity += 1 + nesting
+		w.nest(depth + 1)
+		w.optStmt(s.Init, nesting, depth)
+		w.optStmt(s.Assign, nesting, depth)
+		for _, clause := range s.Body.List {
+			w.stmtList(clause.(*ast.CaseClause).Body, nesting+1, depth+1)
+		}
+	case *ast.SelectStmt:
+		w.complexity += 1 + nesting
+		w.nest(depth + 1)
+		for _, clause := range s.Body.List {
+			w.optStmt(clause.(*ast.CommClause).Comm, nesting, depth)
+			w.stmtList(clause.(*ast.CommClause).Body, nesting+1, depth+1)
+		}
+	case *ast.BranchStmt:
+		if s.Tok == token.GOTO || (s.Label != nil && (s.Tok == token.BREAK || s.Tok == token.CONTINUE)) {
+			w.complexity++
+		}
+	default:
+		w.expr(s, nesting, depth)
+	}
+}
+
+func (w *cognitiveWalker) optStmt(s ast.Stmt, nesting, depth int) {
+	if s != nil {
+		w.stmt(s, nesting, depth)
+	}
+}
+
+// ifStmt 处理 if 及其 else if/else 链，调用前已经计入 if 本身
+func (w *cognitiveWalker) ifStmt(s *ast.IfStmt, nesting, depth int) {
+	w.nest(depth + 1)
+	w.optStmt(s.Init, nesting, depth)
+	w.expr(s.Cond, nesting, depth)
+	w.stmtList(s.Body.List, nesting+1, depth+1)
+	switch e := s.Else.(type) {
+	case *ast.IfStmt:
+		w.complexity++
+		w.ifStmt(e, nesting, depth)
+	case *ast.BlockStmt:
+		w.complexity++
+		w.stmtList(e.List, nesting+1, depth+1)
+	}
+}
+
+// expr 处理语句或表达式内的逻辑运算符序列，func 字面量和递归调用
+func (w *cognitiveWalker) expr(node ast.Node, nesting, depth int) {
+	if node == nil {
+		return
+	}
+	visited := make(map[*ast.BinaryExpr]struct{})
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch e := n.(type) {
+		case *ast.FuncLit:
+			w.stmtList(e.Body.List, nesting+1, depth)
+			return false
+		case *ast.BinaryExpr:
+			if _, has := visited[e]; has || !isLogicalOp(e.Op) {
+				return true
+			}
+			ops := make([]token.Token, 0)
+			logicalOps(e, &ops, visited)
+			w.complexity++
+			for i := 1; i < len(ops); i++ {
+				if ops[i] != ops[i-1] {
+					w.complexity++
+				}
+			}
+		case *ast.CallExpr:
+			if w.isRecursion(e) {
+				w.complexity++
+			}
+		}
+		return true
+	})
+}
+
+func (w *cognitiveWalker) isRecursion(call *ast.CallExpr) bool {
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		return len(w.recvIdent) == 0 && fun.Name == w.funcMeta.ident
+	case *ast.SelectorExpr:
+		x, ok := fun.X.(*ast.Ident)
+		return ok && len(w.recvIdent) > 0 && x.Name == w.recvIdent && fun.Sel.Name == w.funcMeta.ident
+	}
+	return false
+}
+
+func isLogicalOp(op token.Token) bool {
+	return op == token.LAND || op == token.LOR
+}
+
+// logicalOps 按照源码顺序展开逻辑运算符序列，括号不打断序列
+func logicalOps(expr ast.Expr, ops *[]token.Token, visited map[*ast.BinaryExpr]struct{}) {
+	e, ok := ast.Unparen(expr).(*ast.BinaryExpr)
+	if !ok || !isLogicalOp(e.Op) {
+		return
+	}
+	visited[e] = struct{}{}
+	logicalOps(e.X, ops, visited)
+	*ops = append(*ops, e.Op)
+	logicalOps(e.Y, ops, visited)
+}
+
+// GoComplexityMeta func 的复杂度指标
+type GoComplexityMeta struct {
+	// 指标所属的 func 的 meta 数据
+	funcMeta *GoFuncMeta
+
+	// 指标所属的 method 的 meta 数据，非 method 为 nil
+	methodMeta *GoMethodMeta
+
+	// func 的唯一标识，与调用图的节点标识一致
+	id string
+
+	cyclomatic int
+	cognitive  int
+	maxNesting int
+	returns    int
+}
+
+func newGoComplexityMeta(gpm *GoPackageMeta, gfm *GoFuncMeta, gmm *GoMethodMeta) *GoComplexityMeta {
+	cfg := gfm.CFG()
+	w := newCognitiveWalker(gfm)
+	return &GoComplexityMeta{
+		funcMeta:   gfm,
+		methodMeta: gmm,
+		id:         funcMetaID(gpm, gfm, gmm != nil),
+		cyclomatic: cfg.CyclomaticComplexity(),
+		cognitive:  w.complexity,
+		maxNesting: w.maxDepth,
+		returns:    cfg.ReturnCount(),
+	}
+}
+
+// ComplexityReport 获取 package 内所有 func 和 struct 的 method 的复杂度指标
+// - 按照认知复杂度，圈复杂度，最大嵌套深度降序排序，相同时按照唯一标识排序
+// - limit 大于 0 时仅返回前 limit 个
+func (gpm *GoPackageMeta) ComplexityReport(limit int) []*GoComplexityMeta {
+	report := make([]*GoComplexityMeta, 0, len(gpm.funcMetaMap))
+	for _, gfm := range gpm.Funcs() {
+		report = append(report, newGoComplexityMeta(gpm, gfm, nil))
+	}
+	for _, gsm := range gpm.Structs() {
+		for _, gmm := range gsm.Methods() {
+			report = append(report, newGoComplexityMeta(gpm, gmm.GoFuncMeta, gmm))
+		}
+	}
+	sort.SliceStable(report, func(i, j int) bool {
+		if report[i].cognitive != report[j].cognitive {
+			return report[i].cognitive > report[j].cognitive
+		}
+		if report[i].cyclomatic != report[j].cyclomatic {
+			return report[i].cyclomatic > report[j].cyclomatic
+		}
+		if report[i].maxNesting != report[j].maxNesting {
+			return report[i].maxNesting > report[j].maxNesting
+		}
+		return report[i].id < report[j].id
+	})
+	if limit > 0 && len(report) > limit {
+		report = report[:limit]
+	}
+	return report
+}
+
+// -------------------------------- unit test --------------------------------
+
+func (block *GoCFGBlock) Index() int                    { return block.index }
+func (block *GoCFGBlock) BlockType() CFGBlockType       { return block.blockType }
+func (block *GoCFGBlock) Nodes() []ast.Node             { return block.nodes }
+func (block *GoCFGBlock) Succs() []*GoCFGBlock          { return block.succs }
+func (block *GoCFGBlock) Preds() []*GoCFGBlock          { return block.preds }
+func (cfg *GoCFG) FuncMeta() *GoFuncMeta                { return cfg.funcMeta }
+func (cfg *GoCFG) Blocks() []*GoCFGBlock                { return cfg.blocks }
+func (cfg *GoCFG) Entry() *GoCFGBlock                   { return cfg.entry }
+func (cfg *GoCFG) Exit() *GoCFGBlock                    { return cfg.exit }
+func (cfg *GoCFG) Defers() []*ast.DeferStmt             { return cfg.defers }
+func (cfg *GoCFG) Returns() []*ast.ReturnStmt           { return cfg.returns }
+func (gcm *GoComplexityMeta) FuncMeta() *GoFuncMeta     { return gcm.funcMeta }
+func (gcm *GoComplexityMeta) MethodMeta() *GoMethodMeta { return gcm.methodMeta }
+func (gcm *GoComplexityMeta) ID() string                { return gcm.id }
+func (gcm *GoComplexityMeta) Cyclomatic() int           { return gcm.cyclomatic }
+func (gcm *GoComplexityMeta) Cognitive() int            { return gcm.cognitive }
+func (gcm *GoComplexityMeta) MaxNesting() int           { return gcm.maxNesting }
+func (gcm *GoComplexityMeta) ReturnCount() int          { return gcm.returns }
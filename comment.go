@@ -0,0 +1,135 @@
+package extractor
+
+import (
+	"go/ast"
+	"go/doc/comment"
+)
+
+// commentMeta ast 节点的 文档注释 和 行尾注释
+// - 组合到各个 meta 数据中，提供 Doc/DocText/DocLinks/Comment/CommentText
+type commentMeta struct {
+	// 文档注释：紧邻节点上方的注释
+	doc *ast.CommentGroup
+
+	// 行尾注释：与节点同一行的注释
+	comment *ast.CommentGroup
+}
+
+// newCommentMeta 通过 ast 节点构造 注释 的 meta 数据
+func newCommentMeta(node ast.Node) commentMeta {
+	switch n := node.(type) {
+	case *ast.File:
+		return commentMeta{doc: n.Doc}
+	case *ast.FuncDecl:
+		return commentMeta{doc: n.Doc}
+	case *ast.GenDecl:
+		return commentMeta{doc: n.Doc}
+	case *ast.TypeSpec:
+		return commentMeta{doc: n.Doc, comment: n.Comment}
+	case *ast.ValueSpec:
+		return commentMeta{doc: n.Doc, comment: n.Comment}
+	case *ast.ImportSpec:
+		return commentMeta{doc: n.Doc, comment: n.Comment}
+	case *ast.Field:
+		return commentMeta{doc: n.Doc, comment: n.Comment}
+	}
+	return commentMeta{}
+}
+
+// inheritDoc 节点没有文档注释时，继承其所属的非括号形式的 *ast.GenDecl 的文档注释
+// - // doc
+// - type ExampleStruct struct{} -> 文档注释属于 *ast.GenDecl
+func (cm *commentMeta) inheritDoc(genDecl *ast.GenDecl) {
+	if cm.doc == nil && genDecl != nil && !genDecl.Lparen.IsValid() {
+		cm.doc = genDecl.Doc
+	}
+}
+
+// -------------------------------- extractor --------------------------------
+
+// Doc 获取文档注释的原始内容，每行一个元素，包含注释符号
+func (cm *commentMeta) Doc() []string {
+	return commentGroupLines(cm.doc)
+}
+
+// DocText 获取 go/doc 风格清理后的文档注释文本
+// - 去除注释符号，行首空白以及 //go:xxx 等指令
+func (cm *commentMeta) DocText() string {
+	return cm.doc.Text()
+}
+
+// DocLinks 获取文档注释中的所有文档链接: [Name]，[Recv.Method]，[pkg.Name]，[pkg]
+// - ImportPath 为空表示当前 package 内的标识，需要调用方自行确认其是否存在
+// - 仅能识别 标准库单段导入路径 以及 完整导入路径 的 package
+func (cm *commentMeta) DocLinks() []*comment.DocLink {
+	if cm.doc == nil {
+		return nil
+	}
+	parser := &comment.Parser{
+		LookupSym: func(recv, name string) bool { return true },
+	}
+	docLinks := make([]*comment.DocLink, 0)
+	for _, block := range parser.Parse(cm.doc.Text()).Content {
+		foreachCommentText(block, func(text comment.Text) {
+			if docLink, ok := text.(*comment.DocLink); ok {
+				docLinks = append(docLinks, docLink)
+			}
+		})
+	}
+	return docLinks
+}
+
+// Comment 获取行尾注释的原始内容，包含注释符号
+func (cm *commentMeta) Comment() string {
+	if cm.comment == nil || len(cm.comment.List) == 0 {
+		return ""
+	}
+	return cm.comment.List[0].Text
+}
+
+// CommentText 获取清理后的行尾注释文本
+func (cm *commentMeta) CommentText() string {
+	return cm.comment.Text()
+}
+
+// -------------------------------- extractor --------------------------------
+
+// commentGroupLines 获取注释组的原始内容，每行一个元素
+func commentGroupLines(commentGroup *ast.CommentGroup) []string {
+	if commentGroup == nil || len(commentGroup.List) == 0 {
+		return nil
+	}
+	commentSlice := make([]string, 0, len(commentGroup.List))
+	for _, comment := range commentGroup.List {
+		commentSlice = append(commentSlice, comment.Text)
+	}
+	return commentSlice
+}
+
+// foreachCommentText 遍历文档注释块内所有的行内文本
+func foreachCommentText(block comment.Block, f func(comment.Text)) {
+	var foreachText func(texts []comment.Text)
+	foreachText = func(texts []comment.Text) {
+		for _, text := range texts {
+			f(text)
+			if link, ok := text.(*comment.Link); ok {
+				foreachText(link.Text)
+			}
+			if docLink, ok := text.(*comment.DocLink); ok {
+				foreachText(docLink.Text)
+			}
+		}
+	}
+	switch b := block.(type) {
+	case *comment.Paragraph:
+		foreachText(b.Text)
+	case *comment.Heading:
+		foreachText(b.Text)
+	case *comment.List:
+		for _, item := range b.Items {
+			for _, content := range item.Content {
+				foreachCommentText(content, f)
+			}
+		}
+	}
+}
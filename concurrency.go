@@ -0,0 +1,420 @@
+package extractor
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+type ChanOpType int
+
+const (
+	CHAN_OP_MAKE    = iota + 1 // 创建: make(chan T, n)
+	CHAN_OP_SEND               // 发送: ch <- v
+	CHAN_OP_RECEIVE            // 接收: <-ch，v := <-ch，v, ok := <-ch
+	CHAN_OP_RANGE              // 遍历: for v := range ch
+	CHAN_OP_CLOSE              // 关闭: close(ch)
+)
+
+type LockOpType int
+
+const (
+	LOCK_OP_LOCK     = iota + 1 // mu.Lock()
+	LOCK_OP_UNLOCK              // mu.Unlock()
+	LOCK_OP_RLOCK               // mu.RLock()
+	LOCK_OP_RUNLOCK             // mu.RUnlock()
+	LOCK_OP_TRYLOCK             // mu.TryLock()
+	LOCK_OP_TRYRLOCK            // mu.TryRLock()
+)
+
+var lockOpTypeMap = map[string]LockOpType{
+	"Lock":     LOCK_OP_LOCK,
+	"Unlock":   LOCK_OP_UNLOCK,
+	"RLock":    LOCK_OP_RLOCK,
+	"RUnlock":  LOCK_OP_RUNLOCK,
+	"TryLock":  LOCK_OP_TRYLOCK,
+	"TryRLock": LOCK_OP_TRYRLOCK,
+}
+
+// GoGoroutineMeta go 语句 的 meta 数据
+// - go s.loop(ctx) -> callMeta: s.loop(ctx)
+// - go func() {}() -> callMeta 的 callType 为 CALL_TYPE_CLOSURE
+type GoGoroutineMeta struct {
+	// 组合基本 meta 数据
+	// ast 节点，要求为 *ast.GoStmt
+	// 以 ast 节点 为单位执行 AST/PrintAST/Expression/Format
+	*meta
+
+	// go 语句所在的 func 的 meta 数据
+	funcMeta *GoFuncMeta
+
+	// go 语句启动的调用
+	callMeta *GoCallMeta
+}
+
+// GoDeferMeta defer 语句 的 meta 数据
+type GoDeferMeta struct {
+	// 组合基本 meta 数据
+	// ast 节点，要求为 *ast.DeferStmt
+	// 以 ast 节点 为单位执行 AST/PrintAST/Expression/Format
+	*meta
+
+	// defer 语句延迟的调用
+	callMeta *GoCallMeta
+
+	// defer 语句是否位于当前 func 的循环内，不跨越 func 字面量
+	inLoop bool
+}
+
+// GoChanOpMeta channel 操作 的 meta 数据
+// - ch <- v -> opType: CHAN_OP_SEND, channel: ch
+// - make(chan<- int, 1) -> opType: CHAN_OP_MAKE, dir: ast.SEND, elem: int, capacity: 1
+type GoChanOpMeta struct {
+	// 组合基本 meta 数据
+	// ast 节点，*ast.CallExpr，*ast.SendStmt，*ast.UnaryExpr，*ast.RangeStmt
+	// 以 ast 节点 为单位执行 AST/PrintAST/Expression/Format
+	*meta
+
+	// 操作的种类
+	opType ChanOpType
+
+	// channel 表达式，make 时为空
+	channel string
+
+	// channel 类型的方向，无法推断 channel 的类型时为 0
+	dir ast.ChanDir
+
+	// channel 的元素类型，无法推断 channel 的类型时为空
+	elem string
+
+	// make 的缓冲区大小表达式，无缓冲时为空
+	capacity string
+
+	// 是否是 select 的 case
+	inSelect bool
+}
+
+// GoSelectMeta select 语句 的 meta 数据
+type GoSelectMeta struct {
+	// 组合基本 meta 数据
+	// ast 节点，要求为 *ast.SelectStmt
+	// 以 ast 节点 为单位执行 AST/PrintAST/Expression/Format
+	*meta
+
+	// 所有非 default 的 case 的 channel 操作，按照源码顺序
+	cases []*GoChanOpMeta
+
+	// 是否有 default 分支
+	hasDefault bool
+}
+
+// GoLockMeta sync.Mutex/sync.RWMutex 加锁和解锁 的 meta 数据
+// - s.mu.Lock() -> mutex: s.mu, opType: LOCK_OP_LOCK
+type GoLockMeta struct {
+	// 组合基本 meta 数据
+	// ast 节点，要求为 *ast.CallExpr
+	// 以 ast 节点 为单位执行 AST/PrintAST/Expression/Format
+	*meta
+
+	// mutex 表达式，嵌入 mutex 时为 struct 的表达式
+	mutex string
+
+	// 操作的种类
+	opType LockOpType
+
+	// 是否由 defer 语句直接延迟执行
+	deferred bool
+}
+
+// GoLockPairMeta 同一个 mutex 的加锁与对应的解锁
+type GoLockPairMeta struct {
+	lock   *GoLockMeta
+	unlock *GoLockMeta
+}
+
+// GoConcurrencyMeta func 的并发特征
+// - 包括 func 字面量内的语句
+type GoConcurrencyMeta struct {
+	// 所属的 func 的 meta 数据
+	funcMeta *GoFuncMeta
+
+	goroutines []*GoGoroutineMeta
+	defers     []*GoDeferMeta
+	chanOps    []*GoChanOpMeta // 所有 channel 操作，按照源码顺序，包括 select 的 case 中的操作
+	selects    []*GoSelectMeta
+	locks      []*GoLockMeta
+}
+
+// -------------------------------- extractor --------------------------------
+
+// Concurrency 提取 func 内的 go 语句，defer 语句，channel 操作，select 语句，加锁和解锁
+func (gfm *GoFuncMeta) Concurrency() *GoConcurrencyMeta {
+	gccm := &GoConcurrencyMeta{funcMeta: gfm}
+	body := funcBody(gfm.node)
+	if body == nil {
+		return gccm
+	}
+	ctx := newCallContext(gfm.packageMeta, gfm.path, gfm.scopeInfo())
+	ctx.funcMeta = gfm
+
+	// select 的 case 语句中的 channel 操作，key: 操作的 ast 节点
+	selectOps := make(map[ast.Node]*GoChanOpMeta)
+	deferredCalls := make(map[*ast.CallExpr]struct{})
+	stack := make([]ast.Node, 0)
+	ast.Inspect(body, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		stack = append(stack, n)
+		switch node := n.(type) {
+		case *ast.GoStmt:
+			gccm.goroutines = append(gccm.goroutines, &GoGoroutineMeta{
+				meta:     gfm.copyMeta(node),
+				funcMeta: gfm,
+				callMeta: newGoCallMeta(gfm.copyMeta(node.Call), ctx),
+			})
+		case *ast.DeferStmt:
+			deferredCalls[node.Call] = struct{}{}
+			gccm.defers = append(gccm.defers, &GoDeferMeta{
+				meta:     gfm.copyMeta(node),
+				callMeta: newGoCallMeta(gfm.copyMeta(node.Call), ctx),
+				inLoop:   inLoop(stack),
+			})
+		case *ast.SelectStmt:
+			gsm := &GoSelectMeta{meta: gfm.copyMeta(node)}
+			for _, clause := range node.Body.List {
+				comm := clause.(*ast.CommClause).Comm
+				if comm == nil {
+					gsm.hasDefault = true
+					continue
+				}
+				if op := gccm.commOp(comm, ctx); op != nil {
+					op.inSelect = true
+					gsm.cases = append(gsm.cases, op)
+					selectOps[op.node] = op
+				}
+			}
+			gccm.selects = append(gccm.selects, gsm)
+		case *ast.SendStmt:
+			if op, has := selectOps[node]; has {
+				gccm.chanOps = append(gccm.chanOps, op)
+			} else {
+				gccm.chanOps = append(gccm.chanOps, gccm.newChanOp(node, CHAN_OP_SEND, node.Chan, ctx))
+			}
+		case *ast.UnaryExpr:
+			if op, has := selectOps[node]; has {
+				gccm.chanOps = append(gccm.chanOps, op)
+			} else if node.Op == token.ARROW {
+				gccm.chanOps = append(gccm.chanOps, gccm.newChanOp(node, CHAN_OP_RECEIVE, node.X, ctx))
+			}
+		case *ast.RangeStmt:
+			if _, ok := ctx.inferExprType(node.X, 0, 0).(*ast.ChanType); ok {
+				gccm.chanOps = append(gccm.chanOps, gccm.newChanOp(node, CHAN_OP_RANGE, node.X, ctx))
+			}
+		case *ast.CallExpr:
+			gccm.callOp(node, deferredCalls, ctx)
+		}
+		return true
+	})
+	return gccm
+}
+
+// inLoop 节点栈的栈顶是否位于循环内，遇到 func 字面量时停止
+func inLoop(stack []ast.Node) bool {
+	for index := len(stack) - 2; index >= 0; index-- {
+		switch stack[index].(type) {
+		case *ast.ForStmt, *ast.RangeStmt:
+			return true
+		case *ast.FuncLit:
+			return false
+		}
+	}
+	return false
+}
+
+// commOp 解析 select 的 case 语句中的 channel 操作
+func (gccm *GoConcurrencyMeta) commOp(comm ast.Stmt, ctx *callContext) *GoChanOpMeta {
+	var expr ast.Expr
+	switch s := comm.(type) {
+	case *ast.SendStmt:
+		return gccm.newChanOp(s, CHAN_OP_SEND, s.Chan, ctx)
+	case *ast.ExprStmt:
+		expr = s.X
+	case *ast.AssignStmt:
+		if len(s.Rhs) == 1 {
+			expr = s.Rhs[0]
+		}
+	}
+	if unaryExpr, ok := ast.Unparen(expr).(*ast.UnaryExpr); ok && unaryExpr.Op == token.ARROW {
+		return gccm.newChanOp(unaryExpr, CHAN_OP_RECEIVE, unaryExpr.X, ctx)
+	}
+	return nil
+}
+
+func (gccm *GoConcurrencyMeta) newChanOp(node ast.Node, opType ChanOpType, channel ast.Expr, ctx *callContext) *GoChanOpMeta {
+	op := &GoChanOpMeta{meta: gccm.funcMeta.copyMeta(node), opType: opType, channel: types.ExprString(channel)}
+	if chanType, ok := ctx.inferExprType(channel, 0, 0).(*ast.ChanType); ok {
+		op.dir, op.elem = chanType.Dir, types.ExprString(chanType.Value)
+	}
+	return op
+}
+
+// callOp 解析调用中的 make，close，加锁和解锁
+func (gccm *GoConcurrencyMeta) callOp(callExpr *ast.CallExpr, deferredCalls map[*ast.CallExpr]struct{}, ctx *callContext) {
+	switch fun := ast.Unparen(callExpr.Fun).(type) {
+	case *ast.Ident:
+		if ctx.scopeInfo.uses[fun] != nil || !isPredeclaredFunc(fun.Name) || len(callExpr.Args) == 0 {
+			return
+		}
+		switch fun.Name {
+		case "make":
+			if chanType, ok := callExpr.Args[0].(*ast.ChanType); ok {
+				op := &GoChanOpMeta{meta: gccm.funcMeta.copyMeta(callExpr), opType: CHAN_OP_MAKE, dir: chanType.Dir, elem: types.ExprString(chanType.Value)}
+				if len(callExpr.Args) > 1 {
+					op.capacity = types.ExprString(callExpr.Args[1])
+				}
+				gccm.chanOps = append(gccm.chanOps, op)
+			}
+		case "close":
+			gccm.chanOps = append(gccm.chanOps, gccm.newChanOp(callExpr, CHAN_OP_CLOSE, callExpr.Args[0], ctx))
+		}
+	case *ast.SelectorExpr:
+		opType, has := lockOpTypeMap[fun.Sel.Name]
+		if !has || len(callExpr.Args) > 0 || !ctx.isMutex(fun.X) {
+			return
+		}
+		_, deferred := deferredCalls[callExpr]
+		gccm.locks = append(gccm.locks, &GoLockMeta{
+			meta:     gccm.funcMeta.copyMeta(callExpr),
+			mutex:    types.ExprString(fun.X),
+			opType:   opType,
+			deferred: deferred,
+		})
+	}
+}
+
+// isMutex 表达式的类型是否是 sync.Mutex，sync.RWMutex 或嵌入了它们的 struct
+func (ctx *callContext) isMutex(expr ast.Expr) bool {
+	if ctx.isMutexType(ctx.inferExprType(expr, 0, 0)) {
+		return true
+	}
+	gsm := ctx.typeOfExpr(expr).structMeta()
+	if gsm == nil {
+		return false
+	}
+	for _, gvm := range gsm.memberMetaMap {
+		if field, ok := gvm.node.(*ast.Field); ok && len(field.Names) == 0 && ctx.isMutexType(field.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// isMutexType 类型表达式是否是 sync.Mutex，sync.RWMutex 或它们的指针
+// - 限定符为当前文件导入 sync 的标识，当前文件未导入时为 sync
+func (ctx *callContext) isMutexType(typeExpr ast.Expr) bool {
+	if starExpr, ok := typeExpr.(*ast.StarExpr); ok {
+		typeExpr = starExpr.X
+	}
+	selectorExpr, ok := typeExpr.(*ast.SelectorExpr)
+	if !ok || (selectorExpr.Sel.Name != "Mutex" && selectorExpr.Sel.Name != "RWMutex") {
+		return false
+	}
+	qualifier, ok := selectorExpr.X.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	if ctx.fileMeta != nil {
+		if gim := ctx.fileMeta.SearchImport(qualifier.Name); gim != nil {
+			return gim.importPath == "sync"
+		}
+	}
+	return qualifier.Name == "sync"
+}
+
+// LockPairs 按照源码顺序配对同一个 mutex 的加锁和解锁，返回配对结果以及未配对的操作
+// - Lock/TryLock 与 Unlock 配对，RLock/TryRLock 与 RUnlock 配对
+func (gccm *GoConcurrencyMeta) LockPairs() ([]*GoLockPairMeta, []*GoLockMeta) {
+	type lockKey struct {
+		mutex string
+		read  bool
+	}
+	pairs := make([]*GoLockPairMeta, 0)
+	paired := make(map[*GoLockMeta]struct{})
+	pending := make(map[lockKey][]*GoLockMeta)
+	for _, glm := range gccm.locks {
+		switch glm.opType {
+		case LOCK_OP_LOCK, LOCK_OP_TRYLOCK, LOCK_OP_RLOCK, LOCK_OP_TRYRLOCK:
+			key := lockKey{glm.mutex, glm.opType == LOCK_OP_RLOCK || glm.opType == LOCK_OP_TRYRLOCK}
+			pending[key] = append(pending[key], glm)
+		case LOCK_OP_UNLOCK, LOCK_OP_RUNLOCK:
+			key := lockKey{glm.mutex, glm.opType == LOCK_OP_RUNLOCK}
+			if stack := pending[key]; len(stack) > 0 {
+				lock := stack[len(stack)-1]
+				pairs = append(pairs, &GoLockPairMeta{lock: lock, unlock: glm})
+				paired[lock], paired[glm] = struct{}{}, struct{}{}
+				pending[key] = stack[:len(stack)-1]
+			}
+		}
+	}
+	unpaired := make([]*GoLockMeta, 0)
+	for _, glm := range gccm.locks {
+		if _, has := paired[glm]; !has {
+			unpaired = append(unpaired, glm)
+		}
+	}
+	return pairs, unpaired
+}
+
+// Launched 获取 go 语句启动的项目内的 func 或 method，闭包和无法解析时为 nil
+func (ggm *GoGoroutineMeta) Launched() *GoFuncMeta {
+	gfm, _ := ggm.callMeta.resolve()
+	return gfm
+}
+
+// GoroutineSpawnSites 获取项目内所有 go 语句，按照 package，func，struct 的 method 的声明顺序排序
+func (gpm *GoProjectMeta) GoroutineSpawnSites() []*GoGoroutineMeta {
+	goroutines := make([]*GoGoroutineMeta, 0)
+	for _, packageMeta := range gpm.Packages() {
+		for _, gfm := range packageMeta.Funcs() {
+			goroutines = append(goroutines, gfm.Concurrency().goroutines...)
+		}
+		for _, gsm := range packageMeta.Structs() {
+			for _, gmm := range gsm.Methods() {
+				goroutines = append(goroutines, gmm.Concurrency().goroutines...)
+			}
+		}
+	}
+	return goroutines
+}
+
+// -------------------------------- unit test --------------------------------
+
+func (ggm *GoGoroutineMeta) FuncMeta() *GoFuncMeta             { return ggm.funcMeta }
+func (ggm *GoGoroutineMeta) CallMeta() *GoCallMeta             { return ggm.callMeta }
+func (ggm *GoGoroutineMeta) Pos() token.Position               { return ggm.position(ggm.node.Pos()) }
+func (gdm *GoDeferMeta) CallMeta() *GoCallMeta                 { return gdm.callMeta }
+func (gdm *GoDeferMeta) InLoop() bool                          { return gdm.inLoop }
+func (gdm *GoDeferMeta) Pos() token.Position                   { return gdm.position(gdm.node.Pos()) }
+func (gcom *GoChanOpMeta) OpType() ChanOpType                  { return gcom.opType }
+func (gcom *GoChanOpMeta) Channel() string                     { return gcom.channel }
+func (gcom *GoChanOpMeta) Dir() ast.ChanDir                    { return gcom.dir }
+func (gcom *GoChanOpMeta) Elem() string                        { return gcom.elem }
+func (gcom *GoChanOpMeta) Capacity() string                    { return gcom.capacity }
+func (gcom *GoChanOpMeta) InSelect() bool                      { return gcom.inSelect }
+func (gcom *GoChanOpMeta) Pos() token.Position                 { return gcom.position(gcom.node.Pos()) }
+func (gsm *GoSelectMeta) Cases() []*GoChanOpMeta               { return gsm.cases }
+func (gsm *GoSelectMeta) HasDefault() bool                     { return gsm.hasDefault }
+func (glm *GoLockMeta) Mutex() string                          { return glm.mutex }
+func (glm *GoLockMeta) OpType() LockOpType                     { return glm.opType }
+func (glm *GoLockMeta) IsDeferred() bool                       { return glm.deferred }
+func (glm *GoLockMeta) Pos() token.Position                    { return glm.position(glm.node.Pos()) }
+func (glpm *GoLockPairMeta) Lock() *GoLockMeta                 { return glpm.lock }
+func (glpm *GoLockPairMeta) Unlock() *GoLockMeta               { return glpm.unlock }
+func (gccm *GoConcurrencyMeta) FuncMeta() *GoFuncMeta          { return gccm.funcMeta }
+func (gccm *GoConcurrencyMeta) Goroutines() []*GoGoroutineMeta { return gccm.goroutines }
+func (gccm *GoConcurrencyMeta) Defers() []*GoDeferMeta         { return gccm.defers }
+func (gccm *GoConcurrencyMeta) ChanOps() []*GoChanOpMeta       { return gccm.chanOps }
+func (gccm *GoConcurrencyMeta) Selects() []*GoSelectMeta       { return gccm.selects }
+func (gccm *GoConcurrencyMeta) Locks() []*GoLockMeta           { return gccm.locks }
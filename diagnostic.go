@@ -0,0 +1,38 @@
+package extractor
+
+import (
+	"fmt"
+	"go/token"
+)
+
+// GoDiagnostic 分析得到的诊断信息，带有在文件中的位置，格式与 go vet 的输出一致
+// - file.go:12:2: struct field tag `json:name` not compatible with reflect.StructTag.Get: bad syntax for struct tag value
+type GoDiagnostic struct {
+	// 诊断信息在文件中的起止位置
+	pos, end token.Position
+
+	// 诊断信息的分类: structtag，unusedresult 等
+	category string
+
+	// 诊断信息的内容
+	message string
+}
+
+// newGoDiagnostic 构造 诊断信息
+func newGoDiagnostic(pos, end token.Position, category, format string, args ...any) *GoDiagnostic {
+	return &GoDiagnostic{pos: pos, end: end, category: category, message: fmt.Sprintf(format, args...)}
+}
+
+// String 输出 位置: 内容 格式的诊断信息
+func (gd *GoDiagnostic) String() string {
+	return fmt.Sprintf("%v: %v", gd.pos, gd.message)
+}
+
+// -------------------------------- unit test --------------------------------
+
+func (gd *GoDiagnostic) Pos() token.Position { return gd.pos }
+func (gd *GoDiagnostic) End() token.Position { return gd.end }
+func (gd *GoDiagnostic) Category() string    { return gd.category }
+func (gd *GoDiagnostic) Message() string     { return gd.message }
+
+// -------------------------------- unit test --------------------------------
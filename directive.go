@@ -0,0 +1,228 @@
+package extractor
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build/constraint"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type DirectiveType int
+
+const (
+	DIRECTIVE_TYPE_OTHER    = iota + 1 // 其他指令: //go:nosplit，//lint:ignore，//export 等
+	DIRECTIVE_TYPE_GENERATE            // //go:generate command args...
+	DIRECTIVE_TYPE_EMBED               // //go:embed patterns...
+	DIRECTIVE_TYPE_NOINLINE            // //go:noinline
+	DIRECTIVE_TYPE_LINKNAME            // //go:linkname localname [importpath.name]
+	DIRECTIVE_TYPE_BUILD               // //go:build expr
+)
+
+// GoDirectiveMeta go 编译指令 的 meta 数据
+// - //go:generate stringer -type=Pill -> tool: go, name: generate, args: [stringer, -type=Pill]
+type GoDirectiveMeta struct {
+	// 组合基本 meta 数据
+	// ast 节点，要求为 *ast.Comment
+	// 以 ast 节点 为单位执行 AST/PrintAST/Expression/Format
+	*meta
+
+	// 指令的种类
+	directiveType DirectiveType
+
+	// 指令的工具名称: //go:generate -> go，//line，//extern，//export 没有工具名称
+	tool string
+
+	// 指令的名称: //go:generate -> generate
+	name string
+
+	// 指令的原始参数: //go:embed "a b.txt" c.txt -> "a b.txt" c.txt
+	rawArgs string
+}
+
+// newGoDirectiveMeta 通过 ast 构造 编译指令 的 meta 数据
+// - 注释不是指令时返回 nil
+func newGoDirectiveMeta(m *meta) *GoDirectiveMeta {
+	text, ok := strings.CutPrefix(m.node.(*ast.Comment).Text, "//")
+	if !ok || !isDirective(text) {
+		return nil
+	}
+	directive, rawArgs, _ := strings.Cut(text, " ")
+	tool, name, hasTool := strings.Cut(directive, ":")
+	if !hasTool {
+		tool, name = "", directive
+	}
+	gdm := &GoDirectiveMeta{
+		meta:          m,
+		directiveType: DIRECTIVE_TYPE_OTHER,
+		tool:          tool,
+		name:          name,
+		rawArgs:       strings.TrimSpace(rawArgs),
+	}
+	if tool == "go" {
+		switch name {
+		case "generate":
+			gdm.directiveType = DIRECTIVE_TYPE_GENERATE
+		case "embed":
+			gdm.directiveType = DIRECTIVE_TYPE_EMBED
+		case "noinline":
+			gdm.directiveType = DIRECTIVE_TYPE_NOINLINE
+		case "linkname":
+			gdm.directiveType = DIRECTIVE_TYPE_LINKNAME
+		case "build":
+			gdm.directiveType = DIRECTIVE_TYPE_BUILD
+		}
+	}
+	return gdm
+}
+
+// isDirective 判断去除 // 后的注释是否是指令，与 go/ast 的判断规则一致
+// - //line，//extern，//export 为编译器和 cgo 的指令
+// - 其他指令为 tool:name 形式，注释符号与指令之间不能有空格
+// - tool 与 name 的首个字符只能是小写字母和数字
+func isDirective(text string) bool {
+	if strings.HasPrefix(text, "line ") || strings.HasPrefix(text, "extern ") || strings.HasPrefix(text, "export ") {
+		return true
+	}
+	colon := strings.Index(text, ":")
+	if colon <= 0 || colon+1 >= len(text) {
+		return false
+	}
+	for i := 0; i <= colon+1; i++ {
+		if i == colon {
+			continue
+		}
+		if b := text[i]; !('a' <= b && b <= 'z' || '0' <= b && b <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// extractDirectiveMetas 提取注释组内所有 编译指令 的 meta 数据
+func extractDirectiveMetas(m *meta, commentGroup *ast.CommentGroup) []*GoDirectiveMeta {
+	if commentGroup == nil {
+		return nil
+	}
+	directiveMetas := make([]*GoDirectiveMeta, 0)
+	for _, c := range commentGroup.List {
+		if gdm := newGoDirectiveMeta(m.copyMeta(c)); gdm != nil {
+			directiveMetas = append(directiveMetas, gdm)
+		}
+	}
+	return directiveMetas
+}
+
+// -------------------------------- extractor --------------------------------
+
+// Args 获取指令的参数，支持 "..." 和 `...` 形式的带空格参数
+func (gdm *GoDirectiveMeta) Args() []string {
+	args, _ := splitDirectiveArgs(gdm.rawArgs)
+	return args
+}
+
+// BuildConstraint 解析 //go:build 指令的构建约束表达式
+func (gdm *GoDirectiveMeta) BuildConstraint() (constraint.Expr, error) {
+	if gdm.directiveType != DIRECTIVE_TYPE_BUILD {
+		return nil, fmt.Errorf("directive %v:%v is not go:build", gdm.tool, gdm.name)
+	}
+	return constraint.Parse(gdm.node.(*ast.Comment).Text)
+}
+
+// EmbedFiles 将 //go:embed 指令的 pattern 解析为磁盘上匹配的文件的绝对路径，按照路径排序
+// - pattern 相对于指令所在文件的目录
+// - 匹配到目录时递归包含其中的文件，除非使用 all: 前缀，否则忽略以 . 或 _ 开头的文件和目录
+// - 任意 pattern 没有匹配到文件时返回错误
+func (gdm *GoDirectiveMeta) EmbedFiles() ([]string, error) {
+	if gdm.directiveType != DIRECTIVE_TYPE_EMBED {
+		return nil, fmt.Errorf("directive %v:%v is not go:embed", gdm.tool, gdm.name)
+	}
+	patterns, err := splitDirectiveArgs(gdm.rawArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(gdm.path)
+	embedFileMap := make(map[string]struct{})
+	for _, pattern := range patterns {
+		pattern, all := strings.CutPrefix(pattern, "all:")
+		matches, err := filepath.Glob(filepath.Join(dir, filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, fmt.Errorf("pattern %v: %v", pattern, err)
+		}
+		count := len(embedFileMap)
+		for _, match := range matches {
+			err = filepath.WalkDir(match, func(walkPath string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if walkPath != match && !all && (strings.HasPrefix(d.Name(), ".") || strings.HasPrefix(d.Name(), "_")) {
+					if d.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if !d.IsDir() {
+					embedFileMap[walkPath] = struct{}{}
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(embedFileMap) == count {
+			return nil, fmt.Errorf("pattern %v: no matching files found", pattern)
+		}
+	}
+
+	embedFiles := make([]string, 0, len(embedFileMap))
+	for embedFile := range embedFileMap {
+		embedFiles = append(embedFiles, embedFile)
+	}
+	sort.Strings(embedFiles)
+	return embedFiles, nil
+}
+
+// Pos 指令在文件中的位置
+func (gdm *GoDirectiveMeta) Pos() token.Position {
+	return gdm.position(gdm.node.Pos())
+}
+
+// splitDirectiveArgs 按照空白拆分指令的参数，"..." 和 `...` 视为一个参数
+func splitDirectiveArgs(rawArgs string) ([]string, error) {
+	args := make([]string, 0)
+	for rawArgs = strings.TrimSpace(rawArgs); len(rawArgs) > 0; rawArgs = strings.TrimSpace(rawArgs) {
+		var arg string
+		switch rawArgs[0] {
+		case '"', '`':
+			quote, err := strconv.QuotedPrefix(rawArgs)
+			if err != nil {
+				return args, fmt.Errorf("invalid quoted string in directive: %v", rawArgs)
+			}
+			arg, _ = strconv.Unquote(quote)
+			rawArgs = rawArgs[len(quote):]
+		default:
+			end := strings.IndexAny(rawArgs, " \t")
+			if end < 0 {
+				end = len(rawArgs)
+			}
+			arg, rawArgs = rawArgs[:end], rawArgs[end:]
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+// -------------------------------- unit test --------------------------------
+
+func (gdm *GoDirectiveMeta) DirectiveType() DirectiveType { return gdm.directiveType }
+func (gdm *GoDirectiveMeta) Tool() string                 { return gdm.tool }
+func (gdm *GoDirectiveMeta) Name() string                 { return gdm.name }
+func (gdm *GoDirectiveMeta) RawArgs() string              { return gdm.rawArgs }
+
+// -------------------------------- unit test --------------------------------
@@ -0,0 +1,346 @@
+package extractor
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"strings"
+	"sync"
+)
+
+// stdImporter 通过 GOROOT 内的源码解析标准库的 package
+// - stdPackages 缓存解析结果，解析失败时缓存为 nil，避免重复解析
+var (
+	stdImporterOnce sync.Once
+	stdImporter     types.Importer
+	stdImporterLock sync.Mutex
+	stdPackages     = make(map[string]*types.Package)
+)
+
+// importStdPackage 解析标准库的 package，不是标准库或无法解析时返回 nil
+func importStdPackage(importPath string) *types.Package {
+	if len(importPath) == 0 || strings.Contains(strings.Split(importPath, "/")[0], ".") {
+		return nil
+	}
+	stdImporterOnce.Do(func() {
+		stdImporter = importer.ForCompiler(token.NewFileSet(), "source", nil)
+	})
+	stdImporterLock.Lock()
+	defer stdImporterLock.Unlock()
+	if pkg, has := stdPackages[importPath]; has {
+		return pkg
+	}
+	pkg, err := stdImporter.Import(importPath)
+	if err != nil {
+		pkg = nil
+	}
+	stdPackages[importPath] = pkg
+	return pkg
+}
+
+// errCheckExcludes 不检查 error 返回值的标准库 func 和 method
+// - key: importPath.F 或 importPath.T.M
+var errCheckExcludes = map[string]struct{}{
+	"fmt.Print":                   {},
+	"fmt.Printf":                  {},
+	"fmt.Println":                 {},
+	"bytes.Buffer.Write":          {},
+	"bytes.Buffer.WriteByte":      {},
+	"bytes.Buffer.WriteRune":      {},
+	"bytes.Buffer.WriteString":    {},
+	"strings.Builder.Write":       {},
+	"strings.Builder.WriteByte":   {},
+	"strings.Builder.WriteRune":   {},
+	"strings.Builder.WriteString": {},
+}
+
+// errorResults 获取调用的返回值数量以及 error 类型的返回值的序号，无法获取签名时返回值数量为 0
+// - 项目内的 func，struct 的 method，interface 的 method 使用 GoFuncMeta.Returns 判断
+// - 标准库的 func 和 method 使用标准库源码中的签名判断
+func (ctx *callContext) errorResults(callExpr *ast.CallExpr) (int, []int) {
+	gcm := newGoCallMeta(ctx.newMeta(callExpr), ctx)
+	if gcm.callType != CALL_TYPE_FUNC && gcm.callType != CALL_TYPE_METHOD {
+		return 0, nil
+	}
+	gfm, receiverType := gcm.resolve()
+	if gfm != nil {
+		return varMetaErrorResults(gfm.returns)
+	}
+	if gim := receiverType.interfaceMeta(); gim != nil {
+		if gimm := gim.methodMetaMap[gcm.callee]; gimm != nil {
+			return varMetaErrorResults(gimm.returns)
+		}
+		return 0, nil
+	}
+	if signature := ctx.stdSignature(gcm); signature != nil {
+		indices := make([]int, 0)
+		for index := 0; index < signature.Results().Len(); index++ {
+			if types.Identical(signature.Results().At(index).Type(), types.Universe.Lookup("error").Type()) {
+				indices = append(indices, index)
+			}
+		}
+		return signature.Results().Len(), indices
+	}
+	return 0, nil
+}
+
+func varMetaErrorResults(returns []*GoVarMeta) (int, []int) {
+	indices := make([]int, 0)
+	for index, gvm := range returns {
+		if gvm.typeExpr != nil && types.ExprString(gvm.typeExpr.node.(ast.Expr)) == "error" {
+			indices = append(indices, index)
+		}
+	}
+	return len(returns), indices
+}
+
+// stdSignature 获取标准库的 func 或 method 的签名
+// - pkg.F()
+// - x.M()，x 的类型为 pkg.T 或 *pkg.T
+func (ctx *callContext) stdSignature(gcm *GoCallMeta) *types.Signature {
+	selectorExpr, ok := ast.Unparen(gcm.node.(*ast.CallExpr).Fun).(*ast.SelectorExpr)
+	if !ok {
+		return nil
+	}
+	var object types.Object
+	switch gcm.callType {
+	case CALL_TYPE_FUNC:
+		if len(gcm.importPath) == 0 || (ctx.packageMeta != nil && gcm.importPath == ctx.packageMeta.importPath) {
+			return nil
+		}
+		if _, excluded := errCheckExcludes[gcm.importPath+"."+gcm.callee]; excluded {
+			return nil
+		}
+		if pkg := importStdPackage(gcm.importPath); pkg != nil {
+			object = pkg.Scope().Lookup(gcm.callee)
+		}
+	case CALL_TYPE_METHOD:
+		typeExpr := ctx.inferExprType(selectorExpr.X, 0, 0)
+		if starExpr, ok := typeExpr.(*ast.StarExpr); ok {
+			typeExpr = starExpr.X
+		}
+		typeSelector, ok := typeExpr.(*ast.SelectorExpr)
+		if !ok {
+			return nil
+		}
+		qualifier, ok := typeSelector.X.(*ast.Ident)
+		if !ok || ctx.fileMeta == nil || ctx.fileMeta.SearchImport(qualifier.Name) == nil {
+			return nil
+		}
+		importPath := ctx.fileMeta.SearchImport(qualifier.Name).importPath
+		if _, excluded := errCheckExcludes[importPath+"."+typeSelector.Sel.Name+"."+gcm.callee]; excluded {
+			return nil
+		}
+		pkg := importStdPackage(importPath)
+		if pkg == nil {
+			return nil
+		}
+		typeName, ok := pkg.Scope().Lookup(typeSelector.Sel.Name).(*types.TypeName)
+		if !ok {
+			return nil
+		}
+		object, _, _ = types.LookupFieldOrMethod(types.NewPointer(typeName.Type()), true, pkg, gcm.callee)
+	}
+	if fn, ok := object.(*types.Func); ok {
+		return fn.Type().(*types.Signature)
+	}
+	return nil
+}
+
+// errCheckWalker 检查 func 内未处理的 error
+type errCheckWalker struct {
+	ctx         *callContext
+	stack       []ast.Node
+	diagnostics []*GoDiagnostic
+}
+
+// UncheckedErrors 检查 func 内未处理的 error 返回值，包括 func 字面量内的调用
+// - 调用语句丢弃了 error 返回值: f()
+// - defer 语句和 go 语句延迟执行的调用丢弃了 error 返回值: defer f.Close()，go f()
+// - error 返回值赋值给 _: v, _ := f()
+// - error 赋值给变量后，在被读取之前在同一个作用域内被再次赋值: err = f(); err = g()
+// - error 使用 := 赋值给遮蔽了外层同名变量的变量，且外层变量在之后被读取
+// - error 赋值给变量后从未被读取，循环内的赋值在下一次迭代中赋值之前的读取视为已读取
+// - 命名返回值视为已处理，errCheckExcludes 内的调用不检查
+func (gfm *GoFuncMeta) UncheckedErrors() []*GoDiagnostic {
+	body := funcBody(gfm.node)
+	if body == nil {
+		return nil
+	}
+	w := &errCheckWalker{ctx: newCallContext(gfm.packageMeta, gfm.path, gfm.scopeInfo()), diagnostics: make([]*GoDiagnostic, 0)}
+	w.ctx.funcMeta = gfm
+	ast.Inspect(body, func(n ast.Node) bool {
+		if n == nil {
+			w.stack = w.stack[:len(w.stack)-1]
+			return true
+		}
+		w.stack = append(w.stack, n)
+		switch s := n.(type) {
+		case *ast.ExprStmt:
+			if callExpr, ok := ast.Unparen(s.X).(*ast.CallExpr); ok {
+				if _, indices := w.ctx.errorResults(callExpr); len(indices) > 0 {
+					w.report(callExpr, "error return value of %v is not checked", types.ExprString(callExpr.Fun))
+				}
+			}
+		case *ast.DeferStmt:
+			if _, indices := w.ctx.errorResults(s.Call); len(indices) > 0 {
+				w.report(s.Call, "error return value of %v is discarded by defer", types.ExprString(s.Call.Fun))
+			}
+		case *ast.GoStmt:
+			if _, indices := w.ctx.errorResults(s.Call); len(indices) > 0 {
+				w.report(s.Call, "error return value of %v is discarded by go statement", types.ExprString(s.Call.Fun))
+			}
+		case *ast.AssignStmt:
+			if s.Tok == token.ASSIGN || s.Tok == token.DEFINE {
+				w.checkAssign(s.Lhs, s.Rhs)
+			}
+		case *ast.ValueSpec:
+			lhs := make([]ast.Expr, 0, len(s.Names))
+			for _, name := range s.Names {
+				lhs = append(lhs, name)
+			}
+			w.checkAssign(lhs, s.Values)
+		}
+		return true
+	})
+	return w.diagnostics
+}
+
+func (w *errCheckWalker) report(node ast.Node, format string, args ...any) {
+	w.diagnostics = append(w.diagnostics, newGoDiagnostic(w.ctx.position(node.Pos()), w.ctx.position(node.End()), "errcheck", format, args...))
+}
+
+// checkAssign 检查赋值语句中 error 返回值对应的左值
+func (w *errCheckWalker) checkAssign(lhs, rhs []ast.Expr) {
+	if len(rhs) == 1 && len(lhs) > 1 {
+		callExpr, ok := ast.Unparen(rhs[0]).(*ast.CallExpr)
+		if !ok {
+			return
+		}
+		count, indices := w.ctx.errorResults(callExpr)
+		if count != len(lhs) {
+			return
+		}
+		for _, index := range indices {
+			w.checkLhs(lhs[index], callExpr)
+		}
+		return
+	}
+	if len(rhs) != len(lhs) {
+		return
+	}
+	for index, expr := range rhs {
+		callExpr, ok := ast.Unparen(expr).(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		if count, indices := w.ctx.errorResults(callExpr); count == 1 && len(indices) == 1 {
+			w.checkLhs(lhs[index], callExpr)
+		}
+	}
+}
+
+// checkLhs 检查接收 error 返回值的左值
+func (w *errCheckWalker) checkLhs(lhs ast.Expr, callExpr *ast.CallExpr) {
+	ident, ok := lhs.(*ast.Ident)
+	if !ok {
+		// 赋值给字段，下标等视为已处理
+		return
+	}
+	if ident.Name == "_" {
+		w.report(ident, "error return value of %v is assigned to _", types.ExprString(callExpr.Fun))
+		return
+	}
+	info := w.ctx.scopeInfo
+	obj, declared := info.defs[ident], true
+	if obj == nil {
+		obj, declared = info.uses[ident], false
+	}
+	if obj == nil || obj.kind == scopeObjectResult {
+		// package 级变量以及命名返回值视为已处理
+		return
+	}
+	assignScope := obj.scope
+	if !declared {
+		assignScope = info.useScopes[ident]
+	}
+
+	// 按照源码顺序查找赋值之后的第一次读取或同一作用域内的再次赋值
+	var next *ast.Ident
+	for _, read := range obj.reads {
+		if read.Pos() > ident.Pos() && (next == nil || read.Pos() < next.Pos()) {
+			next = read
+		}
+	}
+	for _, write := range obj.writes {
+		if write.Pos() > ident.Pos() && info.useScopes[write] == assignScope && (next == nil || write.Pos() < next.Pos()) {
+			w.report(ident, "error assigned to %v is overwritten at %v before it is checked", ident.Name, w.ctx.position(write.Pos()))
+			return
+		}
+	}
+	if declared && obj.scope.parent != nil {
+		// 外层同名变量在当前作用域结束之后被读取，error 本应赋值给外层变量
+		if outer := obj.scope.parent.lookup(ident.Name); outer != nil && outer.ident.Pos() < ident.Pos() {
+			for _, read := range outer.reads {
+				if read.Pos() >= obj.scope.end() {
+					w.report(ident, "error assigned to %v shadows the declaration at %v, which is checked at %v", ident.Name, w.ctx.position(outer.ident.Pos()), w.ctx.position(read.Pos()))
+					return
+				}
+			}
+		}
+	}
+	if next == nil && w.loopRead(obj, ident) == nil {
+		w.report(ident, "error assigned to %v is never checked", ident.Name)
+	}
+}
+
+// loopRead 赋值位于循环内时，查找下一次迭代中位于赋值之前的读取
+// - for 循环的条件和 post 语句在每次迭代中执行，range 循环只有循环体
+// - 在循环体内声明的变量每次迭代重新声明，不查找
+func (w *errCheckWalker) loopRead(obj *scopeObject, ident *ast.Ident) *ast.Ident {
+	for _, node := range w.stack {
+		var start token.Pos
+		var body *ast.BlockStmt
+		switch loop := node.(type) {
+		case *ast.ForStmt:
+			start, body = loop.Body.Pos(), loop.Body
+			if loop.Post != nil {
+				start = loop.Post.Pos()
+			}
+			if loop.Cond != nil {
+				start = loop.Cond.Pos()
+			}
+		case *ast.RangeStmt:
+			start, body = loop.Body.Pos(), loop.Body
+		default:
+			continue
+		}
+		if obj.ident.Pos() >= body.Pos() {
+			continue
+		}
+		for _, read := range obj.reads {
+			if read.Pos() >= start && read.Pos() < ident.Pos() {
+				return read
+			}
+		}
+	}
+	return nil
+}
+
+// UncheckedErrors 检查项目内所有 func 和 struct 的 method 内未处理的 error 返回值
+// - 按照 package，func，struct 的 method 的声明顺序排序
+func (gpm *GoProjectMeta) UncheckedErrors() []*GoDiagnostic {
+	diagnostics := make([]*GoDiagnostic, 0)
+	for _, packageMeta := range gpm.Packages() {
+		for _, gfm := range packageMeta.Funcs() {
+			diagnostics = append(diagnostics, gfm.UncheckedErrors()...)
+		}
+		for _, gsm := range packageMeta.Structs() {
+			for _, gmm := range gsm.Methods() {
+				diagnostics = append(diagnostics, gmm.UncheckedErrors()...)
+			}
+		}
+	}
+	return diagnostics
+}
@@ -24,15 +24,40 @@ type meta struct {
 
 	// 当前 meta 的 ast 节点所属的文件的绝对路径
 	path string
+
+	// 当前 meta 的 ast 节点所属的文件的 meta 数据，通过源码构造的 meta 为 nil
+	fileMeta *GoFileMeta
 }
 
 func newMeta(node ast.Node, path string) *meta {
 	return &meta{node: node, path: path}
 }
 
-// copyMeta 保持 path 不变的情况下构造新 ast 节点的 meta 数据
+// copyMeta 保持 path 和所属文件不变的情况下构造新 ast 节点的 meta 数据
 func (m *meta) copyMeta(node ast.Node) *meta {
-	return &meta{node: node, path: m.path}
+	return &meta{node: node, path: m.path, fileMeta: m.fileMeta}
+}
+
+// content 获取所属文件在提取时的内容，通过源码构造的 meta 读取磁盘上的文件
+func (m *meta) content() []byte {
+	if m.fileMeta != nil && m.fileMeta.content != nil {
+		return m.fileMeta.content
+	}
+	fileContent, err := os.ReadFile(m.path)
+	if err != nil {
+		return nil
+	}
+	return fileContent
+}
+
+// position 获取 ast 节点位置在所属文件中的行列号
+// - 使用提取时解析文件的 token.FileSet，不受文件之后的修改影响
+// - 通过源码构造的 meta 没有所属文件，只有文件路径
+func (m *meta) position(pos token.Pos) token.Position {
+	if m.fileMeta == nil || m.fileMeta.fileSet == nil || !pos.IsValid() {
+		return token.Position{Filename: m.path}
+	}
+	return m.fileMeta.fileSet.Position(pos)
 }
 
 // AST 获取当前 meta 的 ast 节点树
@@ -76,3 +101,11 @@ func (m *meta) Format() string {
 func (m *meta) AbsPath() string {
 	return m.path
 }
+
+// Range 获取当前 meta 的 ast 节点在所属的文件中的起止位置，没有 ast 节点时为零值
+func (m *meta) Range() (token.Position, token.Position) {
+	if m == nil || m.node == nil {
+		return token.Position{}, token.Position{}
+	}
+	return m.position(m.node.Pos()), m.position(m.node.End())
+}
@@ -1,8 +1,14 @@
 package extractor
 
 import (
+	"encoding/json"
 	"fmt"
 	"go/ast"
+	"go/doc/comment"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	stp "github.com/Mericusta/go-stp"
@@ -49,6 +55,23 @@ func TMapNotEqualPanic[T comparable](cm, vm map[string]T) {
 	}
 }
 
+// writeTestProject 在临时目录内写入测试项目的所有文件，返回项目的绝对路径
+// - files: key 为相对于项目根目录的文件路径，value 为文件内容
+func writeTestProject(t *testing.T, files map[string]string) string {
+	t.Helper()
+	projectPath := t.TempDir()
+	for filePath, content := range files {
+		filePath = filepath.Join(projectPath, filePath)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return projectPath
+}
+
 type compareGoProjectMeta struct {
 	absolutePath string
 	moduleName   string
@@ -1829,3 +1852,2920 @@ func TestExtractGoProjectMeta(t *testing.T) {
 		}
 	}
 }
+
+type compareGoTypeExpr struct {
+	kind     UnderlyingType
+	ident    string
+	from     string
+	length   string
+	chanDir  ast.ChanDir
+	typeArgs []*compareGoTypeExpr
+	key      *compareGoTypeExpr
+	elem     *compareGoTypeExpr
+	params   []*compareGoTypeExpr
+	returns  []*compareGoTypeExpr
+}
+
+func (cgte *compareGoTypeExpr) compare(gte *GoTypeExpr) {
+	if cgte == nil {
+		if gte != nil {
+			panic(fmt.Sprintf("unexpected type expr %v", gte.String()))
+		}
+		return
+	}
+	TNilMetaPanic("type expr", gte)
+	TNotEqualPanic(cgte.kind, gte.Kind())
+	TNotEqualPanic(cgte.ident, gte.Ident())
+	TNotEqualPanic(cgte.from, gte.From())
+	TNotEqualPanic(cgte.length, gte.Len())
+	TNotEqualPanic(cgte.chanDir, gte.ChanDir())
+	TSliceNotEqualPanic(cgte.typeArgs, gte.TypeArgs(), func(c *compareGoTypeExpr, v *GoTypeExpr) { c.compare(v) })
+	cgte.key.compare(gte.Key())
+	cgte.elem.compare(gte.Elem())
+	TSliceNotEqualPanic(cgte.params, gte.Params(), func(c *compareGoTypeExpr, v *GoVarMeta) { c.compare(v.TypeExpr()) })
+	TSliceNotEqualPanic(cgte.returns, gte.Returns(), func(c *compareGoTypeExpr, v *GoVarMeta) { c.compare(v.TypeExpr()) })
+}
+
+func TestGoTypeExpr(t *testing.T) {
+	var (
+		intExpr    = &compareGoTypeExpr{kind: UNDERLYING_TYPE_IDENT, ident: "int"}
+		stringExpr = &compareGoTypeExpr{kind: UNDERLYING_TYPE_IDENT, ident: "string"}
+	)
+	for typeExpression, cgte := range map[string]*compareGoTypeExpr{
+		`int`: intExpr,
+		`*module.ExampleStruct`: {
+			kind: UNDERLYING_TYPE_POINTER,
+			elem: &compareGoTypeExpr{kind: UNDERLYING_TYPE_IDENT, ident: "ExampleStruct", from: "module"},
+		},
+		`[]int`:  {kind: UNDERLYING_TYPE_SLICE, elem: intExpr},
+		`[4]int`: {kind: UNDERLYING_TYPE_ARRAY, length: "4", elem: intExpr},
+		`[N]int`: {kind: UNDERLYING_TYPE_ARRAY, length: "N", elem: intExpr},
+		`func(...int)`: {
+			kind:   UNDERLYING_TYPE_FUNC,
+			params: []*compareGoTypeExpr{{kind: UNDERLYING_TYPE_ELLIPSIS, elem: intExpr}},
+		},
+		`(int)`:      intExpr,
+		`<-chan int`: {kind: UNDERLYING_TYPE_CHAN, chanDir: ast.RECV, elem: intExpr},
+		`chan int`:   {kind: UNDERLYING_TYPE_CHAN, chanDir: ast.SEND | ast.RECV, elem: intExpr},
+		`map[string]*template.TemplateStruct[int]`: {
+			kind: UNDERLYING_TYPE_MAP,
+			key:  stringExpr,
+			elem: &compareGoTypeExpr{
+				kind: UNDERLYING_TYPE_POINTER,
+				elem: &compareGoTypeExpr{kind: UNDERLYING_TYPE_IDENT, ident: "TemplateStruct", from: "template", typeArgs: []*compareGoTypeExpr{intExpr}},
+			},
+		},
+		`TwoTypeTemplateStruct[int, string]`: {kind: UNDERLYING_TYPE_IDENT, ident: "TwoTypeTemplateStruct", typeArgs: []*compareGoTypeExpr{intExpr, stringExpr}},
+		`func(int, string) (int, error)`: {
+			kind:    UNDERLYING_TYPE_FUNC,
+			params:  []*compareGoTypeExpr{intExpr, stringExpr},
+			returns: []*compareGoTypeExpr{intExpr, {kind: UNDERLYING_TYPE_IDENT, ident: "error"}},
+		},
+		`struct{}`:    {kind: UNDERLYING_TYPE_STRUCT},
+		`interface{}`: {kind: UNDERLYING_TYPE_INTERFACE},
+	} {
+		gvm := MakeUpVarMeta("v", typeExpression)
+		TNilMetaPanic(typeExpression, gvm)
+		cgte.compare(gvm.TypeExpr())
+		TNotEqualPanic(cgte.kind == UNDERLYING_TYPE_POINTER, gvm.IsPointer())
+		TNotEqualPanic(cgte.kind == UNDERLYING_TYPE_INTERFACE, gvm.IsInterface())
+	}
+	for typeExpression, isInterface := range map[string]bool{
+		`any`:         true,
+		`error`:       true,
+		`*error`:      false,
+		`io.Reader`:   false,
+		`[]any`:       false,
+		`myAny`:       false,
+		`interface{}`: true,
+	} {
+		TNotEqualPanic(isInterface, MakeUpVarMeta("v", typeExpression).IsInterface())
+	}
+}
+
+func TestResolveGoTypeExpr(t *testing.T) {
+	goProjectMeta, err := ExtractGoProjectMeta(standardProjectRelPath, standardProjectIgnorePathMap)
+	if err != nil {
+		panic(err)
+	}
+
+	mainPackageMeta := goProjectMeta.SearchPackageMeta("main")
+	TNilMetaPanic("main", mainPackageMeta)
+	for varIdent, compare := range map[string]struct {
+		importPath      string
+		packageIdent    string
+		structIdent     string
+		interfaceIdent  string
+		typeArgBuiltins bool
+	}{
+		"globalVariableInt":        {},
+		"globalVariableStruct":     {importPath: "standardProject/pkg/module", packageIdent: "module", structIdent: "ExampleStruct"},
+		"globalVariableTStruct":    {importPath: "standardProject/pkg/template", packageIdent: "template", structIdent: "TemplateStruct", typeArgBuiltins: true},
+		"globalVariableInterface":  {importPath: "standardProject/pkg/interface", packageIdent: "pkgInterface", interfaceIdent: "ExampleInterface"},
+		"globalVariableTInterface": {importPath: "standardProject/pkg/interface", packageIdent: "pkgInterface", interfaceIdent: "ExampleTemplateInterface", typeArgBuiltins: true},
+	} {
+		gvm := mainPackageMeta.SearchVarMeta(varIdent)
+		TNilMetaPanic(varIdent, gvm)
+		// 指向 interface 的指针不是 interface
+		TNotEqualPanic(false, gvm.IsInterface())
+		named := gvm.TypeExpr().Named()
+		TNilMetaPanic(varIdent, named)
+		TNotEqualPanic(compare.importPath, named.ImportPath())
+		if len(compare.packageIdent) > 0 {
+			TNilMetaPanic(compare.packageIdent, named.PackageMeta())
+			TNotEqualPanic(compare.packageIdent, named.PackageMeta().Ident())
+		}
+		if len(compare.structIdent) > 0 {
+			TNilMetaPanic(compare.structIdent, named.StructMeta())
+			TNotEqualPanic(compare.structIdent, named.StructMeta().Ident())
+		}
+		if len(compare.interfaceIdent) > 0 {
+			TNilMetaPanic(compare.interfaceIdent, named.InterfaceMeta())
+			TNotEqualPanic(compare.interfaceIdent, named.InterfaceMeta().Ident())
+		}
+		for _, typeArg := range named.TypeArgs() {
+			TNotEqualPanic(compare.typeArgBuiltins, typeArg.IsBuiltin())
+		}
+	}
+
+	// 当前 package 内的类型
+	modulePackageMeta := goProjectMeta.SearchPackageMeta("standardProject/pkg/module")
+	TNilMetaPanic("standardProject/pkg/module", modulePackageMeta)
+	subMemberMeta := modulePackageMeta.SearchStructMeta("ExampleStruct").SearchMemberMeta("sub")
+	TNilMetaPanic("sub", subMemberMeta)
+	TNotEqualPanic("standardProject/pkg/module", subMemberMeta.TypeExpr().Named().ImportPath())
+	TNotEqualPanic(modulePackageMeta.SearchStructMeta("ExampleStruct"), subMemberMeta.TypeExpr().Named().StructMeta())
+
+	// 命名 interface 类型的 var
+	projectPath := writeTestProject(t, map[string]string{
+		"go.mod": "module named\n\ngo 1.22\n",
+		"api/api.go": `package api
+
+type Store interface{ Get() }
+`,
+		"shadow/shadow.go": `package shadow
+
+type error struct{}
+
+type any int
+
+type Holder struct {
+	err error
+	val any
+}
+`,
+		"main.go": `package main
+
+import "named/api"
+
+type Local interface{ Run() }
+
+type Service struct {
+	store   api.Store
+	local   Local
+	err     error
+	pointer *Local
+	reader  interface{ Read() }
+	name    string
+}
+`,
+	})
+	namedProjectMeta, err := ExtractGoProjectMeta(projectPath, nil)
+	if err != nil {
+		panic(err)
+	}
+	serviceMeta := namedProjectMeta.SearchPackageMeta("main").SearchStructMeta("Service")
+	for memberIdent, isInterface := range map[string]bool{
+		"store":   true,
+		"local":   true,
+		"err":     true,
+		"pointer": false,
+		"reader":  true,
+		"name":    false,
+	} {
+		TNotEqualPanic(isInterface, serviceMeta.SearchMemberMeta(memberIdent).IsInterface())
+	}
+
+	// package 内遮蔽了内置类型的同名类型
+	holderMeta := namedProjectMeta.SearchPackageMeta("named/shadow").SearchStructMeta("Holder")
+	for _, memberIdent := range []string{"err", "val"} {
+		TNotEqualPanic(false, holderMeta.SearchMemberMeta(memberIdent).TypeExpr().IsBuiltin())
+		TNotEqualPanic(false, holderMeta.SearchMemberMeta(memberIdent).IsInterface())
+	}
+}
+
+func TestExtractGoFuncMetaParams(t *testing.T) {
+	packagePath := writeTestProject(t, map[string]string{
+		"params.go": `package params
+
+func UnnamedFunc(int, string) (int, error) { return 0, nil }
+
+func GroupedFunc(a, b int, _ string, vs ...*int) (n int, err error) { return }
+`,
+	})
+
+	type compareParam struct {
+		ident      string
+		typeExpr   string
+		isVariadic bool
+	}
+	for funcIdent, compare := range map[string]struct {
+		params     []compareParam
+		returns    []compareParam
+		isVariadic bool
+	}{
+		"UnnamedFunc": {
+			params:  []compareParam{{"", "int", false}, {"", "string", false}},
+			returns: []compareParam{{"", "int", false}, {"", "error", false}},
+		},
+		"GroupedFunc": {
+			params:     []compareParam{{"a", "int", false}, {"b", "int", false}, {"_", "string", false}, {"vs", "...*int", true}},
+			returns:    []compareParam{{"n", "int", false}, {"err", "error", false}},
+			isVariadic: true,
+		},
+	} {
+		gfm, err := ExtractGoFuncMeta(packagePath, funcIdent)
+		if err != nil {
+			panic(err)
+		}
+		compareParams := func(c compareParam, v *GoVarMeta) {
+			TNotEqualPanic(c.ident, v.Ident())
+			TNotEqualPanic(c.typeExpr, v.TypeExpression())
+			TNotEqualPanic(c.isVariadic, v.IsVariadic())
+		}
+		TSliceNotEqualPanic(compare.params, gfm.Params(), compareParams)
+		TSliceNotEqualPanic(compare.returns, gfm.Returns(), compareParams)
+		TNotEqualPanic(compare.isVariadic, gfm.IsVariadic())
+	}
+}
+
+func TestExtractGoImportMeta(t *testing.T) {
+	packagePath := writeTestProject(t, map[string]string{
+		"imports.go": `package imports
+
+// fmt doc
+import "fmt"
+
+import (
+	// rand doc
+	random "math/rand"
+	_ "embed"
+	. "strings"
+	"github.com/Mericusta/go-stp/v2" // versioned
+	"os"
+)
+
+func F() {
+	fmt.Println(random.Int(), ToUpper(""), stp.X)
+}
+`,
+	})
+
+	gfm, err := ExtractGoFileMeta(filepath.Join(packagePath, "imports.go"))
+	if err != nil {
+		panic(err)
+	}
+
+	type compareImport struct {
+		alias, importPath, packageName, ident string
+		isBlank, isDot                        bool
+		doc                                   []string
+		comment                               string
+		line                                  int
+	}
+	TSliceNotEqualPanic([]compareImport{
+		{"", "fmt", "fmt", "fmt", false, false, []string{"// fmt doc"}, "", 4},
+		{"random", "math/rand", "rand", "random", false, false, []string{"// rand doc"}, "", 8},
+		{"_", "embed", "embed", "_", true, false, nil, "", 9},
+		{".", "strings", "strings", ".", false, true, nil, "", 10},
+		{"", "github.com/Mericusta/go-stp/v2", "stp", "stp", false, false, nil, "// versioned", 11},
+		{"", "os", "os", "os", false, false, nil, "", 12},
+	}, gfm.Imports(), func(c compareImport, v *GoImportMeta) {
+		TNotEqualPanic(c.alias, v.Alias())
+		TNotEqualPanic(c.importPath, v.ImportPath())
+		TNotEqualPanic(c.packageName, v.PackageName())
+		TNotEqualPanic(c.ident, v.Ident())
+		TNotEqualPanic(c.isBlank, v.IsBlank())
+		TNotEqualPanic(c.isDot, v.IsDot())
+		TSliceNotEqualPanic(c.doc, v.Doc(), func(c, v string) { TNotEqualPanic(c, v) })
+		TNotEqualPanic(c.comment, v.Comment())
+		TNotEqualPanic(c.line, v.Pos().Line)
+	})
+
+	TNotEqualPanic("math/rand", gfm.SearchImport("random").ImportPath())
+	TNotEqualPanic(true, gfm.SearchImport("rand") == nil)
+	TNotEqualPanic(true, gfm.SearchImport("_") == nil)
+	TSliceNotEqualPanic([]string{"os"}, gfm.UnusedImports(), func(c string, v *GoImportMeta) { TNotEqualPanic(c, v.ImportPath()) })
+
+	// 项目内的 import 解析为实际的 package 名称
+	goProjectMeta, err := ExtractGoProjectMeta(standardProjectRelPath, standardProjectIgnorePathMap)
+	if err != nil {
+		panic(err)
+	}
+	for _, gim := range goProjectMeta.SearchPackageMeta("main").SearchFileMeta("init.go").Imports() {
+		if gim.ImportPath() == "standardProject/pkg/interface" {
+			TNotEqualPanic("pkgInterface", gim.PackageName())
+			TNilMetaPanic(gim.ImportPath(), gim.PackageMeta())
+		}
+	}
+	TNotEqualPanic(2, len(goProjectMeta.SearchPackageMeta("main").ImportMetaMap()["standardProject/pkg/module"]))
+}
+
+func TestExtractGoMetaComment(t *testing.T) {
+	packagePath := writeTestProject(t, map[string]string{
+		"a.go": `package comments
+
+// DocStruct is documented, see [DocFunc] and [io.Reader]
+type DocStruct struct {
+	// Value doc
+	Value int // value comment
+}
+
+type (
+	// GroupedInterface doc
+	GroupedInterface interface {
+		// Method doc
+		Method() // method comment
+	}
+)
+
+// DocFunc doc
+//
+//go:noinline
+func DocFunc() {}
+
+// Method doc
+func (d *DocStruct) Method() {}
+
+// GlobalVar doc
+var GlobalVar int // var comment
+
+// const group doc
+const (
+	// A doc
+	A = iota // a comment
+	B
+)
+`,
+		"doc.go": `// Package comments is the package doc
+package comments
+`,
+	})
+
+	gpm, err := ExtractGoPackageMeta(packagePath, nil)
+	if err != nil {
+		panic(err)
+	}
+	gpm.ExtractAll()
+
+	type compareComment struct {
+		doc         []string
+		docText     string
+		comment     string
+		commentText string
+	}
+	compare := func(c compareComment, v interface {
+		Doc() []string
+		DocText() string
+		Comment() string
+		CommentText() string
+	}) {
+		TSliceNotEqualPanic(c.doc, v.Doc(), func(c, v string) { TNotEqualPanic(c, v) })
+		TNotEqualPanic(c.docText, v.DocText())
+		TNotEqualPanic(c.comment, v.Comment())
+		TNotEqualPanic(c.commentText, v.CommentText())
+	}
+
+	gsm := gpm.SearchStructMeta("DocStruct")
+	compare(compareComment{[]string{"// DocStruct is documented, see [DocFunc] and [io.Reader]"}, "DocStruct is documented, see [DocFunc] and [io.Reader]\n", "", ""}, gsm)
+	compare(compareComment{[]string{"// Value doc"}, "Value doc\n", "// value comment", "value comment\n"}, gsm.SearchMemberMeta("Value"))
+	compare(compareComment{[]string{"// Method doc"}, "Method doc\n", "", ""}, gsm.SearchMethodMeta("Method"))
+	gim := gpm.SearchInterfaceMeta("GroupedInterface")
+	compare(compareComment{[]string{"// GroupedInterface doc"}, "GroupedInterface doc\n", "", ""}, gim)
+	compare(compareComment{[]string{"// Method doc"}, "Method doc\n", "// method comment", "method comment\n"}, gim.SearchMethodMeta("Method"))
+	compare(compareComment{[]string{"// DocFunc doc", "//", "//go:noinline"}, "DocFunc doc\n", "", ""}, gpm.SearchFuncMeta("DocFunc"))
+	compare(compareComment{[]string{"// GlobalVar doc"}, "GlobalVar doc\n", "// var comment", "var comment\n"}, gpm.SearchVarMeta("GlobalVar"))
+	compare(compareComment{[]string{"// A doc"}, "A doc\n", "// a comment", "a comment\n"}, gpm.SearchConstMeta("A"))
+	compare(compareComment{nil, "", "", ""}, gpm.SearchConstMeta("B"))
+	compare(compareComment{[]string{"// Package comments is the package doc"}, "Package comments is the package doc\n", "", ""}, gpm.SearchFileMeta("doc.go"))
+	TNotEqualPanic("Package comments is the package doc\n", gpm.DocText())
+
+	type compareDocLink struct{ importPath, recv, name string }
+	TSliceNotEqualPanic([]compareDocLink{{"", "", "DocFunc"}, {"io", "", "Reader"}}, gsm.DocLinks(), func(c compareDocLink, v *comment.DocLink) {
+		TNotEqualPanic(c.importPath, v.ImportPath)
+		TNotEqualPanic(c.recv, v.Recv)
+		TNotEqualPanic(c.name, v.Name)
+	})
+}
+
+func TestExtractGoDirectiveMeta(t *testing.T) {
+	projectPath := writeTestProject(t, map[string]string{
+		"go.mod": "module directives\n\ngo 1.22\n",
+		"main.go": `//go:build linux && !race
+
+//go:generate echo "main first"
+package main
+
+import _ "unsafe"
+
+//go:noinline
+func main() {}
+
+//go:linkname localNow time.now
+func localNow() (int64, int32, int64)
+
+//go:generate echo main second
+`,
+		"pkg/pkg.go": `package pkg
+
+import "embed"
+
+//go:generate stringer -type=Kind
+type Kind int
+
+// assets 资源
+//
+//go:embed "static file.txt" data
+var assets embed.FS
+
+//export Exported
+func Exported() {}
+`,
+		"pkg/static file.txt": "static",
+		"pkg/data/a.json":     "{}",
+		"pkg/data/.hidden":    "hidden",
+		"pkg/data/_skip/b":    "skip",
+	})
+
+	goProjectMeta, err := ExtractGoProjectMeta(projectPath, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	// 文件内的所有指令
+	type compareDirective struct {
+		directiveType DirectiveType
+		tool, name    string
+		args          []string
+		line          int
+	}
+	compare := func(c compareDirective, v *GoDirectiveMeta) {
+		TNotEqualPanic(c.directiveType, v.DirectiveType())
+		TNotEqualPanic(c.tool, v.Tool())
+		TNotEqualPanic(c.name, v.Name())
+		TSliceNotEqualPanic(c.args, v.Args(), func(c, v string) { TNotEqualPanic(c, v) })
+		TNotEqualPanic(c.line, v.Pos().Line)
+	}
+	mainPackageMeta := goProjectMeta.SearchPackageMeta("main")
+	TSliceNotEqualPanic([]compareDirective{
+		{DIRECTIVE_TYPE_BUILD, "go", "build", []string{"linux", "&&", "!race"}, 1},
+		{DIRECTIVE_TYPE_GENERATE, "go", "generate", []string{"echo", "main first"}, 3},
+		{DIRECTIVE_TYPE_NOINLINE, "go", "noinline", []string{}, 8},
+		{DIRECTIVE_TYPE_LINKNAME, "go", "linkname", []string{"localNow", "time.now"}, 11},
+		{DIRECTIVE_TYPE_GENERATE, "go", "generate", []string{"echo", "main", "second"}, 14},
+	}, mainPackageMeta.SearchFileMeta("main.go").Directives(), compare)
+
+	// 声明上的指令
+	TSliceNotEqualPanic([]compareDirective{
+		{DIRECTIVE_TYPE_NOINLINE, "go", "noinline", []string{}, 8},
+	}, mainPackageMeta.SearchFuncMeta("main").Directives(), compare)
+	buildConstraint, err := mainPackageMeta.SearchFileMeta("main.go").Directives()[0].BuildConstraint()
+	if err != nil {
+		panic(err)
+	}
+	TNotEqualPanic(true, buildConstraint.Eval(func(tag string) bool { return tag == "linux" }))
+	TNotEqualPanic(false, buildConstraint.Eval(func(tag string) bool { return tag == "linux" || tag == "race" }))
+
+	// cgo 的 //export 指令没有 tool
+	TSliceNotEqualPanic([]compareDirective{
+		{DIRECTIVE_TYPE_OTHER, "", "export", []string{"Exported"}, 13},
+	}, goProjectMeta.SearchPackageMeta("directives/pkg").SearchFuncMeta("Exported").Directives(), compare)
+
+	// go:embed 解析到磁盘上的文件
+	assetsDirectives := goProjectMeta.SearchPackageMeta("directives/pkg").SearchVarMeta("assets").Directives()
+	TNotEqualPanic(1, len(assetsDirectives))
+	embedFiles, err := assetsDirectives[0].EmbedFiles()
+	if err != nil {
+		panic(err)
+	}
+	TSliceNotEqualPanic([]string{"pkg/data/a.json", "pkg/static file.txt"}, embedFiles, func(c, v string) {
+		TNotEqualPanic(filepath.Join(projectPath, c), v)
+	})
+
+	// 项目内所有 go:generate 按照执行顺序
+	TSliceNotEqualPanic([]string{"echo main first", "echo main second", "stringer -type=Kind"},
+		goProjectMeta.SearchDirectiveMetas(DIRECTIVE_TYPE_GENERATE), func(c string, v *GoDirectiveMeta) {
+			TNotEqualPanic(c, strings.Join(v.Args(), " "))
+		})
+}
+
+// prefixAnnotationParser 测试用的自定义注解解析器: +name
+type prefixAnnotationParser struct{}
+
+func (prefixAnnotationParser) Parse(text string) ([]*Annotation, error) {
+	if index := strings.Index(text, "+"); index >= 0 {
+		return []*Annotation{{Name: strings.TrimSpace(text[index+1:]), Offset: index}}, nil
+	}
+	return nil, nil
+}
+
+func TestExtractGoAnnotationMeta(t *testing.T) {
+	projectPath := writeTestProject(t, map[string]string{
+		"go.mod": "module annotations\n\ngo 1.22\n",
+		"service/service.go": `package service
+
+// Service 服务 @rpc(id=12, timeout=3s)
+// mail me at a@b.com
+type Service struct {
+	// @column(name="user id", primary)
+	ID int
+	// +generated
+	Name string
+	// @column(name="x"
+	X, Y int
+}
+
+// Handle 处理 @rpc(id=13)
+func (s *Service) Handle() {}
+
+// @rpc(id=14
+func Broken() {}
+
+// @deprecated
+func Old() {}
+`,
+	})
+
+	goProjectMeta, err := ExtractGoProjectMeta(projectPath, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	type compareArg struct {
+		key, value string
+		line, col  int
+	}
+	gsm := goProjectMeta.SearchPackageMeta("annotations/service").SearchStructMeta("Service")
+	annotationMetas, annotationErrors := gsm.Annotations()
+	TNotEqualPanic(0, len(annotationErrors))
+	TNotEqualPanic(1, len(annotationMetas))
+	TNotEqualPanic("rpc", annotationMetas[0].Name())
+	TNotEqualPanic(3, annotationMetas[0].Pos().Line)
+	TNotEqualPanic(19, annotationMetas[0].Pos().Column) // 列号按照字节计算
+	TSliceNotEqualPanic([]compareArg{{"id", "12", 3, 24}, {"timeout", "3s", 3, 31}}, annotationMetas[0].Args(), func(c compareArg, v *GoAnnotationArgMeta) {
+		TNotEqualPanic(c.key, v.Key())
+		TNotEqualPanic(c.value, v.Value())
+		TNotEqualPanic(c.line, v.Pos().Line)
+		TNotEqualPanic(c.col, v.Pos().Column)
+	})
+
+	annotationMetas, _ = gsm.SearchMemberMeta("ID").Annotations()
+	TNotEqualPanic(1, len(annotationMetas))
+	TSliceNotEqualPanic([]compareArg{{"name", "user id", 6, 13}, {"", "primary", 6, 29}}, annotationMetas[0].Args(), func(c compareArg, v *GoAnnotationArgMeta) {
+		TNotEqualPanic(c.key, v.Key())
+		TNotEqualPanic(c.value, v.Value())
+		TNotEqualPanic(c.col, v.Pos().Column)
+	})
+
+	// 自定义注解解析器
+	annotationMetas, _ = gsm.SearchMemberMeta("Name").Annotations(prefixAnnotationParser{})
+	TNotEqualPanic(1, len(annotationMetas))
+	TNotEqualPanic("generated", annotationMetas[0].Name())
+
+	// 项目内所有 @rpc 注解
+	annotationMetas, annotationErrors = goProjectMeta.FindAnnotated("rpc")
+	TSliceNotEqualPanic([]string{"12", "13", "14"}, annotationMetas, func(c string, v *GoAnnotationMeta) {
+		value, _ := v.Arg("id")
+		TNotEqualPanic(c, value)
+	})
+	_, isMethod := annotationMetas[1].Owner().(*GoMethodMeta)
+	TNotEqualPanic(true, isMethod)
+	// 同一行声明的多个 member 只解析一次
+	TSliceNotEqualPanic([]compareArg{{"", "", 10, 12}, {"", "", 17, 8}}, annotationErrors, func(c compareArg, v *GoAnnotationError) {
+		TNotEqualPanic(c.line, v.Pos().Line)
+		TNotEqualPanic(c.col, v.Pos().Column)
+	})
+}
+
+func TestGoStructTag(t *testing.T) {
+	gst, err := ParseGoStructTag(`json:"name,omitempty,string" db:"name"`)
+	if err != nil {
+		panic(err)
+	}
+	TSliceNotEqualPanic([]string{"json", "db"}, gst.Keys(), func(c, v string) { TNotEqualPanic(c, v) })
+	TNotEqualPanic("name", gst.Entry("json").Name())
+	TSliceNotEqualPanic([]string{"omitempty", "string"}, gst.Entry("json").Options(), func(c, v string) { TNotEqualPanic(c, v) })
+	TNotEqualPanic(true, gst.Entry("json").HasOption("omitempty"))
+	TNotEqualPanic(true, gst.Rename("db", "sql"))
+	TNotEqualPanic(false, gst.Rename("json", "sql"))
+	gst.Set("yaml", "name")
+	TNotEqualPanic(true, gst.Delete("json"))
+	TNotEqualPanic("`sql:\"name\" yaml:\"name\"`", gst.Literal())
+
+	for tag, compareErr := range map[string]error{
+		`json:"a"  db:"b"`: nil,
+		`json:"a"db:"b"`:   errTagSpace,
+		`json:a`:           errTagValueSyntax,
+		`json`:             errTagSyntax,
+		`:"a"`:             errTagKeySyntax,
+		`json:"a`:          errTagValueSyntax,
+	} {
+		_, err := ParseGoStructTag(tag)
+		TNotEqualPanic(compareErr, err)
+	}
+}
+
+func TestEditGoStructTag(t *testing.T) {
+	packagePath := writeTestProject(t, map[string]string{
+		"dto.go": "package dto\n\ntype UserDTO struct {\n\tID       int    `json:\"id\" db:\"id\"`\n\tUserName string `json:\"user_name,omitempty\"`\n\tAlias    string `json:\"user_name\"`\n\tBad      string `json:\"bad, omitempty\"`\n\tAge, Sex int\n}\n",
+		"wrapper.go": "package dto\n\ntype Wrapper struct {\n\tBase   `json:\",omitempty\"`\n\tMeta   string `json:\"Base\"`\n\t*Extra `json:\"id\"`\n\tID     int    `json:\"id\"`\n}\n",
+	})
+	filePath := filepath.Join(packagePath, "dto.go")
+	gsm, err := ExtractGoStructMeta(packagePath, "UserDTO")
+	if err != nil {
+		panic(err)
+	}
+
+	TNotEqualPanic("id", gsm.SearchMemberMeta("ID").Tag().Entry("db").Value())
+	TNotEqualPanic(true, gsm.SearchMemberMeta("Age").Tag() == nil)
+
+	// go vet 风格的检查
+	TSliceNotEqualPanic([]string{
+		"6:18: struct field Alias repeats json tag \"user_name\" also at " + filePath + ":5:2",
+		"7:18: struct field tag `json:\"bad, omitempty\"` not compatible with reflect.StructTag.Get: suspicious space in struct tag value",
+	}, gsm.ValidateTags(), func(c string, v *GoDiagnostic) {
+		TNotEqualPanic(filePath+":"+c, v.String())
+	})
+
+	// 匿名成员没有指定名称时使用类型标识
+	wrapperMeta, err := ExtractGoStructMeta(packagePath, "Wrapper")
+	if err != nil {
+		panic(err)
+	}
+	wrapperPath := filepath.Join(packagePath, "wrapper.go")
+	TSliceNotEqualPanic([]string{
+		"5:16: struct field Meta repeats json tag \"Base\" also at " + wrapperPath + ":4:2",
+		"7:16: struct field ID repeats json tag \"id\" also at " + wrapperPath + ":6:2",
+	}, wrapperMeta.ValidateTags(), func(c string, v *GoDiagnostic) {
+		TNotEqualPanic(wrapperPath+":"+c, v.String())
+	})
+
+	// 重命名 db -> sql，为所有 member 添加 yaml
+	edits, err := gsm.RenameTagKey("db", "sql")
+	if err != nil {
+		panic(err)
+	}
+	TNotEqualPanic(1, len(edits))
+	yamlEdits, err := gsm.AddTagKey("yaml", func(member *GoVarMeta) string { return strings.ToLower(member.Ident()) })
+	if err != nil {
+		panic(err)
+	}
+	TNotEqualPanic(5, len(yamlEdits))
+	// 同一个 tag 的两次修改重叠
+	TNotEqualPanic(true, ApplyTextEdits(append(edits, yamlEdits...)) != nil)
+	if err = ApplyTextEdits(edits); err != nil {
+		panic(err)
+	}
+	// 文件已被修改，旧的修改无法应用
+	TNotEqualPanic(true, ApplyTextEdits(yamlEdits) != nil)
+	// 文件已被修改，基于提取时的内容构造的新修改也无法应用
+	staleEdits, err := gsm.RemoveTagKey("json")
+	if err != nil {
+		panic(err)
+	}
+	TNotEqualPanic(true, ApplyTextEdits(staleEdits) != nil)
+
+	gsm, err = ExtractGoStructMeta(packagePath, "UserDTO")
+	if err != nil {
+		panic(err)
+	}
+	yamlEdits, err = gsm.AddTagKey("yaml", func(member *GoVarMeta) string { return strings.ToLower(member.Ident()) })
+	if err != nil {
+		panic(err)
+	}
+	if err = ApplyTextEdits(yamlEdits); err != nil {
+		panic(err)
+	}
+	gsm, err = ExtractGoStructMeta(packagePath, "UserDTO")
+	if err != nil {
+		panic(err)
+	}
+	removeEdits, err := gsm.RemoveTagKey("json")
+	if err != nil {
+		panic(err)
+	}
+	if err = ApplyTextEdits(removeEdits); err != nil {
+		panic(err)
+	}
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		panic(err)
+	}
+	TNotEqualPanic("package dto\n\ntype UserDTO struct {\n\tID       int    `sql:\"id\" yaml:\"id\"`\n\tUserName string `yaml:\"username\"`\n\tAlias    string `yaml:\"alias\"`\n\tBad      string `yaml:\"bad\"`\n\tAge, Sex int    `yaml:\"age\"`\n}\n", string(content))
+}
+
+func TestExtractGoCallMeta(t *testing.T) {
+	projectPath := writeTestProject(t, map[string]string{
+		"go.mod": "module calls\n\ngo 1.22\n",
+		"util/util.go": `package util
+
+type ID int
+
+func Helper(s string, v ...int) {}
+`,
+		"service/service.go": `package service
+
+import (
+	"strings"
+
+	"calls/util"
+)
+
+type Conn struct{}
+
+func (c *Conn) Write(b []byte) (int, error) { return len(b), nil }
+
+type Service struct {
+	conn    *Conn
+	handler func(int)
+}
+
+var defaultConn = &Conn{}
+
+func newConn() *Conn { return &Conn{} }
+
+func (s *Service) Close() {}
+
+func (s *Service) Handle(name string, args ...int) {
+	s.conn.Write([]byte(name))
+	s.handler(len(args))
+	c := newConn()
+	c.Write(nil)
+	defaultConn.Write(nil)
+	util.Helper(strings.ToUpper(name), args...)
+	_ = util.ID(1)
+	fn := func(v int) { s.handler(v) }
+	fn(1)
+	newConn().Write(nil)
+	s.Close()
+}
+
+func Run(s *Service) { s.Close() }
+`,
+	})
+
+	goProjectMeta, err := ExtractGoProjectMeta(projectPath, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	type compareCall struct {
+		callType     CallType
+		receiverType CallReceiverType
+		from, callee string
+		chain        []string
+		importPath   string
+		args         int
+	}
+	goPackageMeta := goProjectMeta.SearchPackageMeta("calls/service")
+	handleMeta := goPackageMeta.SearchStructMeta("Service").SearchMethodMeta("Handle")
+	TSliceNotEqualPanic([]compareCall{
+		{CALL_TYPE_METHOD, CALL_RECEIVER_FIELD, "s.conn", "Write", []string{"s", "conn"}, "", 1},
+		{CALL_TYPE_CONVERSION, 0, "", "[]byte", nil, "", 1},
+		{CALL_TYPE_CLOSURE, CALL_RECEIVER_RECV, "s", "handler", []string{"s"}, "", 1},
+		{CALL_TYPE_BUILTIN, 0, "", "len", nil, "", 1},
+		{CALL_TYPE_FUNC, 0, "", "newConn", nil, "calls/service", 0},
+		{CALL_TYPE_METHOD, CALL_RECEIVER_LOCAL, "c", "Write", []string{"c"}, "", 1},
+		{CALL_TYPE_METHOD, CALL_RECEIVER_PACKAGE_VAR, "defaultConn", "Write", []string{"defaultConn"}, "", 1},
+		{CALL_TYPE_FUNC, 0, "util", "Helper", []string{"util"}, "calls/util", 2},
+		{CALL_TYPE_FUNC, 0, "strings", "ToUpper", []string{"strings"}, "strings", 1},
+		{CALL_TYPE_CONVERSION, 0, "util", "ID", []string{"util"}, "calls/util", 1},
+		{CALL_TYPE_CLOSURE, CALL_RECEIVER_RECV, "s", "handler", []string{"s"}, "", 1},
+		{CALL_TYPE_CLOSURE, 0, "", "fn", nil, "", 1},
+		{CALL_TYPE_METHOD, CALL_RECEIVER_CALL, "newConn()", "Write", []string{"newConn()"}, "", 1},
+		{CALL_TYPE_FUNC, 0, "", "newConn", nil, "calls/service", 0},
+		{CALL_TYPE_METHOD, CALL_RECEIVER_RECV, "s", "Close", []string{"s"}, "", 0},
+	}, handleMeta.Calls(), func(c compareCall, v *GoCallMeta) {
+		TNotEqualPanic(c.callType, v.CallType())
+		TNotEqualPanic(c.receiverType, v.ReceiverType())
+		TNotEqualPanic(c.from, v.From())
+		TNotEqualPanic(c.callee, v.Callee())
+		TSliceNotEqualPanic(c.chain, v.Chain(), func(c, v string) { TNotEqualPanic(c, v) })
+		TNotEqualPanic(c.importPath, v.ImportPath())
+		TNotEqualPanic(c.args, len(v.Args()))
+	})
+
+	helperCalls := handleMeta.SearchCallMeta("util.Helper")
+	TNotEqualPanic(1, len(helperCalls))
+	TNotEqualPanic(30, helperCalls[0].Pos().Line)
+	TNotEqualPanic(2, helperCalls[0].Pos().Column)
+	TNotEqualPanic("strings.ToUpper(name)", helperCalls[0].Args()[0].Expression())
+	TNotEqualPanic(false, helperCalls[0].Args()[0].IsEllipsis())
+	TNotEqualPanic(true, helperCalls[0].Args()[1].IsEllipsis())
+	TNotEqualPanic(2, len(handleMeta.SearchCallMeta("newConn")))
+
+	runCalls := goPackageMeta.SearchFuncMeta("Run").Calls()
+	TNotEqualPanic(1, len(runCalls))
+	TNotEqualPanic(CallReceiverType(CALL_RECEIVER_PARAM), runCalls[0].ReceiverType())
+}
+
+func TestGoProjectCallGraph(t *testing.T) {
+	projectPath := writeTestProject(t, map[string]string{
+		"go.mod": "module graph\n\ngo 1.22\n",
+		"main.go": `package main
+
+import "graph/svc"
+
+func init() { setup() }
+
+func setup() {}
+
+func unused() {}
+
+func run(h svc.Handler) { h.Handle() }
+
+func main() {
+	s := svc.New()
+	run(s)
+}
+`,
+		"svc/svc.go": `package svc
+
+type Handler interface {
+	Handle()
+}
+
+type Base struct{}
+
+func (b *Base) Log() {}
+
+type Store struct{}
+
+func (s *Store) Save() {}
+
+type Service struct {
+	Base
+	store *Store
+}
+
+func New() *Service { return &Service{store: &Store{}} }
+
+func (s *Service) Handle() {
+	s.Log()
+	s.store.Save()
+}
+
+type Other struct{}
+
+func (o Other) Handle() {}
+`,
+		"svc/svc_test.go": `package svc
+
+import "testing"
+
+func TestHandle(t *testing.T) { New().Handle() }
+`,
+	})
+
+	goProjectMeta, err := ExtractGoProjectMeta(projectPath, nil)
+	if err != nil {
+		panic(err)
+	}
+	callGraph := goProjectMeta.CallGraph()
+
+	compareIDs := func(ids []string, nodes []*CallGraphNode) {
+		TSliceNotEqualPanic(ids, nodes, func(id string, node *CallGraphNode) { TNotEqualPanic(id, node.ID()) })
+	}
+	compareIDs([]string{
+		"graph/svc.(*Base).Log",
+		"graph/svc.(*Service).Handle",
+		"graph/svc.(*Store).Save",
+		"graph/svc.New",
+		"graph/svc.Other.Handle",
+		"graph/svc.TestHandle",
+		"main.init",
+		"main.main",
+		"main.run",
+		"main.setup",
+		"main.unused",
+	}, callGraph.Nodes())
+
+	compareIDs([]string{"graph/svc.New", "main.run"}, callGraph.SearchNode("main.main").Callees())
+	compareIDs([]string{"graph/svc.(*Service).Handle", "graph/svc.Other.Handle"}, callGraph.SearchNode("main.run").Callees())
+	for _, edge := range callGraph.SearchNode("main.run").Out() {
+		TNotEqualPanic(true, edge.IsDynamic())
+		TNotEqualPanic(11, edge.CallMeta().Pos().Line)
+	}
+	compareIDs([]string{"graph/svc.(*Base).Log", "graph/svc.(*Store).Save"}, callGraph.SearchNode("graph/svc.(*Service).Handle").Callees())
+	compareIDs([]string{"graph/svc.TestHandle", "main.run"}, callGraph.SearchNode("graph/svc.(*Service).Handle").Callers())
+	compareIDs([]string{"graph/svc.TestHandle", "main.init", "main.main"}, callGraph.Roots())
+	TNotEqualPanic(10, len(callGraph.Reachable()))
+	compareIDs([]string{"main.init", "main.setup"}, callGraph.Reachable(callGraph.SearchNode("main.init")))
+	TNotEqualPanic(0, len(callGraph.SearchNode("main.unused").Callers()))
+
+	dot := callGraph.DOT()
+	TNotEqualPanic(true, strings.HasPrefix(dot, "digraph callgraph {\n"))
+	TNotEqualPanic(true, strings.Contains(dot, "\t\"main.run\" -> \"graph/svc.(*Service).Handle\" [style=dashed];\n"))
+	TNotEqualPanic(true, strings.Contains(dot, "\t\"main.init\" -> \"main.setup\";\n"))
+
+	content, err := callGraph.JSON(projectPath)
+	if err != nil {
+		panic(err)
+	}
+	output := struct {
+		Nodes []struct {
+			ID, Receiver, File string
+			Line               int
+		}
+		Edges []struct {
+			Caller, Callee, File string
+			Dynamic              bool
+			Line, Column         int
+		}
+	}{}
+	if err = json.Unmarshal(content, &output); err != nil {
+		panic(err)
+	}
+	TNotEqualPanic(11, len(output.Nodes))
+	TNotEqualPanic("*Service", output.Nodes[1].Receiver)
+	TNotEqualPanic("svc/svc.go", output.Nodes[1].File)
+	TNotEqualPanic(22, output.Nodes[1].Line)
+	TNotEqualPanic(9, len(output.Edges))
+
+	// 仅由嵌入的 interface 组成的 interface
+	projectPath = writeTestProject(t, map[string]string{
+		"go.mod": "module embed\n\ngo 1.22\n",
+		"svc/svc.go": `package svc
+
+type Handler interface{ Handle() }
+
+type Closer interface{ Close() }
+
+type HandleCloser interface {
+	Handler
+	Closer
+}
+
+type Failure interface {
+	error
+	Code() int
+}
+
+type Conn struct{}
+
+func (c *Conn) Handle() {}
+
+func (c *Conn) Close() {}
+
+type Half struct{}
+
+func (h *Half) Handle() {}
+
+func (h *Half) Code() int { return 0 }
+
+type Fault struct{}
+
+func (f Fault) Error() string { return "" }
+
+func (f Fault) Code() int { return 1 }
+
+func Serve(hc HandleCloser) { hc.Handle() }
+
+func Report(f Failure) { f.Code() }
+`,
+	})
+	goProjectMeta, err = ExtractGoProjectMeta(projectPath, nil)
+	if err != nil {
+		panic(err)
+	}
+	callGraph = goProjectMeta.CallGraph()
+	compareIDs([]string{"embed/svc.(*Conn).Handle"}, callGraph.SearchNode("embed/svc.Serve").Callees())
+	compareIDs([]string{"embed/svc.Fault.Code"}, callGraph.SearchNode("embed/svc.Report").Callees())
+}
+
+func TestExtractGoAssignmentMeta(t *testing.T) {
+	projectPath := writeTestProject(t, map[string]string{
+		"go.mod": "module locals\n\ngo 1.22\n",
+		"model/model.go": `package model
+
+type User struct {
+	Name string
+	Tags []string
+}
+
+func Load() (*User, error) { return &User{}, nil }
+`,
+		"logic/logic.go": `package logic
+
+import "locals/model"
+
+func Process(items []string, m map[string]int) int {
+	total := 0
+	u, err := model.Load()
+	if err != nil {
+		return 0
+	}
+	var name string = u.Name
+	for i, item := range items {
+		total += i
+		name = item
+	}
+	for k, v := range m {
+		_ = k
+		total += v
+	}
+	tags := u.Tags
+	var x any = name
+	switch t := x.(type) {
+	case string:
+		_ = t
+	case int, bool:
+		_ = t
+	}
+	if total := len(tags); total > 0 {
+		return total
+	}
+	total++
+	return total
+}
+`,
+	})
+
+	goProjectMeta, err := ExtractGoProjectMeta(projectPath, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	type compareAssignment struct {
+		ident          string
+		assignmentType AssignmentType
+		typeExpression string
+		inferred       bool
+	}
+	processMeta := goProjectMeta.SearchPackageMeta("locals/logic").SearchFuncMeta("Process")
+	TSliceNotEqualPanic([]compareAssignment{
+		{"total", ASSIGNMENT_TYPE_DEFINE, "int", true},
+		{"u", ASSIGNMENT_TYPE_DEFINE, "*model.User", true},
+		{"err", ASSIGNMENT_TYPE_DEFINE, "error", true},
+		{"name", ASSIGNMENT_TYPE_VAR, "string", false},
+		{"i", ASSIGNMENT_TYPE_RANGE, "int", true},
+		{"item", ASSIGNMENT_TYPE_RANGE, "string", true},
+		{"k", ASSIGNMENT_TYPE_RANGE, "string", true},
+		{"v", ASSIGNMENT_TYPE_RANGE, "int", true},
+		{"tags", ASSIGNMENT_TYPE_DEFINE, "[]string", true},
+		{"x", ASSIGNMENT_TYPE_VAR, "any", false},
+		{"t", ASSIGNMENT_TYPE_TYPE_SWITCH, "string", false},
+		{"t", ASSIGNMENT_TYPE_TYPE_SWITCH, "any", true},
+		{"total", ASSIGNMENT_TYPE_DEFINE, "int", true},
+	}, processMeta.Assignments(), func(c compareAssignment, v *GoAssignmentMeta) {
+		TNotEqualPanic(c.ident, v.Ident())
+		TNotEqualPanic(c.assignmentType, v.AssignmentType())
+		TNotEqualPanic(c.typeExpression, v.TypeExpression())
+		TNotEqualPanic(c.inferred, v.IsInferred())
+	})
+
+	lines := func(positions []token.Position) []int {
+		ls := make([]int, 0, len(positions))
+		for _, position := range positions {
+			ls = append(ls, position.Line)
+		}
+		return ls
+	}
+	compareLines := func(c, v []int) { TSliceNotEqualPanic(c, v, func(c, v int) { TNotEqualPanic(c, v) }) }
+
+	totalMetas := processMeta.SearchAssignmentMeta("total")
+	TNotEqualPanic(2, len(totalMetas))
+	TNotEqualPanic(6, totalMetas[0].Pos().Line)
+	TNotEqualPanic(6, totalMetas[0].ScopePos().Line)
+	TNotEqualPanic(12, totalMetas[0].ScopePos().Column)
+	TNotEqualPanic(33, totalMetas[0].ScopeEnd().Line)
+	compareLines([]int{13, 18, 31, 32}, lines(totalMetas[0].Reads()))
+	compareLines([]int{13, 18, 31}, lines(totalMetas[0].Writes()))
+	compareLines([]int{28, 29}, lines(totalMetas[1].Reads()))
+	TNotEqualPanic(30, totalMetas[1].ScopeEnd().Line)
+
+	nameMeta := processMeta.SearchAssignmentMeta("name")[0]
+	compareLines([]int{14}, lines(nameMeta.Writes()))
+	compareLines([]int{21}, lines(nameMeta.Reads()))
+
+	tMeta := processMeta.SearchAssignmentMeta("t")[0]
+	TNotEqualPanic(23, tMeta.ScopePos().Line)
+	TNotEqualPanic(14, tMeta.ScopePos().Column)
+	compareLines([]int{24}, lines(tMeta.Reads()))
+}
+
+func TestGoArgMetaHead(t *testing.T) {
+	projectPath := writeTestProject(t, map[string]string{
+		"go.mod": "module origins\n\ngo 1.22\n",
+		"svc/svc.go": `package svc
+
+import (
+	"context"
+	"strings"
+)
+
+var defaultName = "svc"
+
+type Server struct {
+	ctx  context.Context
+	name string
+}
+
+func newName() string { return defaultName }
+
+func handle(ctx context.Context, name string, n int) {}
+
+func (s *Server) Serve(ctx context.Context, req string) {
+	local := strings.TrimSpace(req)
+	handle(ctx, local, len(req))
+	handle(s.ctx, defaultName, 1)
+	handle(context.Background(), newName(), int(int64(2)))
+	handle((ctx), strings.ToUpper(req), n())
+}
+
+func (s *Server) Rebind(ctx context.Context) {
+	ctx = context.WithoutCancel(ctx)
+	handle(ctx, s.name, 0)
+}
+
+func (s *Server) Release(ctx context.Context) {
+	handle(ctx, s.name, 0)
+	ctx = nil
+	_ = ctx
+}
+
+func n() int { return 0 }
+`,
+	})
+
+	goProjectMeta, err := ExtractGoProjectMeta(projectPath, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	type compareOrigin struct {
+		originType  ArgOriginType
+		ident       string
+		importPath  string
+		passthrough bool
+	}
+	compareOrigins := func(c []compareOrigin, gcm *GoCallMeta) {
+		TSliceNotEqualPanic(c, gcm.ArgOrigins(), func(c compareOrigin, v *GoArgOriginMeta) {
+			TNotEqualPanic(c.originType, v.OriginType())
+			TNotEqualPanic(c.ident, v.Ident())
+			TNotEqualPanic(c.importPath, v.ImportPath())
+			TNotEqualPanic(c.passthrough, v.IsPassthrough())
+		})
+	}
+
+	serverMeta := goProjectMeta.SearchPackageMeta("origins/svc").SearchStructMeta("Server")
+	handleCalls := serverMeta.SearchMethodMeta("Serve").SearchCallMeta("handle")
+	TNotEqualPanic(4, len(handleCalls))
+	compareOrigins([]compareOrigin{
+		{ARG_ORIGIN_PARAM, "ctx", "", true},
+		{ARG_ORIGIN_LOCAL, "local", "", false},
+		{ARG_ORIGIN_FUNC, "len", "", false},
+	}, handleCalls[0])
+	compareOrigins([]compareOrigin{
+		{ARG_ORIGIN_RECEIVER, "s", "", false},
+		{ARG_ORIGIN_PACKAGE_VAR, "defaultName", "", false},
+		{ARG_ORIGIN_LITERAL, "", "", false},
+	}, handleCalls[1])
+	compareOrigins([]compareOrigin{
+		{ARG_ORIGIN_PACKAGE, "context", "context", false},
+		{ARG_ORIGIN_FUNC, "newName", "", false},
+		{ARG_ORIGIN_LITERAL, "", "", false},
+	}, handleCalls[2])
+	compareOrigins([]compareOrigin{
+		{ARG_ORIGIN_PARAM, "ctx", "", true},
+		{ARG_ORIGIN_PACKAGE, "strings", "strings", false},
+		{ARG_ORIGIN_FUNC, "n", "", false},
+	}, handleCalls[3])
+	TSliceNotEqualPanic([]string{"s", "ctx"}, handleCalls[1].Args()[0].Chain(), func(c, v string) { TNotEqualPanic(c, v) })
+	TNotEqualPanic(19, handleCalls[0].ArgOrigins()[0].DeclPos().Line)
+	TNotEqualPanic(20, handleCalls[0].ArgOrigins()[1].DeclPos().Line)
+
+	rebindCalls := serverMeta.SearchMethodMeta("Rebind").SearchCallMeta("handle")
+	TNotEqualPanic(1, len(rebindCalls))
+	compareOrigins([]compareOrigin{
+		{ARG_ORIGIN_PARAM, "ctx", "", false},
+		{ARG_ORIGIN_RECEIVER, "s", "", false},
+		{ARG_ORIGIN_LITERAL, "", "", false},
+	}, rebindCalls[0])
+
+	// 调用之后的重新赋值不影响原样传递
+	compareOrigins([]compareOrigin{
+		{ARG_ORIGIN_PARAM, "ctx", "", true},
+		{ARG_ORIGIN_RECEIVER, "s", "", false},
+		{ARG_ORIGIN_LITERAL, "", "", false},
+	}, serverMeta.SearchMethodMeta("Release").SearchCallMeta("handle")[0])
+}
+
+func TestGoFuncMetaCFG(t *testing.T) {
+	projectPath := writeTestProject(t, map[string]string{
+		"go.mod": "module cfgs\n\ngo 1.22\n",
+		"logic/logic.go": `package logic
+
+func Simple() int { return 1 }
+
+func Branchy(xs []int, m map[string]int) (n int) {
+	for _, x := range xs {
+		if x > 0 && x < 10 {
+			n++
+		} else if x < 0 {
+			continue
+		} else {
+			break
+		}
+	}
+	switch len(m) {
+	case 0:
+		return 0
+	case 1, 2:
+		n++
+	}
+	return n
+}
+
+func Nested(grid [][]int) int {
+outer:
+	for i := 0; i < len(grid); i++ {
+		for _, v := range grid[i] {
+			if v < 0 {
+				continue outer
+			}
+			func() {
+				if v == 0 {
+					panic("zero")
+				}
+			}()
+		}
+	}
+	return Nested(nil)
+}
+
+type Worker struct{}
+
+func (w *Worker) Run(ch chan int, done chan struct{}) {
+	defer close(done)
+	for {
+		select {
+		case v := <-ch:
+			if v == 0 {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+func Check(a, b, c bool) {
+	if a && b || c {
+		return
+	}
+	println(a)
+}
+`,
+	})
+
+	goProjectMeta, err := ExtractGoProjectMeta(projectPath, nil)
+	if err != nil {
+		panic(err)
+	}
+	goPackageMeta := goProjectMeta.SearchPackageMeta("cfgs/logic")
+
+	simpleCFG := goPackageMeta.SearchFuncMeta("Simple").CFG()
+	TSliceNotEqualPanic([]CFGBlockType{CFG_BLOCK_ENTRY, CFG_BLOCK_EXIT}, simpleCFG.Reachable(), func(c CFGBlockType, v *GoCFGBlock) {
+		TNotEqualPanic(c, v.BlockType())
+	})
+	TNotEqualPanic(simpleCFG.Exit(), simpleCFG.Entry().Succs()[0])
+	TNotEqualPanic(1, simpleCFG.ReturnCount())
+
+	runCFG := goPackageMeta.SearchStructMeta("Worker").SearchMethodMeta("Run").CFG()
+	TNotEqualPanic(1, len(runCFG.Defers()))
+	TNotEqualPanic(2, runCFG.ReturnCount())
+
+	// 显式 return 以及执行到末尾的隐式 return
+	checkCFG := goPackageMeta.SearchFuncMeta("Check").CFG()
+	TNotEqualPanic(1, len(checkCFG.Returns()))
+	TNotEqualPanic(2, checkCFG.ReturnCount())
+	TNotEqualPanic(4, checkCFG.CyclomaticComplexity())
+
+	type compareComplexity struct {
+		id         string
+		cyclomatic int
+		cognitive  int
+		maxNesting int
+		returns    int
+	}
+	TSliceNotEqualPanic([]compareComplexity{
+		{"cfgs/logic.Nested", 4, 12, 3, 1},
+		{"cfgs/logic.Branchy", 7, 7, 2, 2},
+		{"cfgs/logic.(*Worker).Run", 3, 6, 3, 2},
+		{"cfgs/logic.Check", 4, 3, 1, 2},
+		{"cfgs/logic.Simple", 1, 0, 0, 1},
+	}, goPackageMeta.ComplexityReport(0), func(c compareComplexity, v *GoComplexityMeta) {
+		TNotEqualPanic(c.id, v.ID())
+		TNotEqualPanic(c.cyclomatic, v.Cyclomatic())
+		TNotEqualPanic(c.cognitive, v.Cognitive())
+		TNotEqualPanic(c.maxNesting, v.MaxNesting())
+		TNotEqualPanic(c.returns, v.ReturnCount())
+	})
+	TNotEqualPanic(2, len(goPackageMeta.ComplexityReport(2)))
+	TNotEqualPanic(12, goPackageMeta.SearchFuncMeta("Nested").CognitiveComplexity())
+	TNotEqualPanic(3, goPackageMeta.SearchFuncMeta("Nested").MaxNestingDepth())
+}
+
+func TestGoFuncMetaConcurrency(t *testing.T) {
+	projectPath := writeTestProject(t, map[string]string{
+		"go.mod": "module conc\n\ngo 1.22\n",
+		"svc/svc.go": `package svc
+
+import "sync"
+
+type Cache struct {
+	mu   sync.RWMutex
+	data map[string]int
+}
+
+type Counter struct {
+	sync.Mutex
+	n int
+}
+
+func (c *Cache) Get(key string) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.data[key]
+}
+
+func (c *Counter) Inc() {
+	c.Lock()
+	c.n++
+	c.Unlock()
+	c.Lock()
+}
+
+func worker(in <-chan int, out chan<- int) {
+	for v := range in {
+		out <- v
+	}
+}
+
+func Run(items []int, quit chan struct{}) int {
+	in := make(chan int)
+	out := make(chan int, len(items))
+	go worker(in, out)
+	go func() {
+		defer close(in)
+		for _, item := range items {
+			in <- item
+		}
+	}()
+	sum := 0
+	for range items {
+		select {
+		case v := <-out:
+			sum += v
+		case out <- 0:
+		case <-quit:
+			return sum
+		}
+	}
+	for i := 0; i < 1; i++ {
+		defer func() {}()
+	}
+	return sum + <-out
+}
+`,
+	})
+
+	goProjectMeta, err := ExtractGoProjectMeta(projectPath, nil)
+	if err != nil {
+		panic(err)
+	}
+	goPackageMeta := goProjectMeta.SearchPackageMeta("conc/svc")
+
+	runMeta := goPackageMeta.SearchFuncMeta("Run").Concurrency()
+	type compareChanOp struct {
+		opType   ChanOpType
+		channel  string
+		dir      ast.ChanDir
+		elem     string
+		capacity string
+		inSelect bool
+	}
+	TSliceNotEqualPanic([]compareChanOp{
+		{CHAN_OP_MAKE, "", ast.SEND | ast.RECV, "int", "", false},
+		{CHAN_OP_MAKE, "", ast.SEND | ast.RECV, "int", "len(items)", false},
+		{CHAN_OP_CLOSE, "in", ast.SEND | ast.RECV, "int", "", false},
+		{CHAN_OP_SEND, "in", ast.SEND | ast.RECV, "int", "", false},
+		{CHAN_OP_RECEIVE, "out", ast.SEND | ast.RECV, "int", "", true},
+		{CHAN_OP_SEND, "out", ast.SEND | ast.RECV, "int", "", true},
+		{CHAN_OP_RECEIVE, "quit", ast.SEND | ast.RECV, "struct{}", "", true},
+		{CHAN_OP_RECEIVE, "out", ast.SEND | ast.RECV, "int", "", false},
+	}, runMeta.ChanOps(), func(c compareChanOp, v *GoChanOpMeta) {
+		TNotEqualPanic(c.opType, v.OpType())
+		TNotEqualPanic(c.channel, v.Channel())
+		TNotEqualPanic(c.dir, v.Dir())
+		TNotEqualPanic(c.elem, v.Elem())
+		TNotEqualPanic(c.capacity, v.Capacity())
+		TNotEqualPanic(c.inSelect, v.InSelect())
+	})
+	TNotEqualPanic(1, len(runMeta.Selects()))
+	TNotEqualPanic(false, runMeta.Selects()[0].HasDefault())
+	TSliceNotEqualPanic([]compareChanOp{
+		{CHAN_OP_RECEIVE, "out", ast.SEND | ast.RECV, "int", "", true},
+		{CHAN_OP_SEND, "out", ast.SEND | ast.RECV, "int", "", true},
+		{CHAN_OP_RECEIVE, "quit", ast.SEND | ast.RECV, "struct{}", "", true},
+	}, runMeta.Selects()[0].Cases(), func(c compareChanOp, v *GoChanOpMeta) {
+		TNotEqualPanic(c.opType, v.OpType())
+		TNotEqualPanic(c.channel, v.Channel())
+		TNotEqualPanic(c.dir, v.Dir())
+		TNotEqualPanic(c.elem, v.Elem())
+		TNotEqualPanic(c.inSelect, v.InSelect())
+	})
+	TSliceNotEqualPanic([]bool{false, true}, runMeta.Defers(), func(c bool, v *GoDeferMeta) {
+		TNotEqualPanic(c, v.InLoop())
+	})
+
+	workerMeta := goPackageMeta.SearchFuncMeta("worker").Concurrency()
+	TSliceNotEqualPanic([]compareChanOp{
+		{CHAN_OP_RANGE, "in", ast.RECV, "int", "", false},
+		{CHAN_OP_SEND, "out", ast.SEND, "int", "", false},
+	}, workerMeta.ChanOps(), func(c compareChanOp, v *GoChanOpMeta) {
+		TNotEqualPanic(c.opType, v.OpType())
+		TNotEqualPanic(c.channel, v.Channel())
+		TNotEqualPanic(c.dir, v.Dir())
+	})
+
+	getMeta := goPackageMeta.SearchStructMeta("Cache").SearchMethodMeta("Get").Concurrency()
+	pairs, unpaired := getMeta.LockPairs()
+	TNotEqualPanic(1, len(pairs))
+	TNotEqualPanic(0, len(unpaired))
+	TNotEqualPanic("c.mu", pairs[0].Lock().Mutex())
+	TNotEqualPanic(LOCK_OP_RLOCK, pairs[0].Lock().OpType())
+	TNotEqualPanic(true, pairs[0].Unlock().IsDeferred())
+
+	incMeta := goPackageMeta.SearchStructMeta("Counter").SearchMethodMeta("Inc").Concurrency()
+	pairs, unpaired = incMeta.LockPairs()
+	TNotEqualPanic(3, len(incMeta.Locks()))
+	TNotEqualPanic(1, len(pairs))
+	TNotEqualPanic(1, len(unpaired))
+	TNotEqualPanic(25, unpaired[0].Pos().Line)
+
+	type compareSpawn struct {
+		funcIdent string
+		line      int
+		callType  CallType
+		launched  string
+	}
+	TSliceNotEqualPanic([]compareSpawn{
+		{"Run", 37, CALL_TYPE_FUNC, "worker"},
+		{"Run", 38, CALL_TYPE_CLOSURE, ""},
+	}, goProjectMeta.GoroutineSpawnSites(), func(c compareSpawn, v *GoGoroutineMeta) {
+		TNotEqualPanic(c.funcIdent, v.FuncMeta().Ident())
+		TNotEqualPanic(c.line, v.Pos().Line)
+		TNotEqualPanic(c.callType, v.CallMeta().CallType())
+		launched := ""
+		if gfm := v.Launched(); gfm != nil {
+			launched = gfm.Ident()
+		}
+		TNotEqualPanic(c.launched, launched)
+	})
+}
+
+func TestGoFuncMetaUncheckedErrors(t *testing.T) {
+	projectPath := writeTestProject(t, map[string]string{
+		"go.mod": "module errs\n\ngo 1.22\n",
+		"svc/svc.go": `package svc
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+type Store interface {
+	Save(key string) error
+}
+
+func load() (int, error) { return 0, nil }
+
+func save() error { return nil }
+
+func Run(s Store, f *os.File) (err error) {
+	save()
+	n, _ := load()
+	v, err2 := strconv.Atoi("1")
+	err2 = save()
+	if err2 != nil {
+		return err2
+	}
+	s.Save("k")
+	f.Close()
+	os.Remove("x")
+	fmt.Println(n)
+	var e error
+	if n > v {
+		_, e := load()
+		if e != nil {
+			return e
+		}
+	}
+	if e != nil {
+		return e
+	}
+	e = save()
+	err = save()
+	if _, err := load(); err != nil {
+		return err
+	}
+	go save()
+	defer save()
+	defer f.Close()
+	return
+}
+
+func Retry(keys []string) {
+	var err error
+	for range keys {
+		if err != nil {
+			return
+		}
+		err = save()
+	}
+	for err := save(); err != nil; err = save() {
+	}
+	for _, key := range keys {
+		err := save()
+		_ = key
+	}
+}
+`,
+	})
+
+	goProjectMeta, err := ExtractGoProjectMeta(projectPath, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	type compareDiagnostic struct {
+		line    int
+		column  int
+		message string
+	}
+	TSliceNotEqualPanic([]compareDiagnostic{
+		{18, 2, "error return value of save is not checked"},
+		{19, 5, "error return value of load is assigned to _"},
+		{20, 5, "error assigned to err2 is overwritten at "},
+		{25, 2, "error return value of s.Save is not checked"},
+		{26, 2, "error return value of f.Close is not checked"},
+		{27, 2, "error return value of os.Remove is not checked"},
+		{31, 6, "error assigned to e shadows the declaration at "},
+		{39, 2, "error assigned to e is never checked"},
+		{44, 5, "error return value of save is discarded by go statement"},
+		{45, 8, "error return value of save is discarded by defer"},
+		{46, 8, "error return value of f.Close is discarded by defer"},
+		{61, 3, "error assigned to err is never checked"},
+	}, goProjectMeta.UncheckedErrors(), func(c compareDiagnostic, v *GoDiagnostic) {
+		TNotEqualPanic(c.line, v.Pos().Line)
+		TNotEqualPanic(c.column, v.Pos().Column)
+		TNotEqualPanic("errcheck", v.Category())
+		TNotEqualPanic(true, strings.HasPrefix(v.Message(), c.message))
+	})
+
+	// 无法解析的 package 同样被缓存
+	TNotEqualPanic(true, importStdPackage("missing/pkg") == nil)
+	_, cached := stdPackages["missing/pkg"]
+	TNotEqualPanic(true, cached)
+}
+
+func TestGoFuncMetaFuncLits(t *testing.T) {
+	projectPath := writeTestProject(t, map[string]string{
+		"go.mod": "module lits\n\ngo 1.22\n",
+		"svc/svc.go": `package svc
+
+import "net/http"
+
+type Server struct{ name string }
+
+func (s *Server) Routes(mux *http.ServeMux, paths []string) func() int {
+	count := 0
+	for _, path := range paths {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			count++
+			w.Write([]byte(path + s.name))
+		})
+	}
+	for i := 0; i < 2; i++ {
+		go func() {
+			defer func() {
+				_ = i
+			}()
+		}()
+	}
+	add := func(n int) (sum int) {
+		const base = 1
+		local := n + base
+		return local + count
+	}
+	_ = add(1)
+	return func() int { return count }
+}
+`,
+	})
+
+	goProjectMeta, err := ExtractGoProjectMeta(projectPath, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	type compareCapture struct {
+		ident   string
+		loopVar bool
+		param   bool
+		written bool
+	}
+	type compareFuncLit struct {
+		line      int
+		usageType FuncLitUsageType
+		signature string
+		captures  []compareCapture
+		funcLits  int
+	}
+	routesMeta := goProjectMeta.SearchPackageMeta("lits/svc").SearchStructMeta("Server").SearchMethodMeta("Routes")
+	funcLitMetas := routesMeta.FuncLits()
+	TSliceNotEqualPanic([]compareFuncLit{
+		{10, FUNC_LIT_USAGE_ARG, "func(w http.ResponseWriter, r *http.Request)", []compareCapture{
+			{"count", false, false, true},
+			{"path", true, false, false},
+			{"s", false, true, false},
+		}, 0},
+		{16, FUNC_LIT_USAGE_GO, "func()", []compareCapture{{"i", true, false, false}}, 1},
+		{22, FUNC_LIT_USAGE_ASSIGN, "func(n int) (sum int)", []compareCapture{{"count", false, false, false}}, 0},
+		{28, FUNC_LIT_USAGE_RETURN, "func() int", []compareCapture{{"count", false, false, false}}, 0},
+	}, funcLitMetas, func(c compareFuncLit, v *GoFuncLitMeta) {
+		TNotEqualPanic(c.line, v.Pos().Line)
+		TNotEqualPanic(c.usageType, v.UsageType())
+		TNotEqualPanic(c.signature, v.Signature())
+		TNotEqualPanic(c.funcLits, len(v.FuncLits()))
+		TSliceNotEqualPanic(c.captures, v.Captures(), func(c compareCapture, v *GoCaptureMeta) {
+			TNotEqualPanic(c.ident, v.Ident())
+			TNotEqualPanic(c.loopVar, v.IsLoopVar())
+			TNotEqualPanic(c.param, v.IsParam())
+			TNotEqualPanic(c.written, v.IsWritten())
+		})
+	})
+	TNotEqualPanic("HandleFunc", funcLitMetas[0].CallMeta().Callee())
+	TNotEqualPanic(1, len(funcLitMetas[0].LoopVarCaptures()))
+	TNotEqualPanic(2, len(funcLitMetas[0].Params()))
+	TNotEqualPanic(9, funcLitMetas[0].SearchCaptureMeta("path").DeclPos().Line)
+
+	deferMeta := funcLitMetas[1].FuncLits()[0]
+	TNotEqualPanic(FUNC_LIT_USAGE_DEFER, deferMeta.UsageType())
+	TNotEqualPanic(funcLitMetas[1], deferMeta.Parent())
+	TNotEqualPanic("i", deferMeta.LoopVarCaptures()[0].Ident())
+	TNotEqualPanic(18, deferMeta.SearchCaptureMeta("i").Uses()[0].Line)
+}
+
+func TestGoProjectStringLiterals(t *testing.T) {
+	projectPath := writeTestProject(t, map[string]string{
+		"go.mod": "module texts\n\ngo 1.22\n",
+		"ui/ui.go": `package ui
+
+import (
+	"fmt"
+	"log"
+)
+
+const Title = "Hero \"Quest\""
+
+var greetings = []string{"hello", ` + "`raw\\n`" + `}
+
+type Player struct {
+	Name string ` + "`json:\"name\"`" + `
+}
+
+func (p *Player) Greet() error {
+	const prefix = "Dear"
+	log.Printf("greet %v", p.Name)
+	return fmt.Errorf(("no greeting for %v, %v"), prefix, "friend")
+}
+`,
+		"api/v2/text.go": "package text\n\nconst Hint = \"hint\"\n",
+	})
+
+	goProjectMeta, err := ExtractGoProjectMeta(projectPath, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	type compareLiteral struct {
+		key         string
+		value       string
+		raw         bool
+		declType    StringLiteralDeclType
+		isConst     bool
+		callContext string
+		argIndex    int
+		line        int
+	}
+	TSliceNotEqualPanic([]compareLiteral{
+		{"texts/api/v2.Hint#0", "hint", false, STRING_LITERAL_DECL_CONST, true, "", -1, 3},
+		{"texts/ui.Title#0", `Hero "Quest"`, false, STRING_LITERAL_DECL_CONST, true, "", -1, 8},
+		{"texts/ui.greetings#0", "hello", false, STRING_LITERAL_DECL_VAR, false, "", -1, 10},
+		{"texts/ui.greetings#1", `raw\n`, true, STRING_LITERAL_DECL_VAR, false, "", -1, 10},
+		{"texts/ui.(*Player).Greet#0", "Dear", false, STRING_LITERAL_DECL_METHOD, true, "", -1, 17},
+		{"texts/ui.(*Player).Greet#1", "greet %v", false, STRING_LITERAL_DECL_METHOD, false, "log.Printf", 0, 18},
+		{"texts/ui.(*Player).Greet#2", "no greeting for %v, %v", false, STRING_LITERAL_DECL_METHOD, false, "fmt.Errorf", 0, 19},
+		{"texts/ui.(*Player).Greet#3", "friend", false, STRING_LITERAL_DECL_METHOD, false, "fmt.Errorf", 2, 19},
+	}, goProjectMeta.StringLiterals(nil), func(c compareLiteral, v *GoStringLiteralMeta) {
+		TNotEqualPanic(c.key, v.Key())
+		TNotEqualPanic(c.value, v.Value())
+		TNotEqualPanic(c.raw, v.IsRaw())
+		TNotEqualPanic(c.declType, v.DeclType())
+		TNotEqualPanic(c.isConst, v.IsConst())
+		TNotEqualPanic(c.callContext, v.CallContext())
+		TNotEqualPanic(c.argIndex, v.ArgIndex())
+		TNotEqualPanic(c.line, v.Pos().Line)
+	})
+
+	formatLiterals := goProjectMeta.StringLiterals(func(gslm *GoStringLiteralMeta) bool {
+		return gslm.ArgIndex() == 0 && (gslm.CallContext() == "fmt.Errorf" || gslm.CallContext() == "log.Printf")
+	})
+	TNotEqualPanic(2, len(formatLiterals))
+	table, err := goProjectMeta.StringLiteralTable(formatLiterals)
+	if err != nil {
+		panic(err)
+	}
+	TNotEqualPanic(`key,file,line,column,context,source,translation
+texts/ui.(*Player).Greet#1,ui/ui.go,18,13,log.Printf,greet %v,
+texts/ui.(*Player).Greet#2,ui/ui.go,19,21,fmt.Errorf,"no greeting for %v, %v",
+`, string(table))
+}
+
+func TestGoProjectSelect(t *testing.T) {
+	projectPath := writeTestProject(t, map[string]string{
+		"go.mod": "module github.com/acme/game\n\ngo 1.22\n",
+		"service/service.go": `package service
+
+type Handler interface {
+	Handle() error
+	close()
+}
+
+type GameService struct {
+	Name string
+	id   int
+}
+
+func (s *GameService) HandleLogin() error { return nil }
+
+func (s GameService) HandleLogout() error { return nil }
+
+func (s *GameService) reset() {}
+
+func NewGameService() *GameService { return nil }
+
+var DefaultName = "game"
+
+const maxPlayers = 10
+`,
+		"service/player/player.go": `package player
+
+type Player struct{ Level int }
+
+func (p *Player) LevelUp() {}
+
+func (p *Player) GetLevel() int { return p.Level }
+
+func (p Player) SetName() {}
+
+type ChatService struct{}
+
+func (c *ChatService) HandleChat() {}
+`,
+		"api/v2/util.go": "package util\n\nfunc Join() {}\n",
+	})
+
+	goProjectMeta, err := ExtractGoProjectMeta(projectPath, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	for selector, compareIDs := range map[string][]string{
+		"github.com/acme/game/service.*Service.Handle*":      {"github.com/acme/game/service.(*GameService).HandleLogin", "github.com/acme/game/service.GameService.HandleLogout"},
+		"kind:method recv:*Player exported":                  {"github.com/acme/game/service/player.(*Player).LevelUp", "github.com/acme/game/service/player.(*Player).GetLevel"},
+		"recv:Player":                                        {"github.com/acme/game/service/player.(*Player).LevelUp", "github.com/acme/game/service/player.(*Player).GetLevel", "github.com/acme/game/service/player.Player.SetName"},
+		"pkg:github.com/acme/game/... kind:struct,interface": {"github.com/acme/game/service.GameService", "github.com/acme/game/service.Handler", "github.com/acme/game/service/player.Player", "github.com/acme/game/service/player.ChatService"},
+		"name:/^(Get|Set)/":                                  {"github.com/acme/game/service/player.(*Player).GetLevel", "github.com/acme/game/service/player.Player.SetName"},
+		"github.com/acme/game/service.* unexported":          {"github.com/acme/game/service.maxPlayers"},
+		"github.com/acme/game/....*.Handle*":                 {"github.com/acme/game/service.(*GameService).HandleLogin", "github.com/acme/game/service.GameService.HandleLogout", "github.com/acme/game/service.Handler.Handle", "github.com/acme/game/service/player.(*ChatService).HandleChat"},
+		"kind:field owner:GameService":                       {"github.com/acme/game/service.GameService.Name", "github.com/acme/game/service.GameService.id"},
+		"github.com/acme/game/service.New* kind:func":        {"github.com/acme/game/service.NewGameService"},
+		"service.*Service.Handle*":                           {"github.com/acme/game/service.(*GameService).HandleLogin", "github.com/acme/game/service.GameService.HandleLogout"},
+		"pkg:service/... kind:struct":                        {"github.com/acme/game/service.GameService", "github.com/acme/game/service/player.Player", "github.com/acme/game/service/player.ChatService"},
+		"service/player.*.Handle*":                           {"github.com/acme/game/service/player.(*ChatService).HandleChat"},
+		"api/v2.*":                                           {"github.com/acme/game/api/v2.Join"},
+	} {
+		matches, err := goProjectMeta.Select(selector)
+		if err != nil {
+			panic(err)
+		}
+		TSliceNotEqualPanic(compareIDs, matches, func(c string, v *GoSelectorMatch) { TNotEqualPanic(c, v.ID()) })
+	}
+
+	matches, err := goProjectMeta.Select("github.com/acme/game/service.NewGameService")
+	if err != nil {
+		panic(err)
+	}
+	TNotEqualPanic(1, len(matches))
+	TNotEqualPanic(SELECTOR_KIND_FUNC, matches[0].Kind())
+	TNotEqualPanic("NewGameService", matches[0].FuncMeta().Ident())
+	TNotEqualPanic(19, matches[0].Pos().Line)
+
+	for _, selector := range []string{"kind:bogus", "name:/[/", "a.b.c.d", "exported unexported", "color:red"} {
+		if _, err := ParseSelector(selector); err == nil {
+			panic(selector)
+		}
+	}
+}
+
+func TestGoProjectMetaAt(t *testing.T) {
+	projectPath := writeTestProject(t, map[string]string{
+		"go.mod": "module pos\n\ngo 1.22\n",
+		"model/model.go": `package model
+
+import "fmt"
+
+type Player struct {
+	ID, Level int
+	fmt.Stringer
+}
+
+func (p *Player) Print() {
+	fmt.Println(fmt.Sprint(p.ID))
+}
+
+type Store interface {
+	Load(id int) *Player
+}
+
+func Helper() {}
+`,
+	})
+
+	goProjectMeta, err := ExtractGoProjectMeta(projectPath, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	// p.ID 位于 fmt.Sprint 的参数内
+	gpsm, err := goProjectMeta.MetaAt("model/model.go", 11, 25)
+	if err != nil {
+		panic(err)
+	}
+	TSliceNotEqualPanic([]PositionMetaType{POSITION_META_CALL, POSITION_META_CALL, POSITION_META_METHOD, POSITION_META_FILE}, gpsm.Chain(), func(c PositionMetaType, v *GoPositionMeta) { TNotEqualPanic(c, v.PositionType()) })
+	TNotEqualPanic("Sprint", gpsm.CallMeta().Callee())
+	TNotEqualPanic("Println", gpsm.Parent().CallMeta().Callee())
+	TNotEqualPanic("Print", gpsm.MethodMeta().Ident())
+	TNotEqualPanic("Player", gpsm.StructMeta().Ident())
+	pos, end := gpsm.Range()
+	TNotEqualPanic(11, pos.Line)
+	TNotEqualPanic(14, pos.Column)
+	TNotEqualPanic(30, end.Column)
+
+	for _, c := range []struct {
+		line, col    int
+		positionType PositionMetaType
+		ident        string
+	}{
+		{6, 2, POSITION_META_MEMBER, "ID"},
+		{6, 6, POSITION_META_MEMBER, "Level"},
+		{6, 12, POSITION_META_MEMBER, "ID"},
+		{7, 6, POSITION_META_MEMBER, "Stringer"},
+		{5, 6, POSITION_META_STRUCT, "Player"},
+		{10, 1, POSITION_META_METHOD, "Print"},
+		{15, 3, POSITION_META_INTERFACE_METHOD, "Load"},
+		{14, 6, POSITION_META_INTERFACE, "Store"},
+		{18, 8, POSITION_META_FUNC, "Helper"},
+		{3, 1, POSITION_META_FILE, "model.go"},
+	} {
+		gpsm, err := goProjectMeta.MetaAt(filepath.Join(projectPath, "model", "model.go"), c.line, c.col)
+		if err != nil {
+			panic(err)
+		}
+		TNotEqualPanic(c.positionType, gpsm.PositionType())
+		switch c.positionType {
+		case POSITION_META_MEMBER:
+			TNotEqualPanic(c.ident, gpsm.MemberMeta().Ident())
+		case POSITION_META_STRUCT:
+			TNotEqualPanic(c.ident, gpsm.StructMeta().Ident())
+		case POSITION_META_METHOD, POSITION_META_FUNC:
+			TNotEqualPanic(c.ident, gpsm.FuncMeta().Ident())
+		case POSITION_META_INTERFACE_METHOD:
+			TNotEqualPanic(c.ident, gpsm.InterfaceMethodMeta().Ident())
+		case POSITION_META_INTERFACE:
+			TNotEqualPanic(c.ident, gpsm.InterfaceMeta().Ident())
+		case POSITION_META_FILE:
+			TNotEqualPanic(c.ident, gpsm.FileMeta().Ident())
+		}
+	}
+
+	// 所有 meta 均可获取起止位置
+	gsm := goProjectMeta.SearchPackageMeta("pos/model").SearchStructMeta("Player")
+	pos, end = gsm.Range()
+	TNotEqualPanic(5, pos.Line)
+	TNotEqualPanic(6, pos.Column)
+	TNotEqualPanic(8, end.Line)
+	pos, _ = gsm.SearchMethodMeta("Print").Range()
+	TNotEqualPanic(10, pos.Line)
+	pos, _ = gsm.SearchMemberMeta("Level").Range()
+	TNotEqualPanic(6, pos.Line)
+	pos, _ = goProjectMeta.SearchPackageMeta("pos/model").Range()
+	TNotEqualPanic(0, pos.Line)
+
+	for _, c := range []struct {
+		path      string
+		line, col int
+	}{{"model/other.go", 1, 1}, {"model/model.go", 100, 1}, {"model/model.go", 1, 100}} {
+		if _, err := goProjectMeta.MetaAt(c.path, c.line, c.col); err == nil {
+			panic(c)
+		}
+	}
+
+	// 提取后修改文件，位置仍然按照提取时的文件内容计算
+	modelPath := filepath.Join(projectPath, "model", "model.go")
+	modelContent, err := os.ReadFile(modelPath)
+	if err != nil {
+		panic(err)
+	}
+	if err = os.WriteFile(modelPath, append([]byte("// header\n\n"), modelContent...), 0644); err != nil {
+		panic(err)
+	}
+	pos, end = gsm.Range()
+	TNotEqualPanic(5, pos.Line)
+	TNotEqualPanic(8, end.Line)
+	gpsm, err = goProjectMeta.MetaAt("model/model.go", 6, 2)
+	if err != nil {
+		panic(err)
+	}
+	TNotEqualPanic("ID", gpsm.MemberMeta().Ident())
+}
+
+func TestGoProjectReferences(t *testing.T) {
+	projectPath := writeTestProject(t, map[string]string{
+		"go.mod": "module refs\n\ngo 1.22\n",
+		"model/model.go": `package model
+
+type Base struct {
+	ID int
+}
+
+func (b *Base) Key() int { return b.ID }
+
+type Player struct {
+	Base
+	Name  string
+	Level int
+}
+
+type Leveler interface{ LevelUp() }
+
+func NewPlayer(name string) *Player {
+	return &Player{Name: name, Base: Base{ID: 1}}
+}
+
+func (p *Player) LevelUp() {
+	p.Level++
+	p.Base.ID = p.Key()
+}
+
+var Default = NewPlayer("default")
+
+type Registry struct {
+	players map[string]*Player
+	list    []*Player
+}
+
+func (r *Registry) Names() []string {
+	return []string{r.players["x"].Name, r.list[0].Name}
+}
+
+type Inner struct {
+	ID int
+}
+
+type Deep struct {
+	Base
+}
+
+// Outer.ID 为深度较浅的 Inner.ID
+type Outer struct {
+	Deep
+	Inner
+}
+
+func OuterID(o Outer) int { return o.ID + o.Key() }
+`,
+		"service/service.go": `package service
+
+import (
+	m "refs/model"
+)
+
+type Wrapper struct {
+	*m.Player
+}
+
+func Run() {
+	p := m.NewPlayer("x")
+	p.LevelUp()
+	up := p.LevelUp
+	up()
+	f := (*m.Player).LevelUp
+	f(p)
+	name := p.Name
+	p.Name = name
+	w := Wrapper{Player: p}
+	w.LevelUp()
+	_ = w.ID
+	players := []*m.Player{{Name: "a"}}
+	_ = players
+	create := m.NewPlayer
+	_ = create
+	m.Default.Level = 3
+	var l m.Leveler = p
+	l.LevelUp()
+	byName := map[string]*m.Player{"a": p}
+	for _, v := range byName {
+		v.Level = 1
+	}
+	for k := range map[*m.Player]bool{} {
+		_ = k.Level
+	}
+	_ = byName["a"].Name
+	_ = players[0].Name
+	var x any = p
+	if pl, ok := x.(*m.Player); ok {
+		_ = pl.Name
+	}
+	_ = x.(*m.Player).Name
+	ch := make(chan *m.Player, 1)
+	_ = (<-ch).Name
+	q := struct{ P *m.Player }{P: p}
+	_ = q.P.Name
+}
+`,
+	})
+
+	goProjectMeta, err := ExtractGoProjectMeta(projectPath, nil)
+	if err != nil {
+		panic(err)
+	}
+	modelMeta := goProjectMeta.SearchPackageMeta("refs/model")
+	playerMeta := modelMeta.SearchStructMeta("Player")
+	levelerMeta := modelMeta.SearchInterfaceMeta("Leveler")
+
+	for _, c := range []struct {
+		target     any
+		references []string
+	}{
+		{modelMeta.SearchFuncMeta("NewPlayer"), []string{"model.go:26:1", "service.go:12:1", "service.go:25:2"}},
+		{playerMeta.SearchMethodMeta("LevelUp"), []string{"service.go:13:1", "service.go:14:2", "service.go:16:2", "service.go:21:1"}},
+		{playerMeta, []string{"model.go:17:3", "model.go:18:3", "model.go:21:3", "model.go:29:3", "model.go:30:3", "service.go:8:4", "service.go:16:3", "service.go:23:3", "service.go:30:3", "service.go:34:3", "service.go:40:3", "service.go:43:3", "service.go:44:3", "service.go:46:3"}},
+		{modelMeta.SearchStructMeta("Base").SearchMemberMeta("ID"), []string{"model.go:7:5", "model.go:18:6", "model.go:23:6", "service.go:22:5"}},
+		{modelMeta.SearchStructMeta("Inner").SearchMemberMeta("ID"), []string{"model.go:51:5"}},
+		{playerMeta.SearchMemberMeta("Level"), []string{"model.go:22:6", "service.go:27:6", "service.go:32:6", "service.go:35:5"}},
+		// 下标，range 的 key 和 value，类型断言，接收 channel，匿名 struct 的字段
+		{playerMeta.SearchMemberMeta("Name"), []string{"model.go:18:6", "model.go:34:5", "model.go:34:5", "service.go:18:5", "service.go:19:6", "service.go:23:6", "service.go:37:5", "service.go:38:5", "service.go:41:5", "service.go:43:5", "service.go:45:5", "service.go:47:5"}},
+		{modelMeta.SearchStructMeta("Base").SearchMethodMeta("Key"), []string{"model.go:23:1", "model.go:51:1"}},
+		{levelerMeta, []string{"service.go:28:3"}},
+		{levelerMeta.SearchMethodMeta("LevelUp"), []string{"service.go:29:1"}},
+		{modelMeta.SearchVarMeta("Default"), []string{"service.go:27:5"}},
+	} {
+		references, err := goProjectMeta.References(c.target)
+		if err != nil {
+			panic(err)
+		}
+		TSliceNotEqualPanic(c.references, references, func(r string, grm *GoReferenceMeta) {
+			TNotEqualPanic(r, fmt.Sprintf("%v:%v:%v", filepath.Base(grm.Pos().Filename), grm.Pos().Line, grm.ReferenceType()))
+		})
+	}
+
+	references, err := goProjectMeta.References(playerMeta.SearchMethodMeta("LevelUp"))
+	if err != nil {
+		panic(err)
+	}
+	TNotEqualPanic("Run", references[0].FuncMeta().Ident())
+	TNotEqualPanic("LevelUp", references[0].Expression())
+
+	// 一次遍历搜索所有声明的引用，与逐个搜索一致
+	matches := goProjectMeta.SelectBy(&GoSelector{})
+	referenceMap := goProjectMeta.referencesOfAll(matches)
+	for _, gsm := range matches {
+		TSliceNotEqualPanic(goProjectMeta.referencesOf(gsm), referenceMap[gsm], func(c, v *GoReferenceMeta) {
+			TNotEqualPanic(c.node, v.node)
+			TNotEqualPanic(c.referenceType, v.referenceType)
+		})
+	}
+
+	if _, err := goProjectMeta.References("Player"); err == nil {
+		panic("unsupported target")
+	}
+	if _, err := goProjectMeta.References(MakeUpFuncMeta("F", nil, nil)); err == nil {
+		panic("target not in project")
+	}
+}
+
+func TestGoProjectImportGraph(t *testing.T) {
+	projectPath := writeTestProject(t, map[string]string{
+		"go.mod":                   "module layers\n\ngo 1.22\n",
+		"tools/cli/cli.go":         "package cli\n\nimport (\n\t\"fmt\"\n\t\"layers/pkg/service\"\n)\n\nvar _ = fmt.Sprint(service.Run)\n",
+		"pkg/service/service.go":   "package service\n\nimport (\n\t\"layers/pkg/model\"\n\t\"layers/pkg/store\"\n\t\"layers/util\"\n)\n\nfunc Run() {}\n",
+		"pkg/service/service_x.go": "package service\n\nimport m \"layers/pkg/model\"\n\nvar _ m.Player\n",
+		"pkg/store/store.go":       "package store\n\nimport (\n\t\"layers/pkg/model\"\n\t\"layers/tools/cli\"\n)\n",
+		"pkg/model/model.go":       "package model\n\nimport \"layers/util\"\n\ntype Player struct{}\n",
+		"util/util.go":             "package util\n\nimport \"layers/pkg/model\"\n",
+	})
+
+	goProjectMeta, err := ExtractGoProjectMeta(projectPath, nil)
+	if err != nil {
+		panic(err)
+	}
+	ig := goProjectMeta.ImportGraph()
+	nodeIDs := func(nodes []*ImportGraphNode) []string {
+		ids := make([]string, 0, len(nodes))
+		for _, node := range nodes {
+			ids = append(ids, node.ID())
+		}
+		return ids
+	}
+	compareNodes := func(c []string, nodes []*ImportGraphNode) {
+		TSliceNotEqualPanic(c, nodeIDs(nodes), func(c, v string) { TNotEqualPanic(c, v) })
+	}
+
+	TNotEqualPanic(5, len(ig.Nodes()))
+	TNotEqualPanic(8, len(ig.Edges()))
+	serviceNode := ig.SearchNode("layers/pkg/service")
+	compareNodes([]string{"layers/pkg/model", "layers/pkg/store", "layers/util"}, serviceNode.Imports())
+	compareNodes([]string{"layers/tools/cli"}, serviceNode.ImportedBy())
+	TNotEqualPanic(2, len(serviceNode.Out()[0].ImportMetas()))
+	compareNodes([]string{"layers/pkg/model", "layers/pkg/service", "layers/tools/cli", "layers/util"}, ig.Dependencies("layers/pkg/store"))
+	compareNodes([]string{"layers/pkg/model", "layers/pkg/service", "layers/pkg/store", "layers/tools/cli"}, ig.Dependents("layers/util"))
+	compareNodes([]string{"layers/tools/cli", "layers/pkg/service", "layers/util"}, ig.ShortestPath("layers/tools/cli", "layers/util"))
+	TNotEqualPanic(0, len(ig.ShortestPath("layers/util", "layers/tools/cli")))
+
+	cycles := ig.Cycles()
+	TNotEqualPanic(2, len(cycles))
+	compareNodes([]string{"layers/pkg/model", "layers/util"}, cycles[0])
+	compareNodes([]string{"layers/pkg/service", "layers/pkg/store", "layers/tools/cli"}, cycles[1])
+
+	rulesFilePath := filepath.Join(projectPath, "layers.rules")
+	if err := os.WriteFile(rulesFilePath, []byte("# layering\npkg/... !-> tools/...\ntools/... -> pkg/...\npkg/... -> pkg/...\n\npkg/service -> util\n"), 0644); err != nil {
+		panic(err)
+	}
+	rules, err := LoadImportRules(rulesFilePath)
+	if err != nil {
+		panic(err)
+	}
+	TNotEqualPanic(4, len(rules))
+	TNotEqualPanic(6, rules[3].Line())
+	TSliceNotEqualPanic([]string{
+		"model.go:3: import of layers/util by layers/pkg/model is not allowed by any rule",
+		"store.go:5: import of layers/tools/cli by layers/pkg/store violates rule 'pkg/... !-> tools/...' at line 2",
+	}, ig.CheckRules(rules), func(c string, gd *GoDiagnostic) {
+		TNotEqualPanic(c, fmt.Sprintf("%v:%v: %v", filepath.Base(gd.Pos().Filename), gd.Pos().Line, gd.Message()))
+	})
+
+	for _, content := range []string{"a -> b -> c", "a => b", "a"} {
+		if _, err := ParseImportRules([]byte(content)); err == nil {
+			panic(content)
+		}
+	}
+
+	// package 名称与目录不同，目录排在 go.mod 之前
+	projectPath = writeTestProject(t, map[string]string{
+		"go.mod":               "module github.com/acme/game\n\ngo 1.22\n",
+		"pkg/store/store.go":   "package storage\n\nimport \"github.com/acme/game/app/svc\"\n\nvar _ = svc.Run\n",
+		"app/svc/svc.go":       "package svc\n\nimport \"github.com/acme/game/pkg/module\"\n\nfunc Run() { module.Init() }\n",
+		"pkg/module/module.go": "package module\n\nfunc Init() {}\n",
+	})
+	goProjectMeta, err = ExtractGoProjectMeta(projectPath, nil)
+	if err != nil {
+		panic(err)
+	}
+	ig = goProjectMeta.ImportGraph()
+	compareNodes([]string{"github.com/acme/game/app/svc", "github.com/acme/game/pkg/module", "github.com/acme/game/pkg/store"}, ig.Nodes())
+	TNotEqualPanic("storage", ig.SearchNode("github.com/acme/game/pkg/store").PackageMeta().Ident())
+	compareNodes([]string{"github.com/acme/game/pkg/store", "github.com/acme/game/app/svc", "github.com/acme/game/pkg/module"}, ig.ShortestPath("github.com/acme/game/pkg/store", "github.com/acme/game/pkg/module"))
+	rules, err = ParseImportRules([]byte("pkg/store !-> app/...\napp/... !-> pkg/...\n"))
+	if err != nil {
+		panic(err)
+	}
+	TSliceNotEqualPanic([]string{
+		"svc.go:3: import of github.com/acme/game/pkg/module by github.com/acme/game/app/svc violates rule 'app/... !-> pkg/...' at line 2",
+		"store.go:3: import of github.com/acme/game/app/svc by github.com/acme/game/pkg/store violates rule 'pkg/store !-> app/...' at line 1",
+	}, ig.CheckRules(rules), func(c string, gd *GoDiagnostic) {
+		TNotEqualPanic(c, fmt.Sprintf("%v:%v: %v", filepath.Base(gd.Pos().Filename), gd.Pos().Line, gd.Message()))
+	})
+
+	// 经过起始节点的循环长度不同，取最短的循环
+	projectPath = writeTestProject(t, map[string]string{
+		"go.mod": "module m\n\ngo 1.22\n",
+		"a/a.go": "package a\n\nimport (\n\t\"m/b\"\n\t\"m/c\"\n)\n\nvar _, _ = b.B, c.C\n",
+		"b/b.go": "package b\n\nimport \"m/c\"\n\nvar B = c.C\n",
+		"c/c.go": "package c\n\nimport \"m/a\"\n\nvar C = 0\n\nvar _ = a.A\n",
+	})
+	goProjectMeta, err = ExtractGoProjectMeta(projectPath, nil)
+	if err != nil {
+		panic(err)
+	}
+	cycles = goProjectMeta.ImportGraph().Cycles()
+	TNotEqualPanic(1, len(cycles))
+	compareNodes([]string{"m/a", "m/c"}, cycles[0])
+}
+
+func TestGoProjectSymbolIndex(t *testing.T) {
+	projectPath := writeTestProject(t, map[string]string{
+		"go.mod": "module tags\n\ngo 1.22\n",
+		"model/model.go": `package model
+
+// Player 玩家
+type Player struct {
+	Name string
+}
+
+func (p *Player) Rename(name string) { p.Name = name }
+
+type Namer interface {
+	Rename(name string)
+}
+
+const MaxLevel = 10
+
+var Default = &Player{Name: "default"}
+
+func New() *Player { return &Player{} }
+`,
+	})
+
+	goProjectMeta, err := ExtractGoProjectMeta(projectPath, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	TNotEqualPanic(strings.Join([]string{
+		"!_TAG_FILE_FORMAT\t2\t/extended format; --format=1 will not append ;\" to lines/",
+		"!_TAG_FILE_SORTED\t1\t/0=unsorted, 1=sorted, 2=foldcase/",
+		"!_TAG_PROGRAM_NAME\tgo-extractor\t//",
+		"Default\tmodel/model.go\t16;\"\tv\tline:16",
+		"MaxLevel\tmodel/model.go\t14;\"\tc\tline:14",
+		"Name\tmodel/model.go\t5;\"\tm\tline:5\tstruct:Player",
+		"Namer\tmodel/model.go\t10;\"\ti\tline:10",
+		"New\tmodel/model.go\t18;\"\tf\tline:18",
+		"Player\tmodel/model.go\t4;\"\ts\tline:4",
+		"Rename\tmodel/model.go\t11;\"\tn\tline:11\tinterface:Namer",
+		"Rename\tmodel/model.go\t8;\"\tf\tline:8\tstruct:Player",
+	}, "\n")+"\n", string(goProjectMeta.CTags()))
+
+	content, err := goProjectMeta.SymbolIndex()
+	if err != nil {
+		panic(err)
+	}
+	index := &symbolIndexJSON{}
+	if err := json.Unmarshal(content, index); err != nil {
+		panic(err)
+	}
+	TNotEqualPanic("UTF8", index.Metadata.TextDocumentEncoding)
+	TNotEqualPanic(1, len(index.Documents))
+	document := index.Documents[0]
+	TNotEqualPanic("model/model.go", document.RelativePath)
+	TSliceNotEqualPanic([]string{
+		"go-extractor gomod tags . `tags/model`/Default.",
+		"go-extractor gomod tags . `tags/model`/MaxLevel.",
+		"go-extractor gomod tags . `tags/model`/Namer#",
+		"go-extractor gomod tags . `tags/model`/Namer#Rename().",
+		"go-extractor gomod tags . `tags/model`/New().",
+		"go-extractor gomod tags . `tags/model`/Player#",
+		"go-extractor gomod tags . `tags/model`/Player#Name.",
+		"go-extractor gomod tags . `tags/model`/Player#Rename().",
+	}, document.Symbols, func(c string, v *symbolIndexSymbolJSON) { TNotEqualPanic(c, v.Symbol) })
+	playerSymbol := document.Symbols[5]
+	TNotEqualPanic("Struct", playerSymbol.Kind)
+	TNotEqualPanic("Player 玩家", playerSymbol.Documentation[0])
+	TNotEqualPanic(playerSymbol.Symbol, document.Symbols[6].EnclosingSymbol)
+
+	nameOccurrences := make([]string, 0)
+	playerOccurrences := 0
+	for _, occurrence := range document.Occurrences {
+		switch occurrence.Symbol {
+		case document.Symbols[6].Symbol:
+			nameOccurrences = append(nameOccurrences, fmt.Sprintf("%v:%v", occurrence.Range, occurrence.SymbolRoles))
+		case playerSymbol.Symbol:
+			playerOccurrences++
+		}
+	}
+	TSliceNotEqualPanic([]string{"[4 1 5]:1", "[7 41 45]:4", "[15 22 26]:4"}, nameOccurrences, func(c, v string) { TNotEqualPanic(c, v) })
+	// 定义，receiver，Default，New 的返回值和字面量
+	TNotEqualPanic(5, playerOccurrences)
+
+	// 符号使用导入路径，目录排在 go.mod 之前，package 名称与目录不同
+	projectPath = writeTestProject(t, map[string]string{
+		"go.mod":         "module m\n\ngo 1.22\n",
+		"app/app.go":     "package app\n\nfunc Run() {}\n",
+		"lib/v2/util.go": "package util\n\nfunc F() {}\n",
+	})
+	goProjectMeta, err = ExtractGoProjectMeta(projectPath, nil)
+	if err != nil {
+		panic(err)
+	}
+	if content, err = goProjectMeta.SymbolIndex(); err != nil {
+		panic(err)
+	}
+	index = &symbolIndexJSON{}
+	if err := json.Unmarshal(content, index); err != nil {
+		panic(err)
+	}
+	TSliceNotEqualPanic([]string{
+		"go-extractor gomod m . `m/app`/Run().",
+		"go-extractor gomod m . `m/lib/v2`/F().",
+	}, index.Documents, func(c string, v *symbolIndexDocumentJSON) { TNotEqualPanic(c, v.Symbols[0].Symbol) })
+}
+
+func TestGoOrderedAccessors(t *testing.T) {
+	projectPath := writeTestProject(t, map[string]string{
+		"go.mod": "module order\n\ngo 1.22\n",
+		"model/b.go": `package model
+
+type Writer interface {
+	Write()
+	Flush()
+	Close()
+}
+
+type Base struct{}
+
+func (p *Packet) Reset() {}
+
+func Zero() {}
+
+const (
+	Tail = iota
+	Head
+)
+`,
+		"model/a.go": `package model
+
+// Packet 二进制布局按照 member 的声明顺序
+type Packet struct {
+	Size    uint32
+	Base
+	Z, A    uint16
+	*Writer
+	Payload []byte
+}
+
+func (p *Packet) Encode() {}
+
+func (p Packet) Decode() {}
+
+func Make() {}
+
+func Append() {}
+
+var y, x = 1, 2
+`,
+	})
+
+	goProjectMeta, err := ExtractGoProjectMeta(projectPath, nil)
+	if err != nil {
+		panic(err)
+	}
+	TSliceNotEqualPanic([]string{"order/model"}, goProjectMeta.Packages(), func(c string, v *GoPackageMeta) { TNotEqualPanic(c, v.ImportPath()) })
+	packageMeta := goProjectMeta.SearchPackageMeta("order/model")
+
+	// 多次获取的结果一致
+	for i := 0; i < 8; i++ {
+		TSliceNotEqualPanic([]string{"a.go", "b.go"}, packageMeta.Files(), func(c string, v *GoFileMeta) { TNotEqualPanic(c, v.Ident()) })
+		TSliceNotEqualPanic([]string{"Make", "Append", "Zero"}, packageMeta.Funcs(), func(c string, v *GoFuncMeta) { TNotEqualPanic(c, v.Ident()) })
+		TSliceNotEqualPanic([]string{"Packet", "Base"}, packageMeta.Structs(), func(c string, v *GoStructMeta) { TNotEqualPanic(c, v.Ident()) })
+		TSliceNotEqualPanic([]string{"Writer"}, packageMeta.Interfaces(), func(c string, v *GoInterfaceMeta) { TNotEqualPanic(c, v.Ident()) })
+		TSliceNotEqualPanic([]string{"y", "x"}, packageMeta.Vars(), func(c string, v *GoVarMeta) { TNotEqualPanic(c, v.Ident()) })
+		TSliceNotEqualPanic([]string{"Tail", "Head"}, packageMeta.Consts(), func(c string, v *GoVarMeta) { TNotEqualPanic(c, v.Ident()) })
+
+		packetMeta := packageMeta.SearchStructMeta("Packet")
+		TSliceNotEqualPanic([]string{"Size", "Base", "Z", "A", "Writer", "Payload"}, packetMeta.Members(), func(c string, v *GoVarMeta) { TNotEqualPanic(c, v.Ident()) })
+		TSliceNotEqualPanic([]string{"Encode", "Decode", "Reset"}, packetMeta.Methods(), func(c string, v *GoMethodMeta) { TNotEqualPanic(c, v.Ident()) })
+		TSliceNotEqualPanic([]string{"Write", "Flush", "Close"}, packageMeta.SearchInterfaceMeta("Writer").Methods(), func(c string, v *GoInterfaceMethodMeta) { TNotEqualPanic(c, v.Ident()) })
+		TSliceNotEqualPanic([]string{"Packet", "Writer", "Base"}, packageMeta.StructNames(), func(c, v string) { TNotEqualPanic(c, v) })
+		TSliceNotEqualPanic([]string{"Make", "Append", "Zero"}, packageMeta.FunctionNames(), func(c, v string) { TNotEqualPanic(c, v) })
+	}
+}
+
+func TestGoFileMetaRewrite(t *testing.T) {
+	projectPath := writeTestProject(t, map[string]string{
+		"model/player.go": `package model
+
+// Player 玩家
+type Player struct {
+	// Name 名称
+	Name string // 行尾注释
+	Z, A int
+	Level int
+}
+
+// Greet 问候
+func (p *Player) Greet() string {
+	// 旧的实现
+	return "hi"
+}
+
+const (
+	// MaxLevel 最大等级
+	MaxLevel = 100
+	MinLevel = 1
+)
+
+var Ratio, Scale = 1, 2
+
+func Unused() {}
+`,
+	})
+	filePath := filepath.Join(projectPath, "model", "player.go")
+
+	goPackageMeta, err := ExtractGoPackageMeta(filepath.Dir(filePath), nil)
+	if err != nil {
+		panic(err)
+	}
+	goPackageMeta.ExtractAll()
+	fileMeta := goPackageMeta.SearchFileMeta("player.go")
+	playerMeta := goPackageMeta.SearchStructMeta("Player")
+
+	if err = fileMeta.ReplaceBody(playerMeta.SearchMethodMeta("Greet"), "return \"hello, \" + p.Name"); err != nil {
+		panic(err)
+	}
+	if err = fileMeta.ReplaceDoc(playerMeta.SearchMethodMeta("Greet"), "Greet 向玩家问候", "", "返回问候语"); err != nil {
+		panic(err)
+	}
+	if err = fileMeta.ReplaceField(playerMeta.SearchMemberMeta("Name"), "Name string `json:\"name\"`"); err != nil {
+		panic(err)
+	}
+	if err = fileMeta.RemoveField(playerMeta.SearchMemberMeta("Z")); err != nil {
+		panic(err)
+	}
+	if err = fileMeta.ReplaceDoc(playerMeta.SearchMemberMeta("Level"), "Level 等级"); err != nil {
+		panic(err)
+	}
+	if err = fileMeta.AddField(playerMeta, "// Exp 经验\nExp int64"); err != nil {
+		panic(err)
+	}
+	if err = fileMeta.ReplaceDecl(goPackageMeta.SearchConstMeta("MaxLevel"), "const MaxLevel = 200"); err != nil {
+		panic(err)
+	}
+	// 与其他标识共享的声明只能整体替换
+	TNotEqualPanic(true, fileMeta.ReplaceDecl(goPackageMeta.SearchVarMeta("Ratio"), "var Ratio = 3") != nil)
+	TNotEqualPanic(true, fileMeta.RemoveDecl(goPackageMeta.SearchVarMeta("Ratio")) != nil)
+	if err = fileMeta.ReplaceDecl(goPackageMeta.SearchVarMeta("Scale"), "var Ratio, Scale = 1, 4"); err != nil {
+		panic(err)
+	}
+	if err = fileMeta.RemoveDecl(goPackageMeta.SearchFuncMeta("Unused")); err != nil {
+		panic(err)
+	}
+	if err = fileMeta.AppendDecl("// NewPlayer 构造玩家\nfunc NewPlayer() *Player { return &Player{} }"); err != nil {
+		panic(err)
+	}
+	TNotEqualPanic(10, len(fileMeta.PendingEdits()))
+
+	// 冲突的修改和不支持的修改
+	TNotEqualPanic(true, fileMeta.ReplaceDecl(playerMeta.SearchMethodMeta("Greet"), "func (p *Player) Greet() string { return \"\" }") != nil)
+	TNotEqualPanic(true, fileMeta.AddField(playerMeta, "Level int") != nil)
+	TNotEqualPanic(true, fileMeta.ReplaceDecl(goPackageMeta.SearchConstMeta("MinLevel"), "var MinLevel = 1") != nil)
+	TNotEqualPanic(true, fileMeta.ReplaceBody(playerMeta, "return") != nil)
+	TNotEqualPanic(10, len(fileMeta.PendingEdits()))
+
+	compareContent := `package model
+
+// Player 玩家
+type Player struct {
+	// Name 名称
+	Name string ` + "`json:\"name\"`" + ` // 行尾注释
+	A    int
+	// Level 等级
+	Level int
+	// Exp 经验
+	Exp int64
+}
+
+// Greet 向玩家问候
+//
+// 返回问候语
+func (p *Player) Greet() string {
+	return "hello, " + p.Name
+}
+
+const (
+	// MaxLevel 最大等级
+	MaxLevel = 200
+	MinLevel = 1
+)
+
+var Ratio, Scale = 1, 4
+
+// NewPlayer 构造玩家
+func NewPlayer() *Player { return &Player{} }
+`
+	preview, err := fileMeta.Preview()
+	if err != nil {
+		panic(err)
+	}
+	TNotEqualPanic(compareContent, string(preview))
+	if err = fileMeta.Save(); err != nil {
+		panic(err)
+	}
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		panic(err)
+	}
+	TNotEqualPanic(compareContent, string(content))
+	TNotEqualPanic(0, len(fileMeta.PendingEdits()))
+
+	// 文件在提取后被修改
+	if err = fileMeta.ReplaceDoc(playerMeta, "Player 玩家数据"); err != nil {
+		panic(err)
+	}
+	TNotEqualPanic(true, fileMeta.Save() != nil)
+	fileMeta.DiscardEdits()
+
+	// 重新提取后可以继续修改
+	goPackageMeta, err = ExtractGoPackageMeta(filepath.Dir(filePath), nil)
+	if err != nil {
+		panic(err)
+	}
+	goPackageMeta.ExtractAll()
+	fileMeta = goPackageMeta.SearchFileMeta("player.go")
+	if err = fileMeta.ReplaceDoc(goPackageMeta.SearchStructMeta("Player")); err != nil {
+		panic(err)
+	}
+	if err = fileMeta.Save(); err != nil {
+		panic(err)
+	}
+	content, err = os.ReadFile(filePath)
+	if err != nil {
+		panic(err)
+	}
+	TNotEqualPanic(strings.Replace(compareContent, "// Player 玩家\n", "", 1), string(content))
+}
+
+func TestGoProjectRename(t *testing.T) {
+	projectPath := writeTestProject(t, map[string]string{
+		"go.mod": "module ren\n\ngo 1.22\n",
+		"model/player.go": `package model
+
+// Base 基础数据
+type Base struct{ ID int }
+
+// Player 玩家，名称为 [Player.Name]，参见 [Player.LevelUp] 和 [Base]
+type Player struct {
+	Base
+	Name  string
+	Level int
+}
+
+// Greet 问候 [Player]
+func (p *Player) Greet() string { return "hi, " + p.Name }
+
+// LevelUp 升级
+func (p *Player) LevelUp() { p.Level++ }
+
+// Greeter 可以问候
+type Greeter interface {
+	Greet() string
+}
+
+// NewPlayer 构造 [Player]
+func NewPlayer(name string) *Player {
+	return &Player{Base: Base{ID: 1}, Name: name}
+}
+
+func shadow() *Player {
+	Level := 1
+	_ = Level
+	return NewPlayer("")
+}
+
+// Registry 玩家注册表
+type Registry struct {
+	players map[string]*Player
+	list    []*Player
+}
+
+func (r *Registry) Names() []string {
+	names := []string{r.players["x"].Name, r.list[0].Name}
+	for _, p := range r.players {
+		names = append(names, p.Name)
+	}
+	for _, p := range r.list {
+		names = append(names, p.Name)
+	}
+	return names
+}
+`,
+		"service/service.go": `package service
+
+import "ren/model"
+
+// Serve 使用 [model.Player] 和 [model.Player.Name]，参见 [model.NewPlayer]
+func Serve() string {
+	p := model.NewPlayer("tom")
+	p.Base.ID = 2
+	up := (*model.Player).LevelUp
+	up(p)
+	players := []*model.Player{{Name: "a"}}
+	return p.Name + players[0].Greet()
+}
+
+func Lookup(ps []*model.Player, x any, ch chan *model.Player) []string {
+	names := []string{ps[0].Name}
+	for k := range map[*model.Player]bool{} {
+		names = append(names, k.Name)
+	}
+	if pl, ok := x.(*model.Player); ok {
+		names = append(names, pl.Name)
+	}
+	q := struct{ P *model.Player }{P: <-ch}
+	return append(names, (<-ch).Name, q.P.Name)
+}
+`,
+	})
+
+	extract := func() (*GoProjectMeta, *GoPackageMeta, *GoStructMeta) {
+		goProjectMeta, err := ExtractGoProjectMeta(projectPath, nil)
+		if err != nil {
+			panic(err)
+		}
+		for _, packageMeta := range goProjectMeta.Packages() {
+			if packageMeta.Ident() != "service" {
+				return goProjectMeta, packageMeta, packageMeta.SearchStructMeta("Player")
+			}
+		}
+		panic("model package not found")
+	}
+	goProjectMeta, modelMeta, playerMeta := extract()
+
+	// 拒绝的重命名
+	for _, c := range []struct {
+		target  any
+		newName string
+	}{
+		{modelMeta.SearchFuncMeta("NewPlayer"), "1x"},
+		{modelMeta.SearchFuncMeta("NewPlayer"), "string"},
+		{modelMeta.SearchFuncMeta("NewPlayer"), "Base"},
+		{modelMeta.SearchFuncMeta("NewPlayer"), "Level"},
+		{modelMeta.SearchFuncMeta("NewPlayer"), "newPlayer"},
+		{playerMeta.SearchMemberMeta("Name"), "Level"},
+		{playerMeta.SearchMemberMeta("Name"), "LevelUp"},
+		{playerMeta.SearchMemberMeta("Base"), "Core"},
+		{playerMeta.SearchMethodMeta("Greet"), "Hello"},
+		{modelMeta.SearchInterfaceMeta("Greeter").SearchMethodMeta("Greet"), "Hello"},
+		{modelMeta.SearchStructMeta("Base"), "Name"},
+	} {
+		_, err := goProjectMeta.Rename(c.target, c.newName)
+		TNotEqualPanic(true, err != nil)
+	}
+
+	// 字段: 声明，selector，struct 字面量的 key，省略类型的字面量的 key，文档链接
+	editSet, err := goProjectMeta.Rename(playerMeta.SearchMemberMeta("Name"), "Nickname")
+	if err != nil {
+		panic(err)
+	}
+	TNotEqualPanic(16, len(editSet.Edits()))
+	diff, err := editSet.Diff()
+	if err != nil {
+		panic(err)
+	}
+	for _, line := range []string{
+		"--- " + filepath.Join(projectPath, "model", "player.go") + "\n",
+		"@@ -3,15 +3,15 @@\n",
+		// 原样替换，不重新格式化
+		"-\tName  string\n+\tNickname  string\n \tLevel int\n",
+		"+\tnames := []string{r.players[\"x\"].Nickname, r.list[0].Nickname}\n",
+		"+\t\tnames = append(names, p.Nickname)\n",
+		"+\treturn &Player{Base: Base{ID: 1}, Nickname: name}\n",
+		"+++ " + filepath.Join(projectPath, "service", "service.go") + "\n",
+		"+// Serve 使用 [model.Player] 和 [model.Player.Nickname]，参见 [model.NewPlayer]\n",
+		"+\tplayers := []*model.Player{{Nickname: \"a\"}}\n",
+		"+\tnames := []string{ps[0].Nickname}\n",
+		"+\t\tnames = append(names, k.Nickname)\n",
+		"+\t\tnames = append(names, pl.Nickname)\n",
+		"+\treturn append(names, (<-ch).Nickname, q.P.Nickname)\n",
+	} {
+		TNotEqualPanic(true, strings.Contains(diff, line))
+	}
+	if err = editSet.Apply(); err != nil {
+		panic(err)
+	}
+
+	// 匿名成员的类型: 类型的引用以及匿名成员字段的引用
+	goProjectMeta, modelMeta, _ = extract()
+	editSet, err = goProjectMeta.Rename(modelMeta.SearchStructMeta("Base"), "Core")
+	if err != nil {
+		panic(err)
+	}
+	if err = editSet.Apply(); err != nil {
+		panic(err)
+	}
+
+	// method: 声明，method 表达式，文档链接
+	goProjectMeta, _, playerMeta = extract()
+	editSet, err = goProjectMeta.Rename(playerMeta.SearchMethodMeta("LevelUp"), "Upgrade")
+	if err != nil {
+		panic(err)
+	}
+	if err = editSet.Apply(); err != nil {
+		panic(err)
+	}
+
+	// package: package 子句，限定符，文档链接
+	goProjectMeta, modelMeta, _ = extract()
+	editSet, err = goProjectMeta.Rename(modelMeta, "entity")
+	if err != nil {
+		panic(err)
+	}
+	if err = editSet.Apply(); err != nil {
+		panic(err)
+	}
+	for p, compareContent := range map[string]string{
+		"model/player.go": `package entity
+
+// Core 基础数据
+type Core struct{ ID int }
+
+// Player 玩家，名称为 [Player.Nickname]，参见 [Player.Upgrade] 和 [Core]
+type Player struct {
+	Core
+	Nickname  string
+	Level int
+}
+
+// Greet 问候 [Player]
+func (p *Player) Greet() string { return "hi, " + p.Nickname }
+
+// Upgrade 升级
+func (p *Player) Upgrade() { p.Level++ }
+
+// Greeter 可以问候
+type Greeter interface {
+	Greet() string
+}
+
+// NewPlayer 构造 [Player]
+func NewPlayer(name string) *Player {
+	return &Player{Core: Core{ID: 1}, Nickname: name}
+}
+
+func shadow() *Player {
+	Level := 1
+	_ = Level
+	return NewPlayer("")
+}
+
+// Registry 玩家注册表
+type Registry struct {
+	players map[string]*Player
+	list    []*Player
+}
+
+func (r *Registry) Names() []string {
+	names := []string{r.players["x"].Nickname, r.list[0].Nickname}
+	for _, p := range r.players {
+		names = append(names, p.Nickname)
+	}
+	for _, p := range r.list {
+		names = append(names, p.Nickname)
+	}
+	return names
+}
+`,
+		"service/service.go": `package service
+
+import "ren/model"
+
+// Serve 使用 [entity.Player] 和 [entity.Player.Nickname]，参见 [entity.NewPlayer]
+func Serve() string {
+	p := entity.NewPlayer("tom")
+	p.Core.ID = 2
+	up := (*entity.Player).Upgrade
+	up(p)
+	players := []*entity.Player{{Nickname: "a"}}
+	return p.Nickname + players[0].Greet()
+}
+
+func Lookup(ps []*entity.Player, x any, ch chan *entity.Player) []string {
+	names := []string{ps[0].Nickname}
+	for k := range map[*entity.Player]bool{} {
+		names = append(names, k.Nickname)
+	}
+	if pl, ok := x.(*entity.Player); ok {
+		names = append(names, pl.Nickname)
+	}
+	q := struct{ P *entity.Player }{P: <-ch}
+	return append(names, (<-ch).Nickname, q.P.Nickname)
+}
+`,
+	} {
+		content, err := os.ReadFile(filepath.Join(projectPath, p))
+		if err != nil {
+			panic(err)
+		}
+		TNotEqualPanic(compareContent, string(content))
+	}
+
+	// 重命名后仍然可以提取，且修改集合为空时 Diff 为空
+	_, _, playerMeta = extract()
+	TNotEqualPanic(true, playerMeta.SearchMemberMeta("Core") != nil)
+	TNotEqualPanic(true, playerMeta.SearchMethodMeta("Upgrade") != nil)
+	diff, err = newGoEditSet(nil).Diff()
+	if err != nil {
+		panic(err)
+	}
+	TNotEqualPanic("", diff)
+
+	// 无法推断 receiver 类型的同名 selector，拒绝重命名并列出其位置
+	projectPath = writeTestProject(t, map[string]string{
+		"go.mod": "module unres\n\ngo 1.22\n",
+		"model/model.go": `package model
+
+type Player struct{ Name string }
+
+func first[T any](ts []T) T { return ts[0] }
+
+func Names(players []*Player) []string {
+	get := func() *Player { return players[0] }
+	return []string{first(players).Name, get().Name, players[0].Name}
+}
+`,
+	})
+	goProjectMeta, _, playerMeta = extract()
+	_, err = goProjectMeta.Rename(playerMeta.SearchMemberMeta("Name"), "Nickname")
+	TNotEqualPanic(true, err != nil)
+	TNotEqualPanic(true, strings.HasSuffix(err.Error(), "model.go:9:33, "+filepath.Join(projectPath, "model", "model.go")+":9:45"))
+
+	// 替换后无法被解析时拒绝预览和写入
+	modelPath := filepath.Join(projectPath, "model", "model.go")
+	content, err := os.ReadFile(modelPath)
+	if err != nil {
+		panic(err)
+	}
+	brokenSet := newGoEditSet([]*GoTextEdit{newGoTextEdit(modelPath, content, 0, len("package"), "pkg")})
+	_, err = brokenSet.Diff()
+	TNotEqualPanic(true, err != nil)
+	TNotEqualPanic(true, brokenSet.Apply() != nil)
+	unchanged, err := os.ReadFile(modelPath)
+	if err != nil {
+		panic(err)
+	}
+	TNotEqualPanic(string(content), string(unchanged))
+
+	// 导入路径限定的文档链接，package 名称与目录不同
+	projectPath = writeTestProject(t, map[string]string{
+		"go.mod":         "module m\n\ngo 1.22\n",
+		"lib/v2/util.go": "package util\n\nfunc F() {}\n",
+		"app/app.go":     "package app\n\nimport \"m/lib/v2\"\n\n// Run 调用 [util.F] 和 [m/lib/v2.F]\nfunc Run() { util.F() }\n",
+	})
+	goProjectMeta, err = ExtractGoProjectMeta(projectPath, nil)
+	if err != nil {
+		panic(err)
+	}
+	matches, err := goProjectMeta.Select("lib/v2.F")
+	if err != nil {
+		panic(err)
+	}
+	TNotEqualPanic(1, len(matches))
+	editSet, err = goProjectMeta.Rename(matches[0].FuncMeta(), "G")
+	if err != nil {
+		panic(err)
+	}
+	if err = editSet.Apply(); err != nil {
+		panic(err)
+	}
+	content, err = os.ReadFile(filepath.Join(projectPath, "app", "app.go"))
+	if err != nil {
+		panic(err)
+	}
+	TNotEqualPanic("package app\n\nimport \"m/lib/v2\"\n\n// Run 调用 [util.G] 和 [m/lib/v2.G]\nfunc Run() { util.G() }\n", string(content))
+
+	// 通过嵌入的 interface 实现的 method
+	projectPath = writeTestProject(t, map[string]string{
+		"go.mod":         "module m\n\ngo 1.22\n",
+		"model/model.go": "package model\n\ntype Failure interface{ error }\n\ntype Fault struct{}\n\nfunc (f Fault) Error() string { return \"\" }\n",
+	})
+	goProjectMeta, err = ExtractGoProjectMeta(projectPath, nil)
+	if err != nil {
+		panic(err)
+	}
+	_, err = goProjectMeta.Rename(goProjectMeta.SearchPackageMeta("m/model").SearchStructMeta("Fault").SearchMethodMeta("Error"), "Message")
+	TNotEqualPanic(true, err != nil)
+}
@@ -28,11 +28,28 @@ type GoFileMeta struct {
 
 	// 文件包名称
 	packageName string
+
+	// 文件内所有 import 的 meta 数据，按照源码顺序
+	importMetas []*GoImportMeta
+
+	// 文件内所有 编译指令 的 meta 数据，按照源码顺序
+	directiveMetas []*GoDirectiveMeta
+
+	// 文件的 package 子句的文档注释
+	commentMeta
+
+	// 提取时的文件内容，写回时用于检测文件是否已经被修改
+	content []byte
+
+	// 尚未写回的 文本替换，按照添加顺序
+	pendingEdits []*GoTextEdit
 }
 
 // newGoFileMeta 通过 ast 构造 go 文件 的 meta 数据
 func newGoFileMeta(m *meta, fs *token.FileSet, fn string) *GoFileMeta {
-	return &GoFileMeta{meta: m, fileSet: fs, ident: fn}
+	gfm := &GoFileMeta{meta: m, fileSet: fs, ident: fn, commentMeta: newCommentMeta(m.node)}
+	gfm.meta.fileMeta = gfm
+	return gfm
 }
 
 // -------------------------------- extractor --------------------------------
@@ -44,8 +61,13 @@ func ExtractGoFileMeta(extractFilepath string) (*GoFileMeta, error) {
 		return nil, err
 	}
 
+	fileContent, err := os.ReadFile(fileAbsPath)
+	if err != nil {
+		return nil, err
+	}
+
 	fileSet := token.NewFileSet()
-	fileAST, err := parser.ParseFile(fileSet, fileAbsPath, nil, parser.ParseComments)
+	fileAST, err := parser.ParseFile(fileSet, fileAbsPath, fileContent, parser.ParseComments)
 	if err != nil {
 		return nil, err
 	}
@@ -55,7 +77,16 @@ func ExtractGoFileMeta(extractFilepath string) (*GoFileMeta, error) {
 		fileSet:     fileSet,
 		ident:       filepath.Base(fileAbsPath),
 		packageName: fileAST.Name.String(),
+		commentMeta: newCommentMeta(fileAST),
+		content:     fileContent,
 	}
+	meta.meta.fileMeta = meta
+
+	// 提取 import
+	meta.extractImport()
+
+	// 提取 编译指令
+	meta.extractDirective()
 
 	return meta, nil
 }
@@ -72,10 +103,103 @@ func (gfm *GoFileMeta) OutputAST() {
 	ast.Fprint(outputFile, gfm.fileSet, gfm.node, ast.NotNilFilter)
 }
 
+// extractImport 提取文件中所有 import 的 meta 数据
+func (gfm *GoFileMeta) extractImport() {
+	fileAST, ok := gfm.node.(*ast.File)
+	if !ok {
+		return
+	}
+	gfm.importMetas = make([]*GoImportMeta, 0, len(fileAST.Imports))
+	for _, decl := range fileAST.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			gfm.importMetas = append(gfm.importMetas, newGoImportMeta(gfm.copyMeta(spec), gfm.fileSet, genDecl))
+		}
+	}
+}
+
+// extractDirective 提取文件中所有注释内的 编译指令 的 meta 数据
+func (gfm *GoFileMeta) extractDirective() {
+	fileAST, ok := gfm.node.(*ast.File)
+	if !ok {
+		return
+	}
+	gfm.directiveMetas = make([]*GoDirectiveMeta, 0)
+	for _, commentGroup := range fileAST.Comments {
+		gfm.directiveMetas = append(gfm.directiveMetas, extractDirectiveMetas(gfm.meta, commentGroup)...)
+	}
+}
+
+// resolveImports 将文件中所有 import 解析到项目内的 package
+func (gfm *GoFileMeta) resolveImports(searchPackageMeta func(string) *GoPackageMeta) {
+	for _, gim := range gfm.importMetas {
+		gim.resolve(searchPackageMeta(gim.importPath))
+	}
+}
+
+// SearchImport 根据 别名 或 package 名称 搜索文件中 import 的 meta 数据
+// - 不会搜索到 _ 导入和 . 导入
+func (gfm *GoFileMeta) SearchImport(aliasOrName string) *GoImportMeta {
+	if aliasOrName == "_" || aliasOrName == "." {
+		return nil
+	}
+	for _, gim := range gfm.importMetas {
+		if gim.Ident() == aliasOrName {
+			return gim
+		}
+	}
+	return nil
+}
+
+// dotImports 获取文件中所有 . 导入的 meta 数据
+func (gfm *GoFileMeta) dotImports() []*GoImportMeta {
+	dotImports := make([]*GoImportMeta, 0)
+	for _, gim := range gfm.importMetas {
+		if gim.IsDot() {
+			dotImports = append(dotImports, gim)
+		}
+	}
+	return dotImports
+}
+
+// UnusedImports 根据文件中的 selector 用法获取未使用的 import 的 meta 数据
+// - _ 导入和 . 导入无法通过 selector 判断，视为已使用
+// - 与 import 同名的局部变量的 selector 用法视为使用了该 import
+func (gfm *GoFileMeta) UnusedImports() []*GoImportMeta {
+	selectorXIdents := make(map[string]struct{})
+	ast.Inspect(gfm.node, func(n ast.Node) bool {
+		if selectorExpr, ok := n.(*ast.SelectorExpr); ok {
+			if xIdent, ok := selectorExpr.X.(*ast.Ident); ok {
+				selectorXIdents[xIdent.Name] = struct{}{}
+			}
+		}
+		return true
+	})
+	unusedImports := make([]*GoImportMeta, 0)
+	for _, gim := range gfm.importMetas {
+		if gim.IsBlank() || gim.IsDot() {
+			continue
+		}
+		if _, used := selectorXIdents[gim.Ident()]; !used {
+			unusedImports = append(unusedImports, gim)
+		}
+	}
+	return unusedImports
+}
+
 // -------------------------------- unit test --------------------------------
 
 func (gfm *GoFileMeta) Ident() string       { return gfm.ident }
 func (gfm *GoFileMeta) PackageName() string { return gfm.packageName }
+func (gfm *GoFileMeta) Imports() []*GoImportMeta {
+	return gfm.importMetas
+}
+func (gfm *GoFileMeta) Directives() []*GoDirectiveMeta {
+	return gfm.directiveMetas
+}
 
 // -------------------------------- unit test --------------------------------
 
@@ -0,0 +1,240 @@
+package extractor
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+type FuncLitUsageType int
+
+const (
+	FUNC_LIT_USAGE_ARG    = iota + 1 // 作为调用的参数: t.Run("x", func(t *testing.T) {})
+	FUNC_LIT_USAGE_GO                // 由 go 语句启动: go func() {}()
+	FUNC_LIT_USAGE_DEFER             // 由 defer 语句延迟执行: defer func() {}()
+	FUNC_LIT_USAGE_CALL              // 定义后立即调用: func() {}()
+	FUNC_LIT_USAGE_ASSIGN            // 赋值给变量: f := func() {}
+	FUNC_LIT_USAGE_RETURN            // 作为返回值: return func() {}
+	FUNC_LIT_USAGE_OTHER             // 其他: 字面量的元素，channel 发送等
+)
+
+// GoFuncLitMeta go func 内的 func 字面量 的 meta 数据
+// - for _, c := range cases { t.Run(c.name, func(t *testing.T) { use(c) }) } -> usageType: FUNC_LIT_USAGE_ARG, captures: [c]
+type GoFuncLitMeta struct {
+	// 组合基本 meta 数据
+	// ast 节点，要求为 *ast.FuncLit
+	// 以 ast 节点 为单位执行 AST/PrintAST/Expression/Format
+	*meta
+
+	// func 字面量所在的 func 的 meta 数据
+	funcMeta *GoFuncMeta
+
+	// 外层的 func 字面量，直接位于 func 内时为 nil
+	parent *GoFuncLitMeta
+
+	// 内层的 func 字面量，按照源码顺序
+	funcLits []*GoFuncLitMeta
+
+	// func 字面量的参数
+	params []*GoVarMeta
+
+	// func 字面量的返回值
+	returns []*GoVarMeta
+
+	// func 字面量的使用方式
+	usageType FuncLitUsageType
+
+	// 使用方式为 FUNC_LIT_USAGE_ARG 时所在的调用
+	callMeta *GoCallMeta
+
+	// func 字面量捕获的外部局部标识，按照首次使用的顺序
+	captures []*GoCaptureMeta
+}
+
+// GoCaptureMeta func 字面量捕获的外部局部标识 的 meta 数据
+type GoCaptureMeta struct {
+	// 捕获的局部标识
+	object *scopeObject
+
+	// func 字面量内使用该标识的所有 ast 节点，按照源码顺序
+	uses []*ast.Ident
+
+	// func 字面量内是否写入了该标识
+	written bool
+
+	// 所在 func 字面量的基本 meta 数据，用于计算位置
+	funcLitMeta *meta
+}
+
+// -------------------------------- extractor --------------------------------
+
+// FuncLits 提取 func 内直接包含的 func 字面量，内层的 func 字面量通过 GoFuncLitMeta.FuncLits 获取
+func (gfm *GoFuncMeta) FuncLits() []*GoFuncLitMeta {
+	body := funcBody(gfm.node)
+	if body == nil {
+		return nil
+	}
+	ctx := newCallContext(gfm.packageMeta, gfm.path, gfm.scopeInfo())
+	ctx.funcMeta = gfm
+
+	root := make([]*GoFuncLitMeta, 0)
+	funcLits := make([]*GoFuncLitMeta, 0)
+	stack := make([]ast.Node, 0)
+	ast.Inspect(body, func(n ast.Node) bool {
+		if n == nil {
+			if _, ok := stack[len(stack)-1].(*ast.FuncLit); ok {
+				funcLits = funcLits[:len(funcLits)-1]
+			}
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		if funcLit, ok := n.(*ast.FuncLit); ok {
+			gflm := newGoFuncLitMeta(gfm.copyMeta(funcLit), gfm, stack, ctx)
+			if len(funcLits) > 0 {
+				gflm.parent = funcLits[len(funcLits)-1]
+				gflm.parent.funcLits = append(gflm.parent.funcLits, gflm)
+			} else {
+				root = append(root, gflm)
+			}
+			funcLits = append(funcLits, gflm)
+		}
+		stack = append(stack, n)
+		return true
+	})
+	return root
+}
+
+// newGoFuncLitMeta 通过 ast 构造 func 字面量的 meta 数据，stack 为 func 字面量的所有外层节点
+func newGoFuncLitMeta(m *meta, gfm *GoFuncMeta, stack []ast.Node, ctx *callContext) *GoFuncLitMeta {
+	funcLit := m.node.(*ast.FuncLit)
+	gflm := &GoFuncLitMeta{
+		meta:      m,
+		funcMeta:  gfm,
+		params:    extractFieldListVarMeta(m, funcLit.Type.Params),
+		returns:   extractFieldListVarMeta(m, funcLit.Type.Results),
+		usageType: FUNC_LIT_USAGE_OTHER,
+		captures:  make([]*GoCaptureMeta, 0),
+	}
+
+	// 使用方式
+	var parent, grandparent ast.Node
+	if len(stack) > 0 {
+		parent = stack[len(stack)-1]
+	}
+	if len(stack) > 1 {
+		grandparent = stack[len(stack)-2]
+	}
+	switch p := parent.(type) {
+	case *ast.CallExpr:
+		if ast.Unparen(p.Fun) == funcLit {
+			switch grandparent.(type) {
+			case *ast.GoStmt:
+				gflm.usageType = FUNC_LIT_USAGE_GO
+			case *ast.DeferStmt:
+				gflm.usageType = FUNC_LIT_USAGE_DEFER
+			default:
+				gflm.usageType = FUNC_LIT_USAGE_CALL
+			}
+		} else {
+			gflm.usageType = FUNC_LIT_USAGE_ARG
+			gflm.callMeta = newGoCallMeta(m.copyMeta(p), ctx)
+		}
+	case *ast.AssignStmt, *ast.ValueSpec:
+		gflm.usageType = FUNC_LIT_USAGE_ASSIGN
+	case *ast.ReturnStmt:
+		gflm.usageType = FUNC_LIT_USAGE_RETURN
+	}
+
+	// 自由变量: 使用的局部标识声明在 func 字面量之外
+	captureMap := make(map[*scopeObject]*GoCaptureMeta)
+	ast.Inspect(funcLit.Body, func(n ast.Node) bool {
+		ident, ok := n.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		obj := ctx.scopeInfo.uses[ident]
+		if obj == nil || obj.kind == scopeObjectConst || obj.kind == scopeObjectType {
+			return true
+		}
+		if obj.ident.Pos() >= funcLit.Pos() && obj.ident.Pos() < funcLit.End() {
+			return true
+		}
+		gcpm, has := captureMap[obj]
+		if !has {
+			gcpm = &GoCaptureMeta{object: obj, funcLitMeta: m}
+			captureMap[obj] = gcpm
+			gflm.captures = append(gflm.captures, gcpm)
+		}
+		gcpm.uses = append(gcpm.uses, ident)
+		return true
+	})
+	for _, gcpm := range gflm.captures {
+		for _, write := range gcpm.object.writes {
+			if write.Pos() >= funcLit.Pos() && write.Pos() < funcLit.End() {
+				gcpm.written = true
+			}
+		}
+	}
+	return gflm
+}
+
+// Signature func 字面量的签名: func(t *testing.T)
+func (gflm *GoFuncLitMeta) Signature() string {
+	return types.ExprString(gflm.node.(*ast.FuncLit).Type)
+}
+
+// LoopVarCaptures 获取 func 字面量捕获的所有循环变量，不区分 go.mod 中的 go 版本
+func (gflm *GoFuncLitMeta) LoopVarCaptures() []*GoCaptureMeta {
+	captures := make([]*GoCaptureMeta, 0)
+	for _, gcpm := range gflm.captures {
+		if gcpm.IsLoopVar() {
+			captures = append(captures, gcpm)
+		}
+	}
+	return captures
+}
+
+// SearchCaptureMeta 搜索 func 字面量捕获的外部局部标识
+func (gflm *GoFuncLitMeta) SearchCaptureMeta(ident string) *GoCaptureMeta {
+	for _, gcpm := range gflm.captures {
+		if gcpm.object.ident.Name == ident {
+			return gcpm
+		}
+	}
+	return nil
+}
+
+// IsLoopVar 捕获的标识是否是 for 语句的初始化语句或 range 语句声明的循环变量
+func (gcpm *GoCaptureMeta) IsLoopVar() bool {
+	switch gcpm.object.scope.node.(type) {
+	case *ast.ForStmt, *ast.RangeStmt:
+		return gcpm.object.kind == scopeObjectVar
+	}
+	return false
+}
+
+// IsParam 捕获的标识是否是外层 func 的参数，命名返回值或 receiver
+func (gcpm *GoCaptureMeta) IsParam() bool {
+	return gcpm.object.kind == scopeObjectParam || gcpm.object.kind == scopeObjectResult || gcpm.object.kind == scopeObjectReceiver
+}
+
+// -------------------------------- unit test --------------------------------
+
+func (gflm *GoFuncLitMeta) FuncMeta() *GoFuncMeta       { return gflm.funcMeta }
+func (gflm *GoFuncLitMeta) Parent() *GoFuncLitMeta      { return gflm.parent }
+func (gflm *GoFuncLitMeta) FuncLits() []*GoFuncLitMeta  { return gflm.funcLits }
+func (gflm *GoFuncLitMeta) Params() []*GoVarMeta        { return gflm.params }
+func (gflm *GoFuncLitMeta) Returns() []*GoVarMeta       { return gflm.returns }
+func (gflm *GoFuncLitMeta) UsageType() FuncLitUsageType { return gflm.usageType }
+func (gflm *GoFuncLitMeta) CallMeta() *GoCallMeta       { return gflm.callMeta }
+func (gflm *GoFuncLitMeta) Captures() []*GoCaptureMeta  { return gflm.captures }
+func (gflm *GoFuncLitMeta) Pos() token.Position         { return gflm.position(gflm.node.Pos()) }
+func (gflm *GoFuncLitMeta) End() token.Position         { return gflm.position(gflm.node.End()) }
+func (gcpm *GoCaptureMeta) Ident() string               { return gcpm.object.ident.Name }
+func (gcpm *GoCaptureMeta) IsWritten() bool             { return gcpm.written }
+func (gcpm *GoCaptureMeta) DeclPos() token.Position {
+	return gcpm.funcLitMeta.position(gcpm.object.ident.Pos())
+}
+func (gcpm *GoCaptureMeta) Uses() []token.Position {
+	return identPositions(gcpm.funcLitMeta, gcpm.uses)
+}
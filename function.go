@@ -27,14 +27,19 @@ type GoFuncMeta struct {
 	// func 生成时的 block stmt
 	makeUpBlockStmt string
 
-	// callMeta map[string][]*GoCallMeta
-	// nonSelectorCallMeta map[string][]*GoCallMeta
-	// selectorCallMeta    map[string]map[string][]*GoCallMeta
+	// func 的文档注释
+	commentMeta
+
+	// func 所属的 package 的 meta 数据，通过 package 提取时存在
+	packageMeta *GoPackageMeta
+
+	// func 内的作用域分析结果，首次使用时构造
+	scope *scopeInfo
 }
 
 // newGoFuncMeta 通过 ast 构造 func 的 meta 数据
 func newGoFuncMeta(m *meta, ident string, stopExtract ...bool) *GoFuncMeta {
-	gfm := &GoFuncMeta{meta: m, ident: ident}
+	gfm := &GoFuncMeta{meta: m, ident: ident, commentMeta: newCommentMeta(m.node)}
 	if len(stopExtract) == 0 {
 		gfm.ExtractAll()
 	}
@@ -45,11 +50,35 @@ func (gfm *GoFuncMeta) funcDecl() *ast.FuncDecl {
 	return gfm.node.(*ast.FuncDecl)
 }
 
+// scopeInfo 获取 func 内的作用域分析结果
+func (gfm *GoFuncMeta) scopeInfo() *scopeInfo {
+	if gfm.scope == nil {
+		gfm.scope = newScopeInfo(gfm.node)
+	}
+	return gfm.scope
+}
+
 // -------------------------------- unit test --------------------------------
 
-func (gfm *GoFuncMeta) Ident() string         { return gfm.ident }
-func (gfm *GoFuncMeta) Params() []*GoVarMeta  { return gfm.params }
-func (gfm *GoFuncMeta) Returns() []*GoVarMeta { return gfm.returns }
+func (gfm *GoFuncMeta) Ident() string               { return gfm.ident }
+func (gfm *GoFuncMeta) Params() []*GoVarMeta        { return gfm.params }
+func (gfm *GoFuncMeta) Returns() []*GoVarMeta       { return gfm.returns }
+func (gfm *GoFuncMeta) PackageMeta() *GoPackageMeta { return gfm.packageMeta }
+
+// IsVariadic 最后一个参数是否是可变参数
+func (gfm *GoFuncMeta) IsVariadic() bool {
+	return len(gfm.params) > 0 && gfm.params[len(gfm.params)-1].isVariadic
+}
+
+// Directives 获取 func 的文档注释内的 编译指令: //go:noinline，//go:linkname 等
+func (gfm *GoFuncMeta) Directives() []*GoDirectiveMeta {
+	return extractDirectiveMetas(gfm.meta, gfm.doc)
+}
+
+// Annotations 使用注解解析器获取 func 的文档注释内的所有注解
+func (gfm *GoFuncMeta) Annotations(parsers ...AnnotationParser) ([]*GoAnnotationMeta, []*GoAnnotationError) {
+	return extractAnnotationMetas(gfm.meta, gfm, gfm.doc, parsers)
+}
 
 // -------------------------------- unit test --------------------------------
 
@@ -89,41 +118,33 @@ func (gfm *GoFuncMeta) ExtractAll() {
 
 func (gfm *GoFuncMeta) extractParams() {
 	funcDecl := gfm.funcDecl()
-	if funcDecl.Type == nil || funcDecl.Type.Params == nil || len(funcDecl.Type.Params.List) == 0 {
+	if funcDecl.Type == nil {
 		return
 	}
-
-	pLen := len(funcDecl.Type.Params.List)
-	gfm.params = make([]*GoVarMeta, 0, pLen)
-	for _, field := range funcDecl.Type.Params.List {
-		for _, name := range field.Names {
-			gfm.params = append(gfm.params, newGoVarMeta(newMeta(field, gfm.path), name.String()))
-		}
-	}
+	gfm.params = extractFieldListVarMeta(gfm.meta, funcDecl.Type.Params)
 }
 
 func (gfm *GoFuncMeta) extractReturns() {
 	funcDecl := gfm.funcDecl()
-	if funcDecl.Type == nil || funcDecl.Type.Results == nil || len(funcDecl.Type.Results.List) == 0 {
+	if funcDecl.Type == nil {
 		return
 	}
-
-	rLen := len(funcDecl.Type.Results.List)
-	gfm.returns = make([]*GoVarMeta, 0, rLen)
-	for _, field := range funcDecl.Type.Results.List {
-		if len(field.Names) > 0 {
-			for _, name := range field.Names {
-				gfm.returns = append(gfm.returns, newGoVarMeta(newMeta(field, gfm.path), name.String()))
-			}
-		} else {
-			gfm.returns = append(gfm.returns, newGoVarMeta(newMeta(field, gfm.path), ""))
-		}
-	}
+	gfm.returns = extractFieldListVarMeta(gfm.meta, funcDecl.Type.Results)
 }
 
 func (gfm *GoFuncMeta) extractTypeParams() {
 }
 
+// foreachVarMeta 遍历 func 的 params，returns 的 meta 数据
+func (gfm *GoFuncMeta) foreachVarMeta(f func(*GoVarMeta)) {
+	for _, gvm := range gfm.params {
+		f(gvm)
+	}
+	for _, gvm := range gfm.returns {
+		f(gvm)
+	}
+}
+
 // -------------------------------- extractor --------------------------------
 
 // -------------------------------- maker --------------------------------
@@ -161,17 +182,6 @@ func (gfm *GoFuncMeta) Make() string {
 
 // -------------------------------- maker --------------------------------
 
-// func (gfm *GoFuncMeta) Doc() []string {
-// 	if gfm.node.(*ast.FuncDecl) == nil || gfm.node.(*ast.FuncDecl).Doc == nil || len(gfm.node.(*ast.FuncDecl).Doc.List) == 0 {
-// 		return nil
-// 	}
-// 	commentSlice := make([]string, 0, len(gfm.node.(*ast.FuncDecl).Doc.List))
-// 	for _, comment := range gfm.node.(*ast.FuncDecl).Doc.List {
-// 		commentSlice = append(commentSlice, comment.Text)
-// 	}
-// 	return commentSlice
-// }
-
 // func (gfm *GoFuncMeta) TypeParams() []*GoVarMeta {
 // 	if gfm.node.(*ast.FuncDecl).Type == nil || gfm.node.(*ast.FuncDecl).Type.TypeParams == nil || len(gfm.node.(*ast.FuncDecl).Type.TypeParams.List) == 0 {
 // 		return nil
@@ -191,33 +201,6 @@ func (gfm *GoFuncMeta) Make() string {
 // 	return tParams
 // }
 
-// func (gfm *GoFuncMeta) ReplaceDecl(new *GoFuncMeta) {
-// 	if new.node.(*ast.FuncDecl).Doc != nil {
-// 		gfm.node.(*ast.FuncDecl).Doc = new.node.(*ast.FuncDecl).Doc
-// 	}
-// 	if new.node.(*ast.FuncDecl).Name != nil {
-// 		gfm.node.(*ast.FuncDecl).Name = new.node.(*ast.FuncDecl).Name
-// 	}
-// 	if new.node.(*ast.FuncDecl).Recv != nil {
-// 		gfm.node.(*ast.FuncDecl).Recv = new.node.(*ast.FuncDecl).Recv
-// 	}
-// 	if new.node.(*ast.FuncDecl).Type != nil {
-// 		gfm.node.(*ast.FuncDecl).Type = new.node.(*ast.FuncDecl).Type
-// 	}
-// }
-
-// type BlockMeta struct {
-// 	*meta
-// }
-
-// func (gfm *GoFuncMeta) Body() *BlockMeta {
-// 	return &BlockMeta{meta: gfm.copyMeta(gfm.node.(*ast.FuncDecl).Body)}
-// }
-
-// func (gfm *GoFuncMeta) ReplaceBody(new *BlockMeta) {
-// 	gfm.node.(*ast.FuncDecl).Body = new.node.(*ast.BlockStmt)
-// }
-
 // func (gfm *GoFuncMeta) Expression() string {
 // 	originPos := gfm.node.(*ast.FuncDecl).Pos()
 // 	originEnd := gfm.node.(*ast.FuncDecl).End()
@@ -252,43 +235,4 @@ func (gfm *GoFuncMeta) Make() string {
 // 	return wrapTestType(BENCHMARK, gfm.testFuncName(typeArgs))
 // }
 
-// func (gfm *GoFunctionMeta) ReplaceFunctionDoc(newDoc []string) (string, string, error) {
-// 	originContent := gfm.Expression()
-
-// 	gfm.node.(*ast.FuncDecl).Doc = &ast.CommentGroup{
-// 		List: make([]*ast.Comment, 0, len(newDoc)),
-// 	}
-// 	for _, comment := range newDoc {
-// 		gfm.node.(*ast.FuncDecl).Doc.List = append(gfm.node.(*ast.FuncDecl).Doc.List, &ast.Comment{
-// 			Text: comment,
-// 		})
-// 	}
-
-// 	buffer := &bytes.Buffer{}
-// 	err := format.Node(buffer, gfm.fileMeta.fileSet, gfm.node.(*ast.FuncDecl))
-// 	if err != nil {
-// 		panic(err)
-// 	}
-
-// 	return strings.ReplaceAll(originContent, "\r", ""), strings.ReplaceAll(buffer.String(), "\r", ""), nil
-// }
-
-// func (gfm *GoFunctionMeta) SearchCallMeta(call string) []*GoCallMeta {
-// 	if gcm, has := gfm.callMeta[call]; gcm != nil && has {
-// 		return gcm
-// 	}
-
-// 	if gfm.node.(*ast.FuncDecl) == nil {
-// 		return nil
-// 	}
-
-// 	gfm.callMeta[call] = SearchGoCallMeta(gfm.meta, call)
-
-// 	return gfm.callMeta[call]
-// }
-
-// func (gfm *GoFunctionMeta) Calls() map[string][]*GoCallMeta {
-// 	return ExtractGoCallMeta(gfm.meta)
-// }
-
 // func (gfm *GoFunctionMeta) Search
@@ -1,52 +1,108 @@
 package extractor
 
-// type GoImportMeta struct {
-// 	*meta
-// }
-
-// func ExtractGoImportMeta(extractFilepath, alias string) (*GoImportMeta, error) {
-// 	gfm, err := ExtractGoFileMeta(extractFilepath)
-// 	if err != nil {
-// 		return nil, err
-// 	}
-
-// 	gim := SearchGoImportMeta(gfm.meta, alias)
-// 	if gim == nil {
-// 		return nil, fmt.Errorf("can not find import node")
-// 	}
-
-// 	return gim, nil
-// }
-
-// func SearchGoImportMeta(m *meta, alias string) *GoImportMeta {
-// 	var importSpec *ast.ImportSpec
-// 	ast.Inspect(m.node, func(n ast.Node) bool {
-// 		if IsImportNode(n) {
-// 			spec := n.(*ast.ImportSpec)
-// 			if spec.Name != nil && spec.Name.String() == alias {
-// 				importSpec = spec
-// 			} else if filepath.Base(strings.Trim(spec.Path.Value, "\"")) == alias {
-// 				importSpec = spec
-// 			}
-// 		}
-// 		return importSpec == nil
-// 	})
-// 	if importSpec == nil {
-// 		return nil
-// 	}
-// 	return &GoImportMeta{
-// 		meta: m.copyMeta(importSpec),
-// 	}
-// }
-
-// func (gim *GoImportMeta) Alias() string {
-// 	aliasIdent := gim.node.(*ast.ImportSpec).Name
-// 	if aliasIdent == nil {
-// 		return ""
-// 	}
-// 	return aliasIdent.String()
-// }
-
-// func (gim *GoImportMeta) ImportPath() string {
-// 	return filepath.Base(strings.Trim(gim.node.(*ast.ImportSpec).Path.Value, "\""))
-// }
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+)
+
+// GoImportMeta go import 的 meta 数据
+type GoImportMeta struct {
+	// 组合基本 meta 数据
+	// ast 节点，要求为 *ast.ImportSpec
+	// 以 ast 节点 为单位执行 AST/PrintAST/Expression/Format
+	*meta
+
+	// import 的别名，未指定别名时为空
+	// - _ 表示空白导入
+	// - . 表示点导入
+	alias string
+
+	// import 的导入路径
+	importPath string
+
+	// import 的 package 名称
+	// - 项目内的 package 为其实际名称
+	// - 项目外的 package 为根据导入路径推测的名称
+	packageName string
+
+	// import 的 package 的 meta 数据，仅当其在项目内时存在
+	packageMeta *GoPackageMeta
+
+	// import 的文档注释和行尾注释
+	commentMeta
+
+	// import 在文件中的起止位置
+	pos, end token.Position
+}
+
+// newGoImportMeta 通过 ast 构造 import 的 meta 数据
+func newGoImportMeta(m *meta, fileSet *token.FileSet, genDecl *ast.GenDecl) *GoImportMeta {
+	importSpec := m.node.(*ast.ImportSpec)
+	gim := &GoImportMeta{
+		meta:        m,
+		commentMeta: newCommentMeta(importSpec),
+		pos:         fileSet.Position(importSpec.Pos()),
+		end:         fileSet.Position(importSpec.End()),
+	}
+	gim.inheritDoc(genDecl)
+	if importSpec.Name != nil {
+		gim.alias = importSpec.Name.Name
+	}
+	gim.importPath, _ = strconv.Unquote(importSpec.Path.Value)
+	gim.packageName = importPathAssumedName(gim.importPath)
+	return gim
+}
+
+// -------------------------------- extractor --------------------------------
+
+// ExtractGoImportMeta 通过文件的绝对路径和 import 的 别名或 package 名称 提取文件中 import 的 meta 数据
+func ExtractGoImportMeta(extractFilepath, aliasOrName string) (*GoImportMeta, error) {
+	gfm, err := ExtractGoFileMeta(extractFilepath)
+	if err != nil {
+		return nil, err
+	}
+
+	gim := gfm.SearchImport(aliasOrName)
+	if gim == nil {
+		return nil, fmt.Errorf("can not find import node")
+	}
+
+	return gim, nil
+}
+
+// resolve 将 import 解析到项目内的 package
+func (gim *GoImportMeta) resolve(gpm *GoPackageMeta) {
+	gim.packageMeta = gpm
+	if gpm != nil {
+		gim.packageName = gpm.ident
+	}
+}
+
+// -------------------------------- extractor --------------------------------
+
+// Ident import 在文件中被引用的标识：别名，未指定别名时为 package 名称
+func (gim *GoImportMeta) Ident() string {
+	if len(gim.alias) > 0 {
+		return gim.alias
+	}
+	return gim.packageName
+}
+
+// IsBlank 是否是空白导入: import _ "xxx"
+func (gim *GoImportMeta) IsBlank() bool { return gim.alias == "_" }
+
+// IsDot 是否是点导入: import . "xxx"
+func (gim *GoImportMeta) IsDot() bool { return gim.alias == "." }
+
+// -------------------------------- unit test --------------------------------
+
+func (gim *GoImportMeta) Alias() string               { return gim.alias }
+func (gim *GoImportMeta) ImportPath() string          { return gim.importPath }
+func (gim *GoImportMeta) PackageName() string         { return gim.packageName }
+func (gim *GoImportMeta) PackageMeta() *GoPackageMeta { return gim.packageMeta }
+func (gim *GoImportMeta) Pos() token.Position         { return gim.pos }
+func (gim *GoImportMeta) End() token.Position         { return gim.end }
+
+// -------------------------------- unit test --------------------------------
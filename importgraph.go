@@ -0,0 +1,377 @@
+package extractor
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ImportGraph go 项目的 package 导入图
+// - 节点为项目内的 package
+// - 边为 package 内的文件对项目内其他 package 的 import，项目外的 package 不会产生边
+type ImportGraph struct {
+	// 项目的 meta 数据
+	projectMeta *GoProjectMeta
+
+	// 所有节点，按照节点标识排序
+	nodes []*ImportGraphNode
+
+	// - key: 节点标识
+	idMap map[string]*ImportGraphNode
+
+	// - key: 节点的 package 的 meta 数据
+	nodeMap map[*GoPackageMeta]*ImportGraphNode
+
+	// 所有边，按照导入方节点标识以及被导入方节点标识排序
+	edges []*ImportGraphEdge
+}
+
+// ImportGraphNode 导入图的节点
+type ImportGraphNode struct {
+	// 节点标识，package 的导入路径，由模块名称和 package 所在目录组成
+	id string
+
+	// 节点的 package 的 meta 数据
+	packageMeta *GoPackageMeta
+
+	// 导入该节点的边以及该节点发出的边，按照对方节点标识排序
+	in, out []*ImportGraphEdge
+}
+
+// ImportGraphEdge 导入图的边，同一对 package 之间只有一条边
+type ImportGraphEdge struct {
+	from, to *ImportGraphNode
+
+	// 边对应的所有 import 的 meta 数据，按照文件名称以及源码顺序排序
+	importMetas []*GoImportMeta
+}
+
+// GoImportRule 导入规则，用于检查 package 之间的分层依赖
+// - cmd/... -> pkg/...: cmd 及其子 package 允许导入 pkg 及其子 package
+// - pkg/module !-> cmd/...: pkg/module 禁止导入 cmd 及其子 package
+type GoImportRule struct {
+	// 导入方和被导入方的 package 的匹配条件，与 matchPackagePattern 一致
+	// - 匹配完整的导入路径，项目内的 package 同时匹配相对于模块的路径
+	from, to string
+
+	// 允许或者禁止
+	allow bool
+
+	// 规则在规则文件中的行号
+	line int
+}
+
+// -------------------------------- extractor --------------------------------
+
+// ImportGraph 构造项目的 package 导入图
+func (gpm *GoProjectMeta) ImportGraph() *ImportGraph {
+	ig := &ImportGraph{
+		projectMeta: gpm,
+		nodes:       make([]*ImportGraphNode, 0, len(gpm.packageMap)),
+		idMap:       make(map[string]*ImportGraphNode),
+		nodeMap:     make(map[*GoPackageMeta]*ImportGraphNode),
+		edges:       make([]*ImportGraphEdge, 0),
+	}
+	for _, packageMeta := range gpm.packageMap {
+		node := &ImportGraphNode{id: gpm.packageImportPath(packageMeta), packageMeta: packageMeta}
+		ig.nodes = append(ig.nodes, node)
+		ig.idMap[node.id] = node
+		ig.nodeMap[packageMeta] = node
+	}
+	sort.Slice(ig.nodes, func(i, j int) bool { return ig.nodes[i].id < ig.nodes[j].id })
+	for _, from := range ig.nodes {
+		edgeMap := make(map[*ImportGraphNode]*ImportGraphEdge)
+		for _, fileIdent := range sortedKeys(from.packageMeta.fileMetaMap) {
+			for _, gim := range from.packageMeta.fileMetaMap[fileIdent].importMetas {
+				to := ig.nodeMap[gim.packageMeta]
+				if gim.packageMeta == nil || to == nil {
+					continue
+				}
+				edge, has := edgeMap[to]
+				if !has {
+					edge = &ImportGraphEdge{from: from, to: to}
+					edgeMap[to] = edge
+					from.out = append(from.out, edge)
+				}
+				edge.importMetas = append(edge.importMetas, gim)
+			}
+		}
+		sort.Slice(from.out, func(i, j int) bool { return from.out[i].to.id < from.out[j].to.id })
+		for _, edge := range from.out {
+			ig.edges = append(ig.edges, edge)
+			edge.to.in = append(edge.to.in, edge)
+		}
+	}
+	return ig
+}
+
+// SearchNode 根据节点标识搜索节点
+func (ig *ImportGraph) SearchNode(id string) *ImportGraphNode {
+	return ig.idMap[id]
+}
+
+// Imports 获取该节点直接导入的所有节点，按照节点标识排序
+func (ign *ImportGraphNode) Imports() []*ImportGraphNode {
+	nodes := make([]*ImportGraphNode, 0, len(ign.out))
+	for _, edge := range ign.out {
+		nodes = append(nodes, edge.to)
+	}
+	return nodes
+}
+
+// ImportedBy 获取直接导入该节点的所有节点，按照节点标识排序
+func (ign *ImportGraphNode) ImportedBy() []*ImportGraphNode {
+	nodes := make([]*ImportGraphNode, 0, len(ign.in))
+	for _, edge := range ign.in {
+		nodes = append(nodes, edge.from)
+	}
+	return nodes
+}
+
+// Dependencies 获取节点直接以及间接导入的所有节点，不包括节点自身，按照节点标识排序
+func (ig *ImportGraph) Dependencies(id string) []*ImportGraphNode {
+	return ig.closure(id, (*ImportGraphNode).Imports)
+}
+
+// Dependents 获取直接以及间接导入节点的所有节点，不包括节点自身，按照节点标识排序
+func (ig *ImportGraph) Dependents(id string) []*ImportGraphNode {
+	return ig.closure(id, (*ImportGraphNode).ImportedBy)
+}
+
+func (ig *ImportGraph) closure(id string, next func(*ImportGraphNode) []*ImportGraphNode) []*ImportGraphNode {
+	start := ig.idMap[id]
+	if start == nil {
+		return nil
+	}
+	visited := map[*ImportGraphNode]struct{}{start: {}}
+	queue := []*ImportGraphNode{start}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, n := range next(node) {
+			if _, has := visited[n]; !has {
+				visited[n] = struct{}{}
+				queue = append(queue, n)
+			}
+		}
+	}
+	nodes := make([]*ImportGraphNode, 0, len(visited))
+	for _, node := range ig.nodes {
+		if _, has := visited[node]; has && node != start {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// ShortestPath 获取从 from 节点到 to 节点的最短导入路径，包括首尾节点，不可达时返回 nil
+// - 长度相同的路径按照节点标识的顺序选择第一条
+func (ig *ImportGraph) ShortestPath(from, to string) []*ImportGraphNode {
+	start, end := ig.idMap[from], ig.idMap[to]
+	if start == nil || end == nil {
+		return nil
+	}
+	return ig.shortestPath(start, end, nil)
+}
+
+// shortestPath 广度优先搜索最短路径，within 不为 nil 时只经过其中的节点
+func (ig *ImportGraph) shortestPath(start, end *ImportGraphNode, within map[*ImportGraphNode]struct{}) []*ImportGraphNode {
+	if start == end {
+		return []*ImportGraphNode{start}
+	}
+	prev := map[*ImportGraphNode]*ImportGraphNode{start: nil}
+	queue := []*ImportGraphNode{start}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, edge := range node.out {
+			if _, has := prev[edge.to]; has {
+				continue
+			}
+			if _, has := within[edge.to]; within != nil && !has {
+				continue
+			}
+			prev[edge.to] = node
+			if edge.to == end {
+				path := make([]*ImportGraphNode, 0)
+				for n := end; n != nil; n = prev[n] {
+					path = append([]*ImportGraphNode{n}, path...)
+				}
+				return path
+			}
+			queue = append(queue, edge.to)
+		}
+	}
+	return nil
+}
+
+// Cycles 获取导入图中的所有循环导入，每个强连通分量输出一个循环
+// - 循环从分量内标识最小的节点开始，为经过该节点的最短循环，不重复起始节点
+// - 按照起始节点标识排序
+func (ig *ImportGraph) Cycles() [][]*ImportGraphNode {
+	cycles := make([][]*ImportGraphNode, 0)
+	for _, component := range ig.stronglyConnectedComponents() {
+		start := component[0]
+		if len(component) == 1 {
+			// package 不能导入自身，单个节点不构成循环
+			continue
+		}
+		within := make(map[*ImportGraphNode]struct{}, len(component))
+		for _, node := range component {
+			within[node] = struct{}{}
+		}
+		// 从 start 的后继出发回到 start 的最短路径
+		var cycle []*ImportGraphNode
+		for _, edge := range start.out {
+			if _, has := within[edge.to]; !has {
+				continue
+			}
+			if path := ig.shortestPath(edge.to, start, within); path != nil && (cycle == nil || len(path) < len(cycle)) {
+				cycle = append([]*ImportGraphNode{start}, path[:len(path)-1]...)
+			}
+		}
+		cycles = append(cycles, cycle)
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0].id < cycles[j][0].id })
+	return cycles
+}
+
+// stronglyConnectedComponents 使用 Tarjan 算法计算强连通分量，分量内按照节点标识排序
+func (ig *ImportGraph) stronglyConnectedComponents() [][]*ImportGraphNode {
+	var (
+		index      int
+		indexes    = make(map[*ImportGraphNode]int)
+		lowLinks   = make(map[*ImportGraphNode]int)
+		onStack    = make(map[*ImportGraphNode]bool)
+		stack      = make([]*ImportGraphNode, 0)
+		components = make([][]*ImportGraphNode, 0)
+		connect    func(*ImportGraphNode)
+	)
+	connect = func(node *ImportGraphNode) {
+		indexes[node], lowLinks[node] = index, index
+		index++
+		stack = append(stack, node)
+		onStack[node] = true
+		for _, edge := range node.out {
+			if _, visited := indexes[edge.to]; !visited {
+				connect(edge.to)
+				lowLinks[node] = min(lowLinks[node], lowLinks[edge.to])
+			} else if onStack[edge.to] {
+				lowLinks[node] = min(lowLinks[node], indexes[edge.to])
+			}
+		}
+		if lowLinks[node] != indexes[node] {
+			return
+		}
+		component := make([]*ImportGraphNode, 0)
+		for {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[n] = false
+			component = append(component, n)
+			if n == node {
+				break
+			}
+		}
+		sort.Slice(component, func(i, j int) bool { return component[i].id < component[j].id })
+		components = append(components, component)
+	}
+	for _, node := range ig.nodes {
+		if _, visited := indexes[node]; !visited {
+			connect(node)
+		}
+	}
+	return components
+}
+
+// ParseImportRules 解析导入规则，每行一条规则，# 开始的行为注释
+// - <from> -> <to>: 允许导入
+// - <from> !-> <to>: 禁止导入
+func ParseImportRules(content []byte) ([]*GoImportRule, error) {
+	rules := make([]*GoImportRule, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if len(text) == 0 || strings.HasPrefix(text, "#") {
+			continue
+		}
+		rule := &GoImportRule{line: line}
+		fields := strings.Fields(text)
+		if len(fields) != 3 || (fields[1] != "->" && fields[1] != "!->") {
+			return nil, fmt.Errorf("line %v: import rule %q is not in the form '<from> -> <to>' or '<from> !-> <to>'", line, text)
+		}
+		rule.from, rule.to, rule.allow = fields[0], fields[2], fields[1] == "->"
+		rules = append(rules, rule)
+	}
+	return rules, scanner.Err()
+}
+
+// LoadImportRules 通过规则文件的路径读取并解析导入规则
+func LoadImportRules(rulesFilePath string) ([]*GoImportRule, error) {
+	content, err := os.ReadFile(rulesFilePath)
+	if err != nil {
+		return nil, err
+	}
+	return ParseImportRules(content)
+}
+
+// String 输出规则文件中的格式
+func (gir *GoImportRule) String() string {
+	if gir.allow {
+		return fmt.Sprintf("%v -> %v", gir.from, gir.to)
+	}
+	return fmt.Sprintf("%v !-> %v", gir.from, gir.to)
+}
+
+// CheckRules 使用导入规则检查项目内所有 package 的 import
+// - 按照规则的顺序，第一条同时匹配导入方和被导入方的规则决定是否允许
+// - 没有规则匹配时，存在匹配导入方的允许规则则只允许导入规则内的项目内的 package，项目外的 package 不受限制
+// - 违反规则的 import 以 诊断信息 输出，按照 package，文件名称，源码顺序排序
+func (ig *ImportGraph) CheckRules(rules []*GoImportRule) []*GoDiagnostic {
+	diagnostics := make([]*GoDiagnostic, 0)
+	for _, node := range ig.nodes {
+		fromPaths := ig.projectMeta.patternPaths(node.id)
+		restricted := false
+		for _, rule := range rules {
+			restricted = restricted || (rule.allow && matchPackagePatternPaths(rule.from, fromPaths))
+		}
+		for _, fileIdent := range sortedKeys(node.packageMeta.fileMetaMap) {
+			for _, gim := range node.packageMeta.fileMetaMap[fileIdent].importMetas {
+				toPaths := ig.projectMeta.patternPaths(gim.importPath)
+				var matched *GoImportRule
+				for _, rule := range rules {
+					if matchPackagePatternPaths(rule.from, fromPaths) && matchPackagePatternPaths(rule.to, toPaths) {
+						matched = rule
+						break
+					}
+				}
+				switch {
+				case matched != nil && !matched.allow:
+					diagnostics = append(diagnostics, newGoDiagnostic(gim.pos, gim.end, "importrule", "import of %v by %v violates rule '%v' at line %v", gim.importPath, node.id, matched, matched.line))
+				case matched == nil && restricted && gim.packageMeta != nil:
+					diagnostics = append(diagnostics, newGoDiagnostic(gim.pos, gim.end, "importrule", "import of %v by %v is not allowed by any rule", gim.importPath, node.id))
+				}
+			}
+		}
+	}
+	return diagnostics
+}
+
+// -------------------------------- unit test --------------------------------
+
+func (ig *ImportGraph) Nodes() []*ImportGraphNode         { return ig.nodes }
+func (ig *ImportGraph) Edges() []*ImportGraphEdge         { return ig.edges }
+func (ign *ImportGraphNode) ID() string                   { return ign.id }
+func (ign *ImportGraphNode) PackageMeta() *GoPackageMeta  { return ign.packageMeta }
+func (ign *ImportGraphNode) In() []*ImportGraphEdge       { return ign.in }
+func (ign *ImportGraphNode) Out() []*ImportGraphEdge      { return ign.out }
+func (ige *ImportGraphEdge) From() *ImportGraphNode       { return ige.from }
+func (ige *ImportGraphEdge) To() *ImportGraphNode         { return ige.to }
+func (ige *ImportGraphEdge) ImportMetas() []*GoImportMeta { return ige.importMetas }
+func (gir *GoImportRule) From() string                    { return gir.from }
+func (gir *GoImportRule) To() string                      { return gir.to }
+func (gir *GoImportRule) IsAllow() bool                   { return gir.allow }
+func (gir *GoImportRule) Line() int                       { return gir.line }
@@ -19,7 +19,11 @@ type GoInterfaceMeta struct {
 	// - key: method 标识
 	methodMetaMap map[string]*GoInterfaceMethodMeta
 
-	commentGroup *ast.CommentGroup
+	// interface 内嵌入的类型的类型表达式: interface{ io.Reader; Closer }
+	embeds []*GoTypeExpr
+
+	// interface 的文档注释和行尾注释
+	commentMeta
 }
 
 // newGoInterfaceMeta 通过 ast 构造 interface 的 meta
@@ -28,6 +32,7 @@ func newGoInterfaceMeta(m *meta, ident string, stopExtract ...bool) *GoInterface
 		meta:          m,
 		ident:         ident,
 		methodMetaMap: make(map[string]*GoInterfaceMethodMeta),
+		commentMeta:   newCommentMeta(m.node),
 	}
 	if len(stopExtract) == 0 {
 		gim.ExtractAll()
@@ -69,8 +74,10 @@ func (gim *GoInterfaceMeta) ExtractAll() {
 		if IsInterfaceMethodNode(method) {
 			for _, name := range method.Names {
 				methodIdent := name.String()
-				gim.methodMetaMap[methodIdent] = newGoInterfaceMethodMeta(newMeta(method, gim.path), methodIdent, gim)
+				gim.methodMetaMap[methodIdent] = newGoInterfaceMethodMeta(gim.copyMeta(method), methodIdent, gim)
 			}
+		} else if len(method.Names) == 0 {
+			gim.embeds = append(gim.embeds, newGoTypeExpr(gim.copyMeta(method.Type)))
 		}
 	}
 }
@@ -101,6 +108,38 @@ func (gim *GoInterfaceMeta) SearchMethodMeta(methodIdent string) *GoInterfaceMet
 	return gim.methodMetaMap[methodIdent]
 }
 
+// methodIdents interface 的 method 集合，包括嵌入的 interface 的 method
+// - 嵌入的项目内的 interface 递归展开，嵌入的 error 包含 Error
+// - 无法解析的嵌入类型被忽略
+func (gim *GoInterfaceMeta) methodIdents() map[string]struct{} {
+	methodIdents := make(map[string]struct{})
+	gim.collectMethodIdents(methodIdents, make(map[*GoInterfaceMeta]struct{}))
+	return methodIdents
+}
+
+func (gim *GoInterfaceMeta) collectMethodIdents(methodIdents map[string]struct{}, visited map[*GoInterfaceMeta]struct{}) {
+	if _, has := visited[gim]; has {
+		return
+	}
+	visited[gim] = struct{}{}
+	for methodIdent := range gim.methodMetaMap {
+		methodIdents[methodIdent] = struct{}{}
+	}
+	for _, embed := range gim.embeds {
+		switch {
+		case embed.interfaceMeta != nil:
+			embed.interfaceMeta.collectMethodIdents(methodIdents, visited)
+		case embed.IsBuiltin() && embed.ident == "error":
+			methodIdents["Error"] = struct{}{}
+		}
+	}
+}
+
+// Directives 获取 interface 的文档注释内的 编译指令
+func (gim *GoInterfaceMeta) Directives() []*GoDirectiveMeta {
+	return extractDirectiveMetas(gim.meta, gim.doc)
+}
+
 // func SearchGoInterfaceMeta(gfm *GoFileMeta, interfaceName string) *GoInterfaceMeta {
 // 	var interfaceDecl *ast.TypeSpec
 // 	var commentDecl *ast.CommentGroup
@@ -140,17 +179,6 @@ func (gim *GoInterfaceMeta) MethodMetaMap() map[string]*GoInterfaceMethodMeta {
 
 // -------------------------------- unit test --------------------------------
 
-func (gim *GoInterfaceMeta) Doc() []string {
-	if gim.node == nil || gim.commentGroup == nil || len(gim.commentGroup.List) == 0 {
-		return nil
-	}
-	commentSlice := make([]string, 0, len(gim.commentGroup.List))
-	for _, comment := range gim.commentGroup.List {
-		commentSlice = append(commentSlice, comment.Text)
-	}
-	return commentSlice
-}
-
 // // SearchMethodDecl search method decl from node.(*ast.InterfaceType)
 // func (gim *GoInterfaceMeta) SearchMethodDecl(methodName string) *GoInterfaceMethodMeta {
 // 	gim.ForeachMethodDecl(func(f *ast.Field) bool {
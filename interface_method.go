@@ -26,11 +26,14 @@ type GoInterfaceMethodMeta struct {
 
 	// func 模板参数
 	typeParams []*GoVarMeta
+
+	// method 的文档注释和行尾注释
+	commentMeta
 }
 
 // newGoInterfaceMethodMeta 通过 ast 构造 interface 的 method 的 meta 数据
 func newGoInterfaceMethodMeta(m *meta, ident string, gim *GoInterfaceMeta, stopExtract ...bool) *GoInterfaceMethodMeta {
-	gimm := &GoInterfaceMethodMeta{meta: m, ident: ident, interfaceMeta: gim}
+	gimm := &GoInterfaceMethodMeta{meta: m, ident: ident, interfaceMeta: gim, commentMeta: newCommentMeta(m.node)}
 	if len(stopExtract) == 0 {
 		gimm.ExtractAll()
 	}
@@ -87,44 +90,18 @@ func (gimm *GoInterfaceMethodMeta) ExtractAll() {
 
 func (gimm *GoInterfaceMethodMeta) extractParams() {
 	funcType := gimm.funcType()
-	if funcType == nil || funcType.Params == nil || len(funcType.Params.List) == 0 {
+	if funcType == nil {
 		return
 	}
-
-	pLen := len(funcType.Params.List)
-	gimm.params = make([]*GoVarMeta, 0, pLen)
-	for _, field := range funcType.Params.List {
-		if len(field.Names) > 0 {
-			// 定义参数名称的方法
-			for _, name := range field.Names {
-				gimm.params = append(gimm.params, newGoVarMeta(newMeta(field, gimm.path), name.String()))
-			}
-		} else {
-			// 未定义参数名称的方法
-			gimm.params = append(gimm.params, newGoVarMeta(newMeta(field, gimm.path), ""))
-		}
-	}
+	gimm.params = extractFieldListVarMeta(gimm.meta, funcType.Params)
 }
 
 func (gimm *GoInterfaceMethodMeta) extractReturns() {
 	funcType := gimm.funcType()
-	if funcType == nil || funcType.Results == nil || len(funcType.Results.List) == 0 {
+	if funcType == nil {
 		return
 	}
-
-	rLen := len(funcType.Results.List)
-	gimm.returns = make([]*GoVarMeta, 0, rLen)
-	for _, field := range funcType.Results.List {
-		if len(field.Names) > 0 {
-			// 定义返回值名称的方法
-			for _, name := range field.Names {
-				gimm.returns = append(gimm.returns, newGoVarMeta(newMeta(field, gimm.path), name.String()))
-			}
-		} else {
-			// 未定义返回值名称的方法
-			gimm.returns = append(gimm.returns, newGoVarMeta(newMeta(field, gimm.path), ""))
-		}
-	}
+	gimm.returns = extractFieldListVarMeta(gimm.meta, funcType.Results)
 }
 
 func (gimm *GoInterfaceMethodMeta) extractTypeParams() {
@@ -138,6 +115,11 @@ func (gimm *GoInterfaceMethodMeta) Ident() string         { return gimm.ident }
 func (gimm *GoInterfaceMethodMeta) Params() []*GoVarMeta  { return gimm.params }
 func (gimm *GoInterfaceMethodMeta) Returns() []*GoVarMeta { return gimm.returns }
 
+// IsVariadic 最后一个参数是否是可变参数
+func (gimm *GoInterfaceMethodMeta) IsVariadic() bool {
+	return len(gimm.params) > 0 && gimm.params[len(gimm.params)-1].isVariadic
+}
+
 // -------------------------------- unit test --------------------------------
 
 // func (gimm *GoInterfaceMethodMeta) FunctionName() string {
@@ -16,6 +16,12 @@ func IsVarNode(n ast.Node) bool {
 	return ok && genDecl.Tok == token.VAR
 }
 
+// IsConstNode 判断 ast.Node 是否是 const 关键字定义域
+func IsConstNode(n ast.Node) bool {
+	genDecl, ok := n.(*ast.GenDecl)
+	return ok && genDecl.Tok == token.CONST
+}
+
 func IsFuncNode(n ast.Node) bool {
 	funcDecl, ok := n.(*ast.FuncDecl)
 	return ok && funcDecl.Recv == nil
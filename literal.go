@@ -0,0 +1,278 @@
+package extractor
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type StringLiteralDeclType int
+
+const (
+	STRING_LITERAL_DECL_FUNC   = iota + 1 // 位于 func 内
+	STRING_LITERAL_DECL_METHOD            // 位于 method 内
+	STRING_LITERAL_DECL_CONST             // 位于 package 级 const 声明内
+	STRING_LITERAL_DECL_VAR               // 位于 package 级 var 声明内
+)
+
+// GoStringLiteralMeta go 字符串字面量 的 meta 数据，不包括 import 路径和 struct 标签
+// - fmt.Errorf("not found: %v", id) -> value: not found: %v, decl: F, callMeta: fmt.Errorf, argIndex: 0
+type GoStringLiteralMeta struct {
+	// 组合基本 meta 数据
+	// ast 节点，要求为 Kind 为 token.STRING 的 *ast.BasicLit
+	// 以 ast 节点 为单位执行 AST/PrintAST/Expression/Format
+	*meta
+
+	// 字面量所属的 package 的 meta 数据
+	packageMeta *GoPackageMeta
+
+	// 字面量所属的 package 的导入路径，项目内提取时由模块名称和 package 所在目录组成
+	importPath string
+
+	// 去除引号并处理转义后的值
+	value string
+
+	// 是否是 `` 包围的原始字符串
+	raw bool
+
+	// 所在的声明的种类
+	declType StringLiteralDeclType
+
+	// 所在的声明的标识: F，(*T).M，T.M，package 级 const/var 的标识
+	decl string
+
+	// 字面量在所在的声明内的序号，从 0 开始
+	declIndex int
+
+	// 是否位于 const 声明内，包括局部 const
+	isConst bool
+
+	// 字面量直接作为参数时所在的调用
+	callMeta *GoCallMeta
+
+	// 字面量直接作为参数时的参数序号，否则为 -1
+	argIndex int
+}
+
+// StringLiteralFilter 字符串字面量的过滤器，返回 true 时保留
+type StringLiteralFilter func(*GoStringLiteralMeta) bool
+
+// -------------------------------- extractor --------------------------------
+
+// StringLiterals 提取项目内所有满足过滤器的字符串字面量，filter 为 nil 时提取所有字符串字面量
+// - 按照 package 的导入路径，文件名称，源码顺序排序
+func (gpm *GoProjectMeta) StringLiterals(filter StringLiteralFilter) []*GoStringLiteralMeta {
+	literals := make([]*GoStringLiteralMeta, 0)
+	for _, packageMeta := range gpm.Packages() {
+		importPath := gpm.packageImportPath(packageMeta)
+		for _, gslm := range packageMeta.StringLiterals() {
+			gslm.importPath = importPath
+			if filter == nil || filter(gslm) {
+				literals = append(literals, gslm)
+			}
+		}
+	}
+	return literals
+}
+
+// StringLiterals 提取 package 内所有字符串字面量，按照文件名称，源码顺序排序
+func (gpm *GoPackageMeta) StringLiterals() []*GoStringLiteralMeta {
+	literals := make([]*GoStringLiteralMeta, 0)
+	declIndexes := make(map[string]int)
+	for _, gfm := range gpm.Files() {
+		fileNode, ok := gfm.node.(*ast.File)
+		if !ok {
+			continue
+		}
+		for _, decl := range fileNode.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				var declType StringLiteralDeclType = STRING_LITERAL_DECL_FUNC
+				declIdent := d.Name.Name
+				if d.Recv != nil {
+					declType = STRING_LITERAL_DECL_METHOD
+					receiverIdent, pointerReceiver := extractMethodRecvStruct(d)
+					if pointerReceiver {
+						receiverIdent = fmt.Sprintf("(*%v)", receiverIdent)
+					}
+					declIdent = fmt.Sprintf("%v.%v", receiverIdent, d.Name.Name)
+				}
+				if d.Body == nil {
+					continue
+				}
+				ctx := newCallContext(gpm, gfm.path, newScopeInfo(d))
+				literals = gpm.appendStringLiterals(literals, declIndexes, gfm.copyMeta(d.Body), ctx, declType, declIdent, false)
+			case *ast.GenDecl:
+				if d.Tok != token.CONST && d.Tok != token.VAR {
+					continue
+				}
+				var declType StringLiteralDeclType = STRING_LITERAL_DECL_VAR
+				if d.Tok == token.CONST {
+					declType = STRING_LITERAL_DECL_CONST
+				}
+				ctx := newCallContext(gpm, gfm.path, nil)
+				for _, spec := range d.Specs {
+					valueSpec := spec.(*ast.ValueSpec)
+					for index, value := range valueSpec.Values {
+						declIdent := valueSpec.Names[0].Name
+						if len(valueSpec.Names) == len(valueSpec.Values) {
+							declIdent = valueSpec.Names[index].Name
+						}
+						literals = gpm.appendStringLiterals(literals, declIndexes, gfm.copyMeta(value), ctx, declType, declIdent, d.Tok == token.CONST)
+					}
+				}
+			}
+		}
+	}
+	return literals
+}
+
+// appendStringLiterals 提取声明内的字符串字面量
+// - declIndexes 记录每个声明内已提取的字面量数量
+func (gpm *GoPackageMeta) appendStringLiterals(literals []*GoStringLiteralMeta, declIndexes map[string]int, m *meta, ctx *callContext, declType StringLiteralDeclType, declIdent string, isConst bool) []*GoStringLiteralMeta {
+	constDecls := make([]*ast.GenDecl, 0)
+	stack := make([]ast.Node, 0)
+	ast.Inspect(m.node, func(n ast.Node) bool {
+		if n == nil {
+			if genDecl, ok := stack[len(stack)-1].(*ast.GenDecl); ok && genDecl.Tok == token.CONST {
+				constDecls = constDecls[:len(constDecls)-1]
+			}
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		switch node := n.(type) {
+		case *ast.GenDecl:
+			if node.Tok == token.CONST {
+				constDecls = append(constDecls, node)
+			}
+		case *ast.BasicLit:
+			if node.Kind != token.STRING || isFieldTag(stack, node) {
+				break
+			}
+			value, err := strconv.Unquote(node.Value)
+			if err != nil {
+				break
+			}
+			gslm := &GoStringLiteralMeta{
+				meta:        m.copyMeta(node),
+				packageMeta: gpm,
+				importPath:  gpm.importPath,
+				value:       value,
+				raw:         strings.HasPrefix(node.Value, "`"),
+				declType:    declType,
+				decl:        declIdent,
+				declIndex:   declIndexes[declIdent],
+				isConst:     isConst || len(constDecls) > 0,
+				argIndex:    -1,
+			}
+			declIndexes[declIdent]++
+			gslm.callMeta, gslm.argIndex = literalCallContext(stack, node, m, ctx)
+			literals = append(literals, gslm)
+		}
+		stack = append(stack, n)
+		return true
+	})
+	return literals
+}
+
+// isFieldTag 字面量是否是 struct 的字段标签
+func isFieldTag(stack []ast.Node, lit *ast.BasicLit) bool {
+	if len(stack) == 0 {
+		return false
+	}
+	field, ok := stack[len(stack)-1].(*ast.Field)
+	return ok && field.Tag == lit
+}
+
+// literalCallContext 字面量直接作为参数时所在的调用以及参数序号，括号不影响判断
+func literalCallContext(stack []ast.Node, lit *ast.BasicLit, m *meta, ctx *callContext) (*GoCallMeta, int) {
+	var child ast.Node = lit
+	for index := len(stack) - 1; index >= 0; index-- {
+		switch parent := stack[index].(type) {
+		case *ast.ParenExpr:
+			child = parent
+			continue
+		case *ast.CallExpr:
+			for argIndex, arg := range parent.Args {
+				if arg == child {
+					return newGoCallMeta(m.copyMeta(parent), ctx), argIndex
+				}
+			}
+		}
+		break
+	}
+	return nil, -1
+}
+
+// CallContext 字面量直接作为参数时所在调用的被调用标识: fmt.Errorf，log.Printf，T
+func (gslm *GoStringLiteralMeta) CallContext() string {
+	if gslm.callMeta == nil {
+		return ""
+	}
+	if gslm.callMeta.IsSelector() {
+		return fmt.Sprintf("%v.%v", gslm.callMeta.from, gslm.callMeta.callee)
+	}
+	return gslm.callMeta.callee
+}
+
+// Key 字面量在翻译表中的唯一标识: importPath.decl#declIndex
+// - 项目内提取时 importPath 不受 package 名称以及文件的遍历顺序影响
+// - declIndex 为字面量在声明内的序号，在声明内插入或删除字面量后，其后的字面量的 key 随之改变
+func (gslm *GoStringLiteralMeta) Key() string {
+	packageIdent := gslm.importPath
+	if len(packageIdent) == 0 {
+		packageIdent = gslm.packageMeta.ident
+	}
+	return fmt.Sprintf("%v.%v#%v", packageIdent, gslm.decl, gslm.declIndex)
+}
+
+// StringLiteralTable 将字符串字面量导出为 csv 格式的翻译表
+// - 列: key，file，line，column，context，source，translation
+// - file 为相对于项目的路径，translation 为空待填写
+func (gpm *GoProjectMeta) StringLiteralTable(literals []*GoStringLiteralMeta) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	writer := csv.NewWriter(buffer)
+	if err := writer.Write([]string{"key", "file", "line", "column", "context", "source", "translation"}); err != nil {
+		return nil, err
+	}
+	for _, gslm := range literals {
+		position := gslm.Pos()
+		file, err := filepath.Rel(gpm.absolutePath, position.Filename)
+		if err != nil {
+			file = position.Filename
+		}
+		record := []string{
+			gslm.Key(),
+			filepath.ToSlash(file),
+			strconv.Itoa(position.Line),
+			strconv.Itoa(position.Column),
+			gslm.CallContext(),
+			gslm.value,
+			"",
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buffer.Bytes(), writer.Error()
+}
+
+// -------------------------------- unit test --------------------------------
+
+func (gslm *GoStringLiteralMeta) PackageMeta() *GoPackageMeta     { return gslm.packageMeta }
+func (gslm *GoStringLiteralMeta) Value() string                   { return gslm.value }
+func (gslm *GoStringLiteralMeta) IsRaw() bool                     { return gslm.raw }
+func (gslm *GoStringLiteralMeta) DeclType() StringLiteralDeclType { return gslm.declType }
+func (gslm *GoStringLiteralMeta) Decl() string                    { return gslm.decl }
+func (gslm *GoStringLiteralMeta) IsConst() bool                   { return gslm.isConst }
+func (gslm *GoStringLiteralMeta) CallMeta() *GoCallMeta           { return gslm.callMeta }
+func (gslm *GoStringLiteralMeta) ArgIndex() int                   { return gslm.argIndex }
+func (gslm *GoStringLiteralMeta) Pos() token.Position {
+	return gslm.position(gslm.node.Pos())
+}
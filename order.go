@@ -0,0 +1,101 @@
+package extractor
+
+import (
+	"go/ast"
+	"go/token"
+	"sort"
+)
+
+// declaredMeta 具有声明位置的 meta 数据
+type declaredMeta interface {
+	Ident() string
+	declPos(ident string) (string, token.Pos)
+}
+
+// -------------------------------- extractor --------------------------------
+
+// declPos 获取标识在 meta 的 ast 节点中的声明位置
+// - 同一个 ast 节点声明的多个标识按照标识的位置区分: var a, b int，A, B int
+// - 匿名成员等节点中不存在的标识使用 ast 节点的位置
+func (m *meta) declPos(ident string) (string, token.Pos) {
+	var names []*ast.Ident
+	switch node := m.node.(type) {
+	case *ast.ValueSpec:
+		names = node.Names
+	case *ast.Field:
+		names = node.Names
+	}
+	for _, name := range names {
+		if name.Name == ident {
+			return m.path, name.Pos()
+		}
+	}
+	return m.path, m.node.Pos()
+}
+
+// sortedByDecl 获取 map 的所有 value 并按照声明的文件路径，在文件中的位置排序
+func sortedByDecl[V declaredMeta](m map[string]V) []V {
+	metas := make([]V, 0, len(m))
+	for _, v := range m {
+		metas = append(metas, v)
+	}
+	sort.Slice(metas, func(i, j int) bool {
+		iPath, iPos := metas[i].declPos(metas[i].Ident())
+		jPath, jPos := metas[j].declPos(metas[j].Ident())
+		if iPath != jPath {
+			return iPath < jPath
+		}
+		if iPos != jPos {
+			return iPos < jPos
+		}
+		return metas[i].Ident() < metas[j].Ident()
+	})
+	return metas
+}
+
+// Packages 获取项目内所有 package 的 meta 数据，按照导入路径排序
+func (gpm *GoProjectMeta) Packages() []*GoPackageMeta {
+	packageMetas := make([]*GoPackageMeta, 0, len(gpm.packageMap))
+	for _, packageKey := range sortedKeys(gpm.packageMap) {
+		packageMetas = append(packageMetas, gpm.packageMap[packageKey])
+	}
+	return packageMetas
+}
+
+// Files 获取 package 内所有文件的 meta 数据，按照文件名称排序
+func (gpm *GoPackageMeta) Files() []*GoFileMeta {
+	fileMetas := make([]*GoFileMeta, 0, len(gpm.fileMetaMap))
+	for _, fileName := range gpm.sortedFileNames() {
+		fileMetas = append(fileMetas, gpm.fileMetaMap[fileName])
+	}
+	return fileMetas
+}
+
+// Vars 获取 package 内所有 var 的 meta 数据，按照文件名称，声明顺序排序
+func (gpm *GoPackageMeta) Vars() []*GoVarMeta { return sortedByDecl(gpm.varMetaMap) }
+
+// Consts 获取 package 内所有 const 的 meta 数据，按照文件名称，声明顺序排序
+func (gpm *GoPackageMeta) Consts() []*GoVarMeta { return sortedByDecl(gpm.constMetaMap) }
+
+// Funcs 获取 package 内所有 func 的 meta 数据，按照文件名称，声明顺序排序
+func (gpm *GoPackageMeta) Funcs() []*GoFuncMeta { return sortedByDecl(gpm.funcMetaMap) }
+
+// Structs 获取 package 内所有 struct 的 meta 数据，按照文件名称，声明顺序排序
+func (gpm *GoPackageMeta) Structs() []*GoStructMeta { return sortedByDecl(gpm.structMetaMap) }
+
+// Interfaces 获取 package 内所有 interface 的 meta 数据，按照文件名称，声明顺序排序
+func (gpm *GoPackageMeta) Interfaces() []*GoInterfaceMeta {
+	return sortedByDecl(gpm.interfaceMetaMap)
+}
+
+// Members 获取 struct 内所有 member 的 meta 数据，按照声明顺序排序
+// - 即 struct 的内存布局顺序，匿名成员位于其声明的位置
+func (gsm *GoStructMeta) Members() []*GoVarMeta { return sortedByDecl(gsm.memberMetaMap) }
+
+// Methods 获取 struct 的所有 method 的 meta 数据，按照文件名称，声明顺序排序
+func (gsm *GoStructMeta) Methods() []*GoMethodMeta { return sortedByDecl(gsm.methodMetaMap) }
+
+// Methods 获取 interface 内所有 method 的 meta 数据，按照声明顺序排序
+func (gim *GoInterfaceMeta) Methods() []*GoInterfaceMethodMeta {
+	return sortedByDecl(gim.methodMetaMap)
+}
@@ -3,8 +3,10 @@ package extractor
 import (
 	"fmt"
 	"go/ast"
+	"go/doc/comment"
 	"os"
 	"path/filepath"
+	"sort"
 )
 
 // GoPackageMeta go package 的 meta 数据
@@ -32,6 +34,10 @@ type GoPackageMeta struct {
 	// - key: var 标识
 	varMetaMap map[string]*GoVarMeta
 
+	// package 内所有 const 的 meta 数据
+	// - key: const 标识
+	constMetaMap map[string]*GoVarMeta
+
 	// package 内所有 func 的 meta 数据
 	// - key: func 标识
 	funcMetaMap map[string]*GoFuncMeta
@@ -57,6 +63,7 @@ func newGoPackageMeta(ident, absolutePath, importPath string) *GoPackageMeta {
 		importPath:       importPath,
 		fileMetaMap:      make(map[string]*GoFileMeta),
 		varMetaMap:       make(map[string]*GoVarMeta),
+		constMetaMap:     make(map[string]*GoVarMeta),
 		funcMetaMap:      make(map[string]*GoFuncMeta),
 		structMetaMap:    make(map[string]*GoStructMeta),
 		interfaceMetaMap: make(map[string]*GoInterfaceMeta),
@@ -112,6 +119,7 @@ func extractGoPackageMeta(packageRelativePath string, files map[string]struct{},
 		absolutePath:     packagePathAbs,
 		fileMetaMap:      make(map[string]*GoFileMeta),
 		varMetaMap:       make(map[string]*GoVarMeta),
+		constMetaMap:     make(map[string]*GoVarMeta),
 		structMetaMap:    make(map[string]*GoStructMeta),
 		interfaceMetaMap: make(map[string]*GoInterfaceMeta),
 		funcMetaMap:      make(map[string]*GoFuncMeta),
@@ -156,7 +164,7 @@ func extractGoPackageMeta(packageRelativePath string, files map[string]struct{},
 	return packageMeta, nil
 }
 
-// ExtractAll 提取 package 内所有 var，func，struct，interface 的 meta 数据
+// ExtractAll 提取 package 内所有 var，const，func，struct，interface 的 meta 数据
 func (gpm *GoPackageMeta) ExtractAll() {
 	if gpm.extractedAll {
 		return
@@ -165,6 +173,9 @@ func (gpm *GoPackageMeta) ExtractAll() {
 	// 提取 var
 	gpm.extractVar()
 
+	// 提取 const
+	gpm.extractConst()
+
 	// 提取 func
 	gpm.extractFunc()
 
@@ -177,6 +188,9 @@ func (gpm *GoPackageMeta) ExtractAll() {
 	// 提取 interface
 	gpm.extractInterface()
 
+	// 解析类型表达式的包限定符
+	gpm.resolveTypeExprs(nil)
+
 	gpm.extractedAll = true
 }
 
@@ -191,12 +205,41 @@ func (gpm *GoPackageMeta) extractVar() {
 						valueSpec, ok := specNode.(*ast.ValueSpec)
 						if valueSpec != nil && ok {
 							for _, ident := range valueSpec.Names {
-								gpm.varMetaMap[ident.Name] = newGoVarMeta(newMeta(valueSpec, gfm.path), ident.Name)
+								gvm := newGoVarMeta(gfm.copyMeta(valueSpec), ident.Name)
+								gvm.inheritDoc(n.(*ast.GenDecl))
+								gpm.varMetaMap[ident.Name] = gvm
 							}
 						}
 					}
 					return false // 只查找顶层为 var 的节点
-				case IsImportNode(n) || IsFuncNode(n) || IsTypeNode(n) || IsMethodNode(n):
+				case IsImportNode(n) || IsConstNode(n) || IsFuncNode(n) || IsTypeNode(n) || IsMethodNode(n):
+					return false // 顶层为其他节点直接跳过
+				}
+				return true
+			})
+		}
+	}
+}
+
+// extractConst 提取 const 的 meta 数据
+func (gpm *GoPackageMeta) extractConst() {
+	for _, gfm := range gpm.fileMetaMap {
+		if gfm.node != nil {
+			ast.Inspect(gfm.node, func(n ast.Node) bool {
+				switch {
+				case IsConstNode(n):
+					for _, specNode := range n.(*ast.GenDecl).Specs {
+						valueSpec, ok := specNode.(*ast.ValueSpec)
+						if valueSpec != nil && ok {
+							for _, ident := range valueSpec.Names {
+								gvm := newGoVarMeta(gfm.copyMeta(valueSpec), ident.Name)
+								gvm.inheritDoc(n.(*ast.GenDecl))
+								gpm.constMetaMap[ident.Name] = gvm
+							}
+						}
+					}
+					return false // 只查找顶层为 const 的节点
+				case IsImportNode(n) || IsVarNode(n) || IsFuncNode(n) || IsTypeNode(n) || IsMethodNode(n):
 					return false // 顶层为其他节点直接跳过
 				}
 				return true
@@ -214,7 +257,9 @@ func (gpm *GoPackageMeta) extractFunc() {
 				case IsFuncNode(n):
 					funcDecl := n.(*ast.FuncDecl)
 					funcIdent := funcDecl.Name.String()
-					gpm.funcMetaMap[funcIdent] = newGoFuncMeta(newMeta(funcDecl, gfm.path), funcIdent)
+					goFuncMeta := newGoFuncMeta(gfm.copyMeta(funcDecl), funcIdent)
+					goFuncMeta.packageMeta = gpm
+					gpm.funcMetaMap[funcIdent] = goFuncMeta
 					return false // 只查找顶层为 func 的节点
 				case IsImportNode(n) || IsVarNode(n) || IsTypeNode(n) || IsMethodNode(n):
 					return false // 顶层为其他节点直接跳过
@@ -235,7 +280,9 @@ func (gpm *GoPackageMeta) extractStruct() {
 						if IsStructNode(specNode) {
 							typeSpec := specNode.(*ast.TypeSpec)
 							structIdent := typeSpec.Name.String()
-							gpm.structMetaMap[structIdent] = newGoStructMeta(newMeta(typeSpec, gfm.path), structIdent)
+							gsm := newGoStructMeta(gfm.copyMeta(typeSpec), structIdent)
+							gsm.inheritDoc(n.(*ast.GenDecl))
+							gpm.structMetaMap[structIdent] = gsm
 						}
 					}
 					return false // 只查找顶层为 struct 的节点
@@ -255,7 +302,8 @@ func (gpm *GoPackageMeta) extractMethod() {
 				case IsMethodNode(n):
 					funcDecl := n.(*ast.FuncDecl)
 					funcIdent := funcDecl.Name.String()
-					gmm := newGoMethodMeta(newMeta(funcDecl, gfm.path), funcIdent)
+					gmm := newGoMethodMeta(gfm.copyMeta(funcDecl), funcIdent)
+					gmm.packageMeta = gpm
 					gsm, has := gpm.structMetaMap[gmm.Receiver().TypeIdent()]
 					if gsm != nil && has {
 						gsm.methodMetaMap[funcIdent] = gmm
@@ -280,7 +328,9 @@ func (gpm *GoPackageMeta) extractInterface() {
 						if IsInterfaceNode(specNode) && !IsTypeConstraintsNode(specNode) {
 							typeSpec := specNode.(*ast.TypeSpec)
 							interfaceIdent := typeSpec.Name.String()
-							gpm.interfaceMetaMap[interfaceIdent] = newGoInterfaceMeta(newMeta(typeSpec, gfm.path), interfaceIdent)
+							gim := newGoInterfaceMeta(gfm.copyMeta(typeSpec), interfaceIdent)
+							gim.inheritDoc(n.(*ast.GenDecl))
+							gpm.interfaceMetaMap[interfaceIdent] = gim
 						}
 					}
 					return false // 只查找顶层为 interface 的节点
@@ -291,6 +341,123 @@ func (gpm *GoPackageMeta) extractInterface() {
 	}
 }
 
+// resolveTypeExprs 根据所属文件的 import 解析 package 内所有类型表达式的包限定符
+// - searchPackageMeta 根据导入路径搜索项目内 package 的 meta 数据，为 nil 时仅解析导入路径
+func (gpm *GoPackageMeta) resolveTypeExprs(searchPackageMeta func(string) *GoPackageMeta) {
+	if searchPackageMeta == nil {
+		searchPackageMeta = func(string) *GoPackageMeta { return nil }
+	}
+	typeIdents := gpm.typeIdents()
+	resolveTypeExpr := func(gfm *GoFileMeta, typeExpr *GoTypeExpr) {
+		typeExpr.foreach(func(gte *GoTypeExpr) {
+			if !gte.IsNamed() {
+				return
+			}
+			// 包限定类型：pkg.T
+			if len(gte.from) > 0 {
+				if gim := gfm.SearchImport(gte.from); gim != nil {
+					gte.resolve(gim.importPath, searchPackageMeta(gim.importPath))
+				}
+				return
+			}
+			// 当前 package 内的类型：T，包括遮蔽了内置类型的同名类型
+			if _, has := typeIdents[gte.ident]; has {
+				gte.resolve(gpm.importPath, gpm)
+				return
+			}
+			if gte.IsBuiltin() {
+				return
+			}
+			// . 导入的 package 内的类型：T
+			for _, gim := range gfm.dotImports() {
+				dotPackageMeta := searchPackageMeta(gim.importPath)
+				if dotPackageMeta == nil {
+					continue
+				}
+				if _, has := dotPackageMeta.typeIdents()[gte.ident]; has {
+					gte.resolve(gim.importPath, dotPackageMeta)
+					return
+				}
+			}
+		})
+	}
+	gpm.foreachVarMeta(func(gvm *GoVarMeta) {
+		gfm := gpm.fileMetaMap[filepath.Base(gvm.path)]
+		if gfm == nil {
+			return
+		}
+		resolveTypeExpr(gfm, gvm.typeExpr)
+		// 解析到项目内的命名类型时，是否是 interface 取决于解析到的类型
+		if gvm.typeExpr != nil && gvm.typeExpr.packageMeta != nil {
+			gvm.isInterface = gvm.typeExpr.interfaceMeta != nil
+		}
+	})
+	// interface 嵌入的类型
+	for _, gim := range gpm.Interfaces() {
+		if gfm := gpm.fileMetaMap[filepath.Base(gim.path)]; gfm != nil {
+			for _, embed := range gim.embeds {
+				resolveTypeExpr(gfm, embed)
+			}
+		}
+	}
+}
+
+// foreachVarMeta 按照声明顺序遍历 package 内所有 var 的 meta 数据
+// - package 的 var，const
+// - func 的 params，returns
+// - struct 的 member，method 的 receiver，params，returns
+// - interface 的 method 的 params，returns
+func (gpm *GoPackageMeta) foreachVarMeta(f func(*GoVarMeta)) {
+	for _, gvm := range gpm.Vars() {
+		f(gvm)
+	}
+	for _, gvm := range gpm.Consts() {
+		f(gvm)
+	}
+	for _, gfm := range gpm.Funcs() {
+		gfm.foreachVarMeta(f)
+	}
+	for _, gsm := range gpm.Structs() {
+		for _, gvm := range gsm.Members() {
+			f(gvm)
+		}
+		for _, gmm := range gsm.Methods() {
+			f(gmm.receiver)
+			gmm.foreachVarMeta(f)
+		}
+	}
+	for _, gim := range gpm.Interfaces() {
+		for _, gimm := range gim.Methods() {
+			for _, gvm := range gimm.params {
+				f(gvm)
+			}
+			for _, gvm := range gimm.returns {
+				f(gvm)
+			}
+		}
+	}
+}
+
+// typeIdents 获取 package 内所有顶层 type 的标识
+func (gpm *GoPackageMeta) typeIdents() map[string]struct{} {
+	typeIdents := make(map[string]struct{})
+	for _, gfm := range gpm.fileMetaMap {
+		fileAST, ok := gfm.node.(*ast.File)
+		if !ok {
+			continue
+		}
+		for _, decl := range fileAST.Decls {
+			if !IsTypeNode(decl) {
+				continue
+			}
+			for _, specNode := range decl.(*ast.GenDecl).Specs {
+				typeIdents[specNode.(*ast.TypeSpec).Name.Name] = struct{}{}
+			}
+		}
+	}
+	return typeIdents
+}
+
 // -------------------------------- extractor --------------------------------
 
 // SearchFileMeta 根据 文件名 搜索 文件 的 meta 数据
@@ -303,6 +470,11 @@ func (gpm *GoPackageMeta) SearchVarMeta(varIdent string) *GoVarMeta {
 	return gpm.varMetaMap[varIdent]
 }
 
+// SearchConstMeta 根据 const 名称 搜索 const 的 meta 数据
+func (gpm *GoPackageMeta) SearchConstMeta(constIdent string) *GoVarMeta {
+	return gpm.constMetaMap[constIdent]
+}
+
 func (gpm *GoPackageMeta) SearchFuncMeta(funcIdent string) *GoFuncMeta {
 	return gpm.funcMetaMap[funcIdent]
 }
@@ -317,7 +489,7 @@ func (gpm *GoPackageMeta) SearchInterfaceMeta(interfaceIdent string) *GoInterfac
 
 func (gpm *GoPackageMeta) StructNames() []string {
 	structNames := make([]string, 0)
-	for _, gfm := range gpm.fileMetaMap {
+	for _, gfm := range gpm.Files() {
 		ast.Inspect(gfm.node, func(n ast.Node) bool {
 			if IsTypeNode(n) {
 				switch _n := n.(type) {
@@ -340,7 +512,7 @@ func (gpm *GoPackageMeta) StructNames() []string {
 
 func (gpm *GoPackageMeta) InterfaceNames() []string {
 	interfaceNames := make([]string, 0)
-	for _, gfm := range gpm.fileMetaMap {
+	for _, gfm := range gpm.Files() {
 		ast.Inspect(gfm.node, func(n ast.Node) bool {
 			if IsTypeNode(n) {
 				interfaceNames = append(interfaceNames, n.(*ast.TypeSpec).Name.String())
@@ -354,7 +526,7 @@ func (gpm *GoPackageMeta) InterfaceNames() []string {
 
 func (gpm *GoPackageMeta) FunctionNames() []string {
 	functionNames := make([]string, 0)
-	for _, gfm := range gpm.fileMetaMap {
+	for _, gfm := range gpm.Files() {
 		ast.Inspect(gfm.node, func(n ast.Node) bool {
 			if IsFuncNode(n) {
 				functionNames = append(functionNames, n.(*ast.FuncDecl).Name.String())
@@ -422,6 +594,53 @@ func (gpm *GoPackageMeta) FunctionNames() []string {
 // 	return gpm.structMetaMap[structName].methodDecl[methodName]
 // }
 
+// ImportMetaMap 汇总 package 内所有文件的 import 的 meta 数据
+// - key: import 的导入路径
+// - value: 按照文件名称排序的所有导入了该路径的 import 的 meta 数据
+func (gpm *GoPackageMeta) ImportMetaMap() map[string][]*GoImportMeta {
+	importMetaMap := make(map[string][]*GoImportMeta)
+	for _, fileName := range gpm.sortedFileNames() {
+		for _, gim := range gpm.fileMetaMap[fileName].importMetas {
+			importMetaMap[gim.importPath] = append(importMetaMap[gim.importPath], gim)
+		}
+	}
+	return importMetaMap
+}
+
+// packageComment 获取 package 的文档注释
+// - 优先使用 doc.go 的 package 子句的文档注释
+// - 其次使用按照文件名称排序的第一个具有 package 子句文档注释的文件
+func (gpm *GoPackageMeta) packageComment() *commentMeta {
+	if gfm := gpm.fileMetaMap["doc.go"]; gfm != nil && gfm.doc != nil {
+		return &gfm.commentMeta
+	}
+	for _, fileName := range gpm.sortedFileNames() {
+		if gfm := gpm.fileMetaMap[fileName]; gfm.doc != nil {
+			return &gfm.commentMeta
+		}
+	}
+	return &commentMeta{}
+}
+
+// Doc 获取 package 的文档注释的原始内容
+func (gpm *GoPackageMeta) Doc() []string { return gpm.packageComment().Doc() }
+
+// DocText 获取 go/doc 风格清理后的 package 的文档注释文本
+func (gpm *GoPackageMeta) DocText() string { return gpm.packageComment().DocText() }
+
+// DocLinks 获取 package 的文档注释中的所有文档链接
+func (gpm *GoPackageMeta) DocLinks() []*comment.DocLink { return gpm.packageComment().DocLinks() }
+
+// sortedFileNames 获取 package 内按照名称排序的所有文件名称
+func (gpm *GoPackageMeta) sortedFileNames() []string {
+	fileNames := make([]string, 0, len(gpm.fileMetaMap))
+	for fileName := range gpm.fileMetaMap {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+	return fileNames
+}
+
 // -------------------------------- unit test --------------------------------
 
 func (gpm *GoPackageMeta) Ident() string                                 { return gpm.ident }
@@ -429,6 +648,7 @@ func (gpm *GoPackageMeta) AbsolutePath() string                          { retur
 func (gpm *GoPackageMeta) ImportPath() string                            { return gpm.importPath }
 func (gpm *GoPackageMeta) FileMetaMap() map[string]*GoFileMeta           { return gpm.fileMetaMap }
 func (gpm *GoPackageMeta) VariableMetaMap() map[string]*GoVarMeta        { return gpm.varMetaMap }
+func (gpm *GoPackageMeta) ConstMetaMap() map[string]*GoVarMeta           { return gpm.constMetaMap }
 func (gpm *GoPackageMeta) FuncMetaMap() map[string]*GoFuncMeta           { return gpm.funcMetaMap }
 func (gpm *GoPackageMeta) StructMetaMap() map[string]*GoStructMeta       { return gpm.structMetaMap }
 func (gpm *GoPackageMeta) InterfaceMetaMap() map[string]*GoInterfaceMeta { return gpm.interfaceMetaMap }
@@ -0,0 +1,249 @@
+package extractor
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path/filepath"
+)
+
+type PositionMetaType int
+
+const (
+	POSITION_META_FILE             = iota + 1 // 文件
+	POSITION_META_FUNC                        // package 级 func
+	POSITION_META_METHOD                      // struct 的 method
+	POSITION_META_STRUCT                      // struct
+	POSITION_META_MEMBER                      // struct 的 member
+	POSITION_META_INTERFACE                   // interface
+	POSITION_META_INTERFACE_METHOD            // interface 的 method
+	POSITION_META_CALL                        // func 或 method 内的调用
+)
+
+// GoPositionMeta 源码位置所在的 meta 数据，通过 parent 构成由内到外的链
+// - f(g(x)) 中 x 的位置 -> call: g -> call: f -> func -> file
+type GoPositionMeta struct {
+	// 组合基本 meta 数据
+	// ast 节点为位置所在的 meta 的 ast 节点
+	*meta
+
+	// 位置所在的 meta 的种类
+	positionType PositionMetaType
+
+	// 外层的 meta 数据，文件时为 nil
+	parent *GoPositionMeta
+
+	// 位置所在的 package 的 meta 数据
+	packageMeta *GoPackageMeta
+
+	// 位置所在的文件的 meta 数据
+	fileMeta *GoFileMeta
+
+	// 种类为 POSITION_META_FUNC，POSITION_META_METHOD 时的 func 的 meta 数据
+	funcMeta *GoFuncMeta
+
+	// 种类为 POSITION_META_METHOD 时的 method 的 meta 数据
+	methodMeta *GoMethodMeta
+
+	// 种类为 POSITION_META_STRUCT，POSITION_META_MEMBER 时的 struct 的 meta 数据
+	structMeta *GoStructMeta
+
+	// 种类为 POSITION_META_MEMBER 时的 member 的 meta 数据
+	memberMeta *GoVarMeta
+
+	// 种类为 POSITION_META_INTERFACE，POSITION_META_INTERFACE_METHOD 时的 interface 的 meta 数据
+	interfaceMeta *GoInterfaceMeta
+
+	// 种类为 POSITION_META_INTERFACE_METHOD 时的 interface 的 method 的 meta 数据
+	interfaceMethodMeta *GoInterfaceMethodMeta
+
+	// 种类为 POSITION_META_CALL 时的调用的 meta 数据
+	callMeta *GoCallMeta
+}
+
+// -------------------------------- extractor --------------------------------
+
+// MetaAt 获取源码位置所在的最内层的 meta 数据，通过 Parent 或 Chain 获取外层的 meta 数据
+// - path 为文件的绝对路径或相对于项目的路径
+// - line 和 col 从 1 开始，col 按照字节计算，与 token.Position 一致
+func (gpm *GoProjectMeta) MetaAt(path string, line, col int) (*GoPositionMeta, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(gpm.absolutePath, path)
+	}
+	path = filepath.Clean(path)
+
+	var (
+		packageMeta *GoPackageMeta
+		fileMeta    *GoFileMeta
+	)
+	for _, packageKey := range sortedKeys(gpm.packageMap) {
+		for _, gfm := range gpm.packageMap[packageKey].fileMetaMap {
+			if filepath.Clean(gfm.path) == path {
+				packageMeta, fileMeta = gpm.packageMap[packageKey], gfm
+			}
+		}
+	}
+	if fileMeta == nil {
+		return nil, fmt.Errorf("file '%v' is not in project '%v'", path, gpm.moduleName)
+	}
+	pos, err := fileMeta.linePos(line, col)
+	if err != nil {
+		return nil, err
+	}
+
+	gpsm := &GoPositionMeta{meta: fileMeta.meta, positionType: POSITION_META_FILE, packageMeta: packageMeta, fileMeta: fileMeta}
+	if gfm := packageMeta.funcAt(path, pos); gfm != nil {
+		gpsm = gpsm.child(POSITION_META_FUNC, gfm.meta)
+		gpsm.funcMeta = gfm
+		return gpsm.callAt(pos), nil
+	}
+	for _, structIdent := range sortedKeys(packageMeta.structMetaMap) {
+		gsm := packageMeta.structMetaMap[structIdent]
+		for _, methodIdent := range sortedKeys(gsm.methodMetaMap) {
+			gmm := gsm.methodMetaMap[methodIdent]
+			if gmm.path == path && nodeContains(gmm.node, pos) {
+				gpsm = gpsm.child(POSITION_META_METHOD, gmm.meta)
+				gpsm.funcMeta, gpsm.methodMeta, gpsm.structMeta = gmm.GoFuncMeta, gmm, gsm
+				return gpsm.callAt(pos), nil
+			}
+		}
+		if gsm.path == path && nodeContains(gsm.node, pos) {
+			gpsm = gpsm.child(POSITION_META_STRUCT, gsm.meta)
+			gpsm.structMeta = gsm
+			return gpsm.memberAt(pos), nil
+		}
+	}
+	for _, interfaceIdent := range sortedKeys(packageMeta.interfaceMetaMap) {
+		gim := packageMeta.interfaceMetaMap[interfaceIdent]
+		if gim.path != path || !nodeContains(gim.node, pos) {
+			continue
+		}
+		gpsm = gpsm.child(POSITION_META_INTERFACE, gim.meta)
+		gpsm.interfaceMeta = gim
+		for _, methodIdent := range sortedKeys(gim.methodMetaMap) {
+			if gimm := gim.methodMetaMap[methodIdent]; nodeContains(gimm.node, pos) {
+				gpsm = gpsm.child(POSITION_META_INTERFACE_METHOD, gimm.meta)
+				gpsm.interfaceMethodMeta = gimm
+				break
+			}
+		}
+		return gpsm, nil
+	}
+	return gpsm, nil
+}
+
+// funcAt 获取文件内位置所在的 package 级 func
+func (gpm *GoPackageMeta) funcAt(path string, pos token.Pos) *GoFuncMeta {
+	for _, funcIdent := range sortedKeys(gpm.funcMetaMap) {
+		if gfm := gpm.funcMetaMap[funcIdent]; gfm.path == path && nodeContains(gfm.node, pos) {
+			return gfm
+		}
+	}
+	return nil
+}
+
+// child 构造内层的 meta 数据，继承 package 和 文件 以及外层的 func，struct，interface
+func (gpsm *GoPositionMeta) child(positionType PositionMetaType, m *meta) *GoPositionMeta {
+	c := *gpsm
+	c.meta, c.positionType, c.parent = m, positionType, gpsm
+	return &c
+}
+
+// callAt 获取 func 或 method 内位置所在的调用，嵌套的调用由外到内构成链
+func (gpsm *GoPositionMeta) callAt(pos token.Pos) *GoPositionMeta {
+	inner := gpsm
+	for _, gcm := range gpsm.funcMeta.Calls() {
+		// Calls 按照源码顺序，外层的调用先于内层的调用
+		if nodeContains(gcm.node, pos) && nodeContains(inner.node, gcm.node.Pos()) {
+			inner = inner.child(POSITION_META_CALL, gcm.meta)
+			inner.callMeta = gcm
+		}
+	}
+	return inner
+}
+
+// memberAt 获取 struct 内位置所在的 member，多个标识的 member 按照标识所在的位置判断
+// - A, B int 中 int 的位置为 A
+func (gpsm *GoPositionMeta) memberAt(pos token.Pos) *GoPositionMeta {
+	structType, ok := gpsm.node.(*ast.TypeSpec).Type.(*ast.StructType)
+	if !ok || structType.Fields == nil {
+		return gpsm
+	}
+	for _, field := range structType.Fields.List {
+		if !nodeContains(field, pos) {
+			continue
+		}
+		var gvm *GoVarMeta
+		if len(field.Names) > 0 {
+			gvm = gpsm.structMeta.memberMetaMap[field.Names[0].Name]
+			for _, name := range field.Names {
+				if nodeContains(name, pos) {
+					gvm = gpsm.structMeta.memberMetaMap[name.Name]
+				}
+			}
+		} else {
+			// 匿名成员以类型标识为 key
+			for _, member := range gpsm.structMeta.memberMetaMap {
+				if member.node == field {
+					gvm = member
+				}
+			}
+		}
+		if gvm == nil {
+			return gpsm
+		}
+		member := gpsm.child(POSITION_META_MEMBER, gvm.meta)
+		member.memberMeta = gvm
+		return member
+	}
+	return gpsm
+}
+
+// nodeContains ast 节点的范围 [Pos, End) 是否包含位置
+func nodeContains(node ast.Node, pos token.Pos) bool {
+	return node != nil && node.Pos() <= pos && pos < node.End()
+}
+
+// linePos 将文件内的行列转换为位置，使用提取时解析文件的 token.FileSet，不受文件之后的修改影响
+func (gfm *GoFileMeta) linePos(line, col int) (token.Pos, error) {
+	tokenFile := gfm.fileSet.File(gfm.node.Pos())
+	if tokenFile == nil {
+		return token.NoPos, fmt.Errorf("file '%v' has no position information", gfm.path)
+	}
+	if line < 1 || line > tokenFile.LineCount() {
+		return token.NoPos, fmt.Errorf("line %v out of range [1, %v] in file '%v'", line, tokenFile.LineCount(), gfm.path)
+	}
+	lineStart, lineEnd := tokenFile.LineStart(line), token.Pos(tokenFile.Base()+tokenFile.Size())
+	if line < tokenFile.LineCount() {
+		lineEnd = tokenFile.LineStart(line + 1)
+	}
+	if maxCol := int(lineEnd-lineStart) + 1; col < 1 || col > maxCol {
+		return token.NoPos, fmt.Errorf("column %v out of range [1, %v] at line %v in file '%v'", col, maxCol, line, gfm.path)
+	}
+	return lineStart + token.Pos(col-1), nil
+}
+
+// Chain 获取位置所在的 meta 数据的链，由内到外，最后为文件
+func (gpsm *GoPositionMeta) Chain() []*GoPositionMeta {
+	chain := make([]*GoPositionMeta, 0)
+	for c := gpsm; c != nil; c = c.parent {
+		chain = append(chain, c)
+	}
+	return chain
+}
+
+// -------------------------------- unit test --------------------------------
+
+func (gpsm *GoPositionMeta) PositionType() PositionMetaType  { return gpsm.positionType }
+func (gpsm *GoPositionMeta) Parent() *GoPositionMeta         { return gpsm.parent }
+func (gpsm *GoPositionMeta) PackageMeta() *GoPackageMeta     { return gpsm.packageMeta }
+func (gpsm *GoPositionMeta) FileMeta() *GoFileMeta           { return gpsm.fileMeta }
+func (gpsm *GoPositionMeta) FuncMeta() *GoFuncMeta           { return gpsm.funcMeta }
+func (gpsm *GoPositionMeta) MethodMeta() *GoMethodMeta       { return gpsm.methodMeta }
+func (gpsm *GoPositionMeta) StructMeta() *GoStructMeta       { return gpsm.structMeta }
+func (gpsm *GoPositionMeta) MemberMeta() *GoVarMeta          { return gpsm.memberMeta }
+func (gpsm *GoPositionMeta) InterfaceMeta() *GoInterfaceMeta { return gpsm.interfaceMeta }
+func (gpsm *GoPositionMeta) InterfaceMethodMeta() *GoInterfaceMethodMeta {
+	return gpsm.interfaceMethodMeta
+}
+func (gpsm *GoPositionMeta) CallMeta() *GoCallMeta { return gpsm.callMeta }
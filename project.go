@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 )
 
 // 以文件为单位提取
@@ -235,6 +238,16 @@ func extractGoProjectMeta(projectPath string, toHandlePaths map[string]struct{},
 		gpm.ExtractAll()
 	}
 
+	// 提取所有 package 的 meta 数据后，解析 import 以及类型表达式的包限定符到项目内的 package
+	for _, gpm := range projectMeta.packageMap {
+		for _, gfm := range gpm.fileMetaMap {
+			gfm.resolveImports(projectMeta.searchPackageMetaByImportPath)
+		}
+	}
+	for _, gpm := range projectMeta.packageMap {
+		gpm.resolveTypeExprs(projectMeta.searchPackageMetaByImportPath)
+	}
+
 	return projectMeta, nil
 }
 
@@ -245,6 +258,71 @@ func (gpm *GoProjectMeta) SearchPackageMeta(packageImportPath string) *GoPackage
 	return gpm.packageMap[packageImportPath]
 }
 
+// searchPackageMetaByImportPath 根据 import 中的导入路径 搜索项目内 package 的 meta 数据
+// - 导入路径由 模块名称 和 package 所在目录相对于项目的路径 组成
+// - main package 无法被导入
+func (gpm *GoProjectMeta) searchPackageMetaByImportPath(importPath string) *GoPackageMeta {
+	if len(gpm.moduleName) == 0 {
+		return nil
+	}
+	for _, packageMeta := range gpm.packageMap {
+		if packageMeta.ident == "main" {
+			continue
+		}
+		if gpm.packageImportPath(packageMeta) == importPath {
+			return packageMeta
+		}
+	}
+	return nil
+}
+
+// packageImportPath package 的导入路径，由 模块名称 和 package 所在目录相对于项目的路径 组成
+// - 与 PackageMap 的 key 不同，不受 package 名称以及 go.mod 的遍历顺序影响
+func (gpm *GoProjectMeta) packageImportPath(packageMeta *GoPackageMeta) string {
+	relPath, err := filepath.Rel(gpm.absolutePath, packageMeta.absolutePath)
+	if err != nil {
+		return packageMeta.importPath
+	}
+	return path.Join(gpm.moduleName, filepath.ToSlash(relPath))
+}
+
+// patternPaths package 条件匹配的路径: 完整的导入路径，项目内的 package 还包括相对于模块的路径
+func (gpm *GoProjectMeta) patternPaths(importPath string) []string {
+	paths := []string{importPath}
+	if relPath, ok := strings.CutPrefix(importPath, gpm.moduleName+"/"); ok && len(gpm.moduleName) > 0 {
+		paths = append(paths, relPath)
+	}
+	return paths
+}
+
+// SearchDirectiveMetas 按照 go generate 的处理顺序搜索项目内指定种类的 编译指令 的 meta 数据
+// - 按照 package 所在目录，文件名称，指令在文件中的位置 排序
+// - SearchDirectiveMetas(DIRECTIVE_TYPE_GENERATE) 即为项目内所有 //go:generate 命令的执行顺序
+func (gpm *GoProjectMeta) SearchDirectiveMetas(directiveType DirectiveType) []*GoDirectiveMeta {
+	fileMetas := make([]*GoFileMeta, 0)
+	for _, packageMeta := range gpm.packageMap {
+		for _, gfm := range packageMeta.fileMetaMap {
+			fileMetas = append(fileMetas, gfm)
+		}
+	}
+	sort.Slice(fileMetas, func(i, j int) bool {
+		iDir, jDir := filepath.Dir(fileMetas[i].path), filepath.Dir(fileMetas[j].path)
+		if iDir != jDir {
+			return iDir < jDir
+		}
+		return fileMetas[i].ident < fileMetas[j].ident
+	})
+	directiveMetas := make([]*GoDirectiveMeta, 0)
+	for _, gfm := range fileMetas {
+		for _, gdm := range gfm.directiveMetas {
+			if gdm.directiveType == directiveType {
+				directiveMetas = append(directiveMetas, gdm)
+			}
+		}
+	}
+	return directiveMetas
+}
+
 // -------------------------------- unit test --------------------------------
 
 func (gpm *GoProjectMeta) AbsolutePath() string                  { return gpm.absolutePath }
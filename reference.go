@@ -0,0 +1,505 @@
+package extractor
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+type ReferenceType int
+
+const (
+	REFERENCE_CALL  = iota + 1 // 调用 func 或 method: F()，pkg.F()，x.M()
+	REFERENCE_VALUE            // func 或 method 作为值使用: f := F，h := x.M，g := (*T).M
+	REFERENCE_TYPE             // 作为类型使用: var t T，T{}，*pkg.T，func (t *T) M()
+	REFERENCE_EMBED            // 作为匿名成员嵌入 struct 或 interface: struct{ *T }
+	REFERENCE_READ             // 读取字段，var 或 const: x.f，V
+	REFERENCE_WRITE            // 写入字段或 var: x.f = v，x.f++，T{f: v}，V = v
+)
+
+// GoReferenceMeta go 项目内对声明的引用 的 meta 数据
+// - h := s.Handle -> referenceType: REFERENCE_VALUE，ast 节点为 Handle
+type GoReferenceMeta struct {
+	// 组合基本 meta 数据
+	// ast 节点，要求为引用处的 *ast.Ident，pkg.F 和 x.M 为 Sel
+	// 以 ast 节点 为单位执行 AST/PrintAST/Expression/Format
+	*meta
+
+	// 引用的种类
+	referenceType ReferenceType
+
+	// 引用所在的 package 的 meta 数据
+	packageMeta *GoPackageMeta
+
+	// 引用所在的 func 或 method 的 meta 数据，位于 package 级声明时为 nil
+	funcMeta *GoFuncMeta
+}
+
+// referenceWalker 遍历一次项目，搜索对多个声明的引用
+type referenceWalker struct {
+	// 被引用的声明
+	// - key: 声明的标识
+	targets map[string][]*GoSelectorMatch
+
+	// 当前搜索的 package，文件，以及声明的上下文
+	packageMeta *GoPackageMeta
+	fileMeta    *GoFileMeta
+	ctx         *callContext
+
+	// 当前文件通过 . 导入的 package
+	dotImports map[*GoPackageMeta]struct{}
+
+	// - key: 被引用的声明
+	references map[*GoSelectorMatch][]*GoReferenceMeta
+
+	// 无法推断 receiver 类型的同名 selector，可能是对字段或 method 的引用
+	// - key: 被引用的声明
+	unresolved map[*GoSelectorMatch][]*GoReferenceMeta
+}
+
+// -------------------------------- extractor --------------------------------
+
+// locate 搜索 meta 数据在项目内对应的声明
+// - 支持 *GoFuncMeta，*GoMethodMeta，*GoStructMeta，*GoInterfaceMeta，*GoInterfaceMethodMeta，*GoVarMeta
+func (gpm *GoProjectMeta) locate(target any) (*GoSelectorMatch, error) {
+	for _, gsm := range gpm.SelectBy(&GoSelector{}) {
+		var located bool
+		switch t := target.(type) {
+		case *GoFuncMeta:
+			located = gsm.funcMeta == t
+		case *GoMethodMeta:
+			located = gsm.methodMeta == t
+		case *GoStructMeta:
+			located = gsm.kind == SELECTOR_KIND_STRUCT && gsm.structMeta == t
+		case *GoInterfaceMeta:
+			located = gsm.kind == SELECTOR_KIND_INTERFACE && gsm.interfaceMeta == t
+		case *GoInterfaceMethodMeta:
+			located = gsm.interfaceMethodMeta == t
+		case *GoVarMeta:
+			located = gsm.varMeta == t
+		default:
+			return nil, fmt.Errorf("unsupported meta type %T", target)
+		}
+		if located {
+			return gsm, nil
+		}
+	}
+	return nil, fmt.Errorf("meta %T is not declared in project '%v'", target, gpm.moduleName)
+}
+
+// References 搜索项目内对 func，method，struct，interface，interface 的 method，字段，var，const 的所有引用
+// - 按照 package 的导入路径，文件名称，源码顺序排序，不包括声明本身
+// - 支持 import 别名和 . 导入，字段和 method 通过 receiver 的类型判断，包括匿名成员提升的字段和 method
+// - 无法推断类型的 receiver 上的字段和 method 无法搜索
+func (gpm *GoProjectMeta) References(target any) ([]*GoReferenceMeta, error) {
+	gsm, err := gpm.locate(target)
+	if err != nil {
+		return nil, err
+	}
+	return gpm.referencesOf(gsm), nil
+}
+
+// referencesOf 搜索项目内对声明的所有引用
+func (gpm *GoProjectMeta) referencesOf(target *GoSelectorMatch) []*GoReferenceMeta {
+	return gpm.referencesOfAll([]*GoSelectorMatch{target})[target]
+}
+
+// referencesOfAll 遍历一次项目，搜索项目内对多个声明的所有引用
+// - 每个声明的引用与 referencesOf 一致
+func (gpm *GoProjectMeta) referencesOfAll(targets []*GoSelectorMatch) map[*GoSelectorMatch][]*GoReferenceMeta {
+	return gpm.walkReferences(targets).references
+}
+
+// walkReferences 遍历一次项目，搜索项目内对多个声明的所有引用以及无法推断 receiver 类型的同名 selector
+func (gpm *GoProjectMeta) walkReferences(targets []*GoSelectorMatch) *referenceWalker {
+	w := &referenceWalker{
+		targets:    make(map[string][]*GoSelectorMatch),
+		references: make(map[*GoSelectorMatch][]*GoReferenceMeta, len(targets)),
+		unresolved: make(map[*GoSelectorMatch][]*GoReferenceMeta),
+	}
+	for _, target := range targets {
+		w.targets[target.ident] = append(w.targets[target.ident], target)
+		w.references[target] = make([]*GoReferenceMeta, 0)
+	}
+	for _, packageMeta := range gpm.Packages() {
+		w.packageMeta = packageMeta
+		for _, fileMeta := range packageMeta.Files() {
+			w.fileMeta = fileMeta
+			fileNode, ok := fileMeta.node.(*ast.File)
+			if !ok {
+				continue
+			}
+			w.dotImports = make(map[*GoPackageMeta]struct{})
+			for _, gim := range fileMeta.dotImports() {
+				if gim.packageMeta != nil {
+					w.dotImports[gim.packageMeta] = struct{}{}
+				}
+			}
+			for _, decl := range fileNode.Decls {
+				w.walkDecl(decl)
+			}
+		}
+	}
+	return w
+}
+
+// walkDecl 搜索 package 级声明内的引用
+func (w *referenceWalker) walkDecl(decl ast.Decl) {
+	w.ctx = newCallContext(w.packageMeta, w.fileMeta.path, nil)
+	switch d := decl.(type) {
+	case *ast.GenDecl:
+		if d.Tok == token.IMPORT {
+			return
+		}
+	case *ast.FuncDecl:
+		w.ctx = newCallContext(w.packageMeta, w.fileMeta.path, newScopeInfo(d))
+		w.ctx.funcMeta = w.packageMeta.funcMetaOfDecl(d)
+	}
+	stack := make([]ast.Node, 0)
+	ast.Inspect(decl, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		if ident, ok := n.(*ast.Ident); ok && len(stack) > 0 {
+			if targets := w.targets[ident.Name]; len(targets) > 0 {
+				w.visit(ident, targets, stack)
+			}
+		}
+		stack = append(stack, n)
+		return true
+	})
+}
+
+// funcMetaOfDecl 获取 func 声明对应的 func 或 struct 的 method 的 meta 数据
+func (gpm *GoPackageMeta) funcMetaOfDecl(decl *ast.FuncDecl) *GoFuncMeta {
+	if decl.Recv == nil {
+		if gfm := gpm.funcMetaMap[decl.Name.Name]; gfm != nil && gfm.node == decl {
+			return gfm
+		}
+		return nil
+	}
+	receiverIdent, _ := extractMethodRecvStruct(decl)
+	if gsm := gpm.structMetaMap[receiverIdent]; gsm != nil {
+		if gmm := gsm.methodMetaMap[decl.Name.Name]; gmm != nil && gmm.node == decl {
+			return gmm.GoFuncMeta
+		}
+	}
+	return nil
+}
+
+// visit 判断与声明同名的标识是否是对声明的引用，targets 为与标识同名的所有声明
+func (w *referenceWalker) visit(ident *ast.Ident, targets []*GoSelectorMatch, stack []ast.Node) {
+	parent := stack[len(stack)-1]
+	if selectorExpr, ok := parent.(*ast.SelectorExpr); ok && selectorExpr.Sel == ident {
+		w.visitSelector(selectorExpr, targets, stack[:len(stack)-1])
+		return
+	}
+	if isDeclIdent(ident, parent) || w.ctx.scopeInfo.defs[ident] != nil || w.ctx.scopeInfo.uses[ident] != nil || shadowedByTypeParam(ident, stack) {
+		return
+	}
+	if w.isStructLitKey(ident, stack) {
+		litStructMeta := w.compositeLitType(stack[:len(stack)-1]).structMeta()
+		for _, target := range targets {
+			if target.kind == SELECTOR_KIND_FIELD && litStructMeta == target.structMeta {
+				w.add(target, ident, REFERENCE_WRITE)
+			}
+		}
+		return
+	}
+	for _, target := range targets {
+		switch target.kind {
+		case SELECTOR_KIND_FUNC, SELECTOR_KIND_STRUCT, SELECTOR_KIND_INTERFACE, SELECTOR_KIND_VAR, SELECTOR_KIND_CONST:
+			if _, dotImported := w.dotImports[target.packageMeta]; w.packageMeta == target.packageMeta || dotImported {
+				w.add(target, ident, w.classify(target, ident, stack))
+			}
+		}
+	}
+}
+
+// visitSelector 判断 selector 表达式是否是对声明的引用
+// - pkg.F，pkg.T: pkg 为声明所在的 package
+// - x.f，x.M，T.M，(*T).M: x 的类型或 T 为声明所属的 struct 或 interface
+func (w *referenceWalker) visitSelector(selectorExpr *ast.SelectorExpr, targets []*GoSelectorMatch, stack []ast.Node) {
+	if ident, ok := selectorExpr.X.(*ast.Ident); ok && w.ctx.isImport(ident) {
+		importedMeta := w.ctx.fileMeta.SearchImport(ident.Name).packageMeta
+		for _, target := range targets {
+			switch target.kind {
+			case SELECTOR_KIND_FUNC, SELECTOR_KIND_STRUCT, SELECTOR_KIND_INTERFACE, SELECTOR_KIND_VAR, SELECTOR_KIND_CONST:
+				if importedMeta == target.packageMeta {
+					w.add(target, selectorExpr.Sel, w.classify(target, selectorExpr, stack))
+				}
+			}
+		}
+		return
+	}
+	// receiver 的类型只推断一次，由所有同名的字段和 method 共用
+	var (
+		resolved     bool
+		receiverType namedType
+		owner        *GoStructMeta
+	)
+	resolve := func() {
+		if resolved {
+			return
+		}
+		resolved = true
+		receiverType = w.ctx.typeOfExpr(selectorExpr.X)
+		if receiverType.packageMeta == nil {
+			// method 表达式: T.M，(*T).M
+			receiverType = w.ctx.typeOfTypeExpr(selectorExpr.X)
+		}
+		if gsm := receiverType.structMeta(); gsm != nil {
+			owner = gsm.memberOwner(selectorExpr.Sel.Name)
+		}
+	}
+	for _, target := range targets {
+		switch target.kind {
+		case SELECTOR_KIND_FIELD, SELECTOR_KIND_METHOD, SELECTOR_KIND_INTERFACE_METHOD:
+			resolve()
+		default:
+			continue
+		}
+		switch {
+		case receiverType.packageMeta == nil && len(receiverType.ident) == 0:
+			w.unresolved[target] = append(w.unresolved[target], w.newReference(selectorExpr.Sel, w.classify(target, selectorExpr, stack)))
+		case target.kind == SELECTOR_KIND_INTERFACE_METHOD && receiverType.interfaceMeta() == target.interfaceMeta,
+			target.kind != SELECTOR_KIND_INTERFACE_METHOD && owner != nil && owner == target.structMeta:
+			w.add(target, selectorExpr.Sel, w.classify(target, selectorExpr, stack))
+		}
+	}
+}
+
+// memberOwner 搜索声明了字段或 method 的 struct，包括匿名成员提升的字段和 method
+// - 按照嵌入的深度逐层搜索，深度较浅的字段或 method 屏蔽较深的，同一深度按照声明顺序搜索
+// - 仅能搜索到项目内的 struct
+func (gsm *GoStructMeta) memberOwner(ident string) *GoStructMeta {
+	visited := map[*GoStructMeta]struct{}{gsm: {}}
+	for level := []*GoStructMeta{gsm}; len(level) > 0; {
+		for _, owner := range level {
+			if owner.memberMetaMap[ident] != nil || owner.methodMetaMap[ident] != nil {
+				return owner
+			}
+		}
+		next := make([]*GoStructMeta, 0)
+		for _, owner := range level {
+			for _, gvm := range owner.Members() {
+				if field, ok := gvm.node.(*ast.Field); !ok || len(field.Names) > 0 {
+					continue
+				}
+				embedded := newNamedType(gvm.typeExpr).structMeta()
+				if embedded == nil {
+					continue
+				}
+				if _, has := visited[embedded]; !has {
+					visited[embedded] = struct{}{}
+					next = append(next, embedded)
+				}
+			}
+		}
+		level = next
+	}
+	return nil
+}
+
+// classify 根据引用的表达式的外层节点判断对声明的引用的种类，stack 为表达式的所有外层节点
+func (w *referenceWalker) classify(target *GoSelectorMatch, expr ast.Node, stack []ast.Node) ReferenceType {
+	index := len(stack) - 1
+	// 跳过括号，泛型实例化以及指针类型
+	for ; index >= 0; index-- {
+		switch parent := stack[index].(type) {
+		case *ast.ParenExpr:
+			expr = parent
+			continue
+		case *ast.IndexExpr:
+			if parent.X == expr {
+				expr = parent
+				continue
+			}
+		case *ast.IndexListExpr:
+			if parent.X == expr {
+				expr = parent
+				continue
+			}
+		case *ast.StarExpr:
+			if target.kind == SELECTOR_KIND_STRUCT || target.kind == SELECTOR_KIND_INTERFACE {
+				expr = parent
+				continue
+			}
+		}
+		break
+	}
+	var parent ast.Node
+	if index >= 0 {
+		parent = stack[index]
+	}
+	switch target.kind {
+	case SELECTOR_KIND_FUNC, SELECTOR_KIND_METHOD, SELECTOR_KIND_INTERFACE_METHOD:
+		if callExpr, ok := parent.(*ast.CallExpr); ok && callExpr.Fun == expr {
+			return REFERENCE_CALL
+		}
+		return REFERENCE_VALUE
+	case SELECTOR_KIND_STRUCT, SELECTOR_KIND_INTERFACE:
+		if field, ok := parent.(*ast.Field); ok && field.Type == expr && len(field.Names) == 0 && index >= 2 {
+			switch stack[index-2].(type) {
+			case *ast.StructType, *ast.InterfaceType:
+				return REFERENCE_EMBED
+			}
+		}
+		return REFERENCE_TYPE
+	}
+	switch p := parent.(type) {
+	case *ast.AssignStmt:
+		for _, lhs := range p.Lhs {
+			if lhs == expr {
+				return REFERENCE_WRITE
+			}
+		}
+	case *ast.IncDecStmt:
+		return REFERENCE_WRITE
+	case *ast.RangeStmt:
+		if p.Key == expr || p.Value == expr {
+			return REFERENCE_WRITE
+		}
+	}
+	return REFERENCE_READ
+}
+
+// isDeclIdent 标识是否是声明的标识，而不是对声明的引用
+func isDeclIdent(ident *ast.Ident, parent ast.Node) bool {
+	switch p := parent.(type) {
+	case *ast.FuncDecl:
+		return p.Name == ident
+	case *ast.TypeSpec:
+		return p.Name == ident
+	case *ast.ImportSpec:
+		return p.Name == ident
+	case *ast.LabeledStmt:
+		return p.Label == ident
+	case *ast.BranchStmt:
+		return p.Label == ident
+	case *ast.ValueSpec:
+		for _, name := range p.Names {
+			if name == ident {
+				return true
+			}
+		}
+	case *ast.Field:
+		for _, name := range p.Names {
+			if name == ident {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// shadowedByTypeParam 标识是否是外层 package 级泛型类型声明的类型参数
+func shadowedByTypeParam(ident *ast.Ident, stack []ast.Node) bool {
+	for _, n := range stack {
+		typeSpec, ok := n.(*ast.TypeSpec)
+		if !ok || typeSpec.TypeParams == nil {
+			continue
+		}
+		for _, field := range typeSpec.TypeParams.List {
+			for _, name := range field.Names {
+				if name.Name == ident.Name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// isStructLitKey 标识是否是 struct 字面量中的字段标识: T{f: v}
+// - map 和 array 字面量的 key，以及项目内非 struct 的命名类型的字面量的 key 不是字段标识
+func (w *referenceWalker) isStructLitKey(ident *ast.Ident, stack []ast.Node) bool {
+	if len(stack) < 2 {
+		return false
+	}
+	keyValueExpr, ok := stack[len(stack)-1].(*ast.KeyValueExpr)
+	if !ok || keyValueExpr.Key != ident {
+		return false
+	}
+	if _, ok := stack[len(stack)-2].(*ast.CompositeLit); !ok {
+		return false
+	}
+	litType := w.compositeLitTypeExpr(stack[:len(stack)-1])
+	switch t := litType.(type) {
+	case nil, *ast.MapType, *ast.ArrayType:
+		return false
+	case *ast.StructType:
+		return true
+	default:
+		nt := w.ctx.typeOfTypeExpr(t)
+		return nt.packageMeta == nil || nt.structMeta() != nil
+	}
+}
+
+// compositeLitType 获取复合字面量的命名类型，stack 的最后一个节点为复合字面量
+func (w *referenceWalker) compositeLitType(stack []ast.Node) namedType {
+	litType := w.compositeLitTypeExpr(stack)
+	if litType == nil {
+		return namedType{}
+	}
+	return w.ctx.typeOfTypeExpr(litType)
+}
+
+// compositeLitTypeExpr 获取复合字面量的类型表达式，省略类型的元素使用外层字面量的元素类型
+// - []T{{f: v}} 中 {f: v} 的类型为 T
+func (w *referenceWalker) compositeLitTypeExpr(stack []ast.Node) ast.Expr {
+	lit := stack[len(stack)-1].(*ast.CompositeLit)
+	if lit.Type != nil || len(stack) < 3 {
+		return lit.Type
+	}
+	var outer []ast.Node
+	isKey := false
+	switch parent := stack[len(stack)-2].(type) {
+	case *ast.CompositeLit:
+		outer = stack[:len(stack)-1]
+	case *ast.KeyValueExpr:
+		if _, ok := stack[len(stack)-3].(*ast.CompositeLit); !ok {
+			return nil
+		}
+		outer, isKey = stack[:len(stack)-2], parent.Key == lit
+	default:
+		return nil
+	}
+	var elemType ast.Expr
+	switch t := w.compositeLitTypeExpr(outer).(type) {
+	case *ast.ArrayType:
+		elemType = t.Elt
+	case *ast.MapType:
+		elemType = t.Value
+		if isKey {
+			elemType = t.Key
+		}
+	default:
+		return nil
+	}
+	if starExpr, ok := elemType.(*ast.StarExpr); ok {
+		return starExpr.X
+	}
+	return elemType
+}
+
+func (w *referenceWalker) add(target *GoSelectorMatch, ident *ast.Ident, referenceType ReferenceType) {
+	w.references[target] = append(w.references[target], w.newReference(ident, referenceType))
+}
+
+func (w *referenceWalker) newReference(ident *ast.Ident, referenceType ReferenceType) *GoReferenceMeta {
+	return &GoReferenceMeta{
+		meta:          w.fileMeta.copyMeta(ident),
+		referenceType: referenceType,
+		packageMeta:   w.packageMeta,
+		funcMeta:      w.ctx.funcMeta,
+	}
+}
+
+// -------------------------------- unit test --------------------------------
+
+func (grm *GoReferenceMeta) ReferenceType() ReferenceType { return grm.referenceType }
+func (grm *GoReferenceMeta) PackageMeta() *GoPackageMeta  { return grm.packageMeta }
+func (grm *GoReferenceMeta) FuncMeta() *GoFuncMeta        { return grm.funcMeta }
+func (grm *GoReferenceMeta) Pos() token.Position          { return grm.position(grm.node.Pos()) }
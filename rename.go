@@ -0,0 +1,508 @@
+package extractor
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"regexp"
+	"strings"
+)
+
+// docLinkPattern 文档注释中的文档链接: [Name]，[T.M]，[pkg.Name]，[pkg.T.M]，[*T]，[importpath.Name]
+var docLinkPattern = regexp.MustCompile(`\[\*?([A-Za-z_][\w./]*)\]`)
+
+// renamer 项目内的重命名
+type renamer struct {
+	projectMeta *GoProjectMeta
+
+	// 重命名前后的标识
+	oldName, newName string
+
+	// 文件的绝对路径到文件的 meta 数据
+	fileMetas map[string]*GoFileMeta
+
+	// func 声明的作用域分析结果
+	scopeInfos map[*ast.FuncDecl]*scopeInfo
+
+	// 所有 文本替换，key: 文件路径和字节偏移，避免重复替换同一个标识
+	edits map[string]*GoTextEdit
+}
+
+// docLinkRef 文档注释中的文档链接，offset 为各部分在文件中的字节偏移，不存在时为 -1
+// - [pkg.T.M] -> qualifier: pkg，recv: T，name: M
+type docLinkRef struct {
+	// 链接指向的 package 的 meta 数据，项目外的 package 为 nil
+	packageMeta *GoPackageMeta
+
+	// 通过 import 的 package 名称或别名限定时为对应的 import，通过导入路径限定或指向当前 package 时为 nil
+	importMeta *GoImportMeta
+
+	recv, name string
+
+	qualifierOffset, recvOffset, nameOffset int
+}
+
+// -------------------------------- extractor --------------------------------
+
+// Rename 在项目内重命名 func，method，struct，interface，interface 的 method，字段，var，const 或 package
+// - target 支持 *GoFuncMeta，*GoMethodMeta，*GoStructMeta，*GoInterfaceMeta，*GoInterfaceMethodMeta，*GoVarMeta，*GoPackageMeta
+// - 修改声明，以声明标识开头的文档注释，以及项目内的所有引用，包括 struct 字面量的 key，method 表达式以及文档注释中的文档链接
+// - 存在无法推断 receiver 类型的同名字段或 method 的 selector 时拒绝重命名，并列出其位置
+// - 重命名 struct 或 interface 时一并修改以其为匿名成员的字段的引用
+// - 重命名 package 时只修改 package 子句和未使用别名的 import 的引用，不修改目录和导入路径
+// - 新的标识与已有的声明冲突，引用处被局部标识遮蔽，无法被其他 package 访问或破坏 interface 的实现时拒绝重命名
+// - 返回的修改集合可以通过 Diff 预览或通过 Apply 应用，应用后需要重新提取项目
+func (gpm *GoProjectMeta) Rename(target any, newName string) (*GoEditSet, error) {
+	if !token.IsIdentifier(newName) || newName == "_" {
+		return nil, fmt.Errorf("'%v' is not a valid identifier", newName)
+	}
+	r := &renamer{
+		projectMeta: gpm,
+		newName:     newName,
+		fileMetas:   make(map[string]*GoFileMeta),
+		scopeInfos:  make(map[*ast.FuncDecl]*scopeInfo),
+		edits:       make(map[string]*GoTextEdit),
+	}
+	for _, packageMeta := range gpm.Packages() {
+		for _, gfm := range packageMeta.Files() {
+			r.fileMetas[gfm.path] = gfm
+		}
+	}
+
+	var err error
+	if packageMeta, ok := target.(*GoPackageMeta); ok {
+		err = r.renamePackage(packageMeta)
+	} else {
+		var gsm *GoSelectorMatch
+		if gsm, err = gpm.locate(target); err == nil {
+			err = r.renameDecl(gsm)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	edits := make([]*GoTextEdit, 0, len(r.edits))
+	for _, edit := range r.edits {
+		edits = append(edits, edit)
+	}
+	return newGoEditSet(edits), nil
+}
+
+// renameDecl 重命名声明以及其所有引用
+func (r *renamer) renameDecl(target *GoSelectorMatch) error {
+	r.oldName = target.ident
+	if r.oldName == r.newName {
+		return fmt.Errorf("'%v' is already named '%v'", target.ID(), r.newName)
+	}
+	if err := r.checkDecl(target); err != nil {
+		return err
+	}
+
+	// 以重命名的类型为匿名成员的字段，字段名称随类型名称变化
+	embeddedFields := make([]*GoSelectorMatch, 0)
+	if target.kind == SELECTOR_KIND_STRUCT || target.kind == SELECTOR_KIND_INTERFACE {
+		for _, packageMeta := range r.projectMeta.Packages() {
+			for _, gsm := range packageMeta.Structs() {
+				for _, gvm := range gsm.Members() {
+					if field, ok := gvm.node.(*ast.Field); !ok || len(field.Names) > 0 || gvm.ident != r.oldName {
+						continue
+					}
+					namedType := newNamedType(gvm.typeExpr)
+					if namedType.structMeta() != target.structMeta || namedType.interfaceMeta() != target.interfaceMeta {
+						continue
+					}
+					if gsm.memberMetaMap[r.newName] != nil || gsm.methodMetaMap[r.newName] != nil {
+						return fmt.Errorf("struct '%v' embeds '%v' and already has field or method '%v'", gsm.ident, r.oldName, r.newName)
+					}
+					embeddedFields = append(embeddedFields, &GoSelectorMatch{kind: SELECTOR_KIND_FIELD, packageMeta: packageMeta, importPath: r.projectMeta.packageImportPath(packageMeta), ident: r.oldName, owner: gsm.ident, structMeta: gsm, varMeta: gvm})
+				}
+			}
+		}
+	}
+
+	targets := append([]*GoSelectorMatch{target}, embeddedFields...)
+	w := r.projectMeta.walkReferences(targets)
+	for _, gsm := range targets {
+		references := w.references[gsm]
+		if err := r.checkUnresolved(gsm, w.unresolved[gsm]); err != nil {
+			return err
+		}
+		if err := r.checkReferences(gsm, references); err != nil {
+			return err
+		}
+		if gsm == target {
+			r.addIdent(gsm.declMeta().path, gsm.nameIdent())
+			r.addDocLeadingName(gsm.declMeta().path, gsm.commentMeta().doc)
+		}
+		for _, grm := range references {
+			r.addIdent(grm.path, grm.node.(*ast.Ident))
+		}
+		r.renameDocLinks(func(link *docLinkRef) []int {
+			return matchDocLink(gsm, link)
+		})
+	}
+	return nil
+}
+
+// checkDecl 检查新的标识是否与已有的声明冲突，或破坏 interface 的实现
+func (r *renamer) checkDecl(target *GoSelectorMatch) error {
+	packageMeta := target.packageMeta
+	switch target.kind {
+	case SELECTOR_KIND_FUNC, SELECTOR_KIND_STRUCT, SELECTOR_KIND_INTERFACE, SELECTOR_KIND_VAR, SELECTOR_KIND_CONST:
+		if types.Universe.Lookup(r.newName) != nil || r.newName == "init" || (r.newName == "main" && packageMeta.ident == "main") {
+			return fmt.Errorf("'%v' would shadow or be a predeclared or special identifier", r.newName)
+		}
+		if err := r.checkPackageScope(packageMeta, ""); err != nil {
+			return err
+		}
+		if !token.IsExported(r.newName) {
+			return nil
+		}
+		// 通过 . 导入了该 package 的文件
+		for _, importerMeta := range r.projectMeta.Packages() {
+			for _, gfm := range importerMeta.Files() {
+				for _, gim := range gfm.dotImports() {
+					if gim.packageMeta == packageMeta {
+						if err := r.checkPackageScope(importerMeta, gfm.ident); err != nil {
+							return err
+						}
+					}
+				}
+			}
+		}
+	case SELECTOR_KIND_FIELD, SELECTOR_KIND_METHOD:
+		if target.kind == SELECTOR_KIND_FIELD {
+			if field, ok := target.varMeta.node.(*ast.Field); ok && len(field.Names) == 0 {
+				return fmt.Errorf("'%v' is an embedded field, rename its type instead", target.ID())
+			}
+		}
+		// 声明了该字段或 method 的 struct 以及通过匿名成员提升了该字段或 method 的 struct
+		for _, gsm := range r.structs() {
+			if gsm.memberOwner(r.oldName) != target.structMeta {
+				continue
+			}
+			if gsm.memberOwner(r.newName) != nil {
+				return fmt.Errorf("struct '%v' already has field or method '%v'", gsm.ident, r.newName)
+			}
+			if target.kind != SELECTOR_KIND_METHOD {
+				continue
+			}
+			for _, gim := range r.interfaces() {
+				if _, has := gim.methodIdents()[r.oldName]; has && gsm.implements(gim) {
+					return fmt.Errorf("renaming '%v' would break the implementation of interface '%v' by struct '%v'", target.ID(), gim.ident, gsm.ident)
+				}
+			}
+		}
+	case SELECTOR_KIND_INTERFACE_METHOD:
+		if target.interfaceMeta.methodMetaMap[r.newName] != nil {
+			return fmt.Errorf("interface '%v' already has method '%v'", target.interfaceMeta.ident, r.newName)
+		}
+		for _, gsm := range r.structs() {
+			if gsm.implements(target.interfaceMeta) {
+				return fmt.Errorf("renaming '%v' would break the implementation of interface '%v' by struct '%v'", target.ID(), target.interfaceMeta.ident, gsm.ident)
+			}
+		}
+	}
+	return nil
+}
+
+// checkPackageScope 检查新的标识是否与 package 级声明以及文件的 import 冲突，fileIdent 为空时检查 package 内所有文件
+func (r *renamer) checkPackageScope(packageMeta *GoPackageMeta, fileIdent string) error {
+	_, isType := packageMeta.typeIdents()[r.newName]
+	if isType || packageMeta.funcMetaMap[r.newName] != nil || packageMeta.varMetaMap[r.newName] != nil || packageMeta.constMetaMap[r.newName] != nil {
+		return fmt.Errorf("'%v' is already declared in package '%v'", r.newName, packageMeta.importPath)
+	}
+	for _, gfm := range packageMeta.Files() {
+		if len(fileIdent) > 0 && gfm.ident != fileIdent {
+			continue
+		}
+		if gfm.SearchImport(r.newName) != nil {
+			return fmt.Errorf("'%v' collides with an import in file '%v'", r.newName, gfm.path)
+		}
+	}
+	return nil
+}
+
+// checkReferences 检查引用处是否会被局部标识遮蔽，以及重命名后其他 package 是否仍然可以访问
+func (r *renamer) checkReferences(target *GoSelectorMatch, references []*GoReferenceMeta) error {
+	for _, grm := range references {
+		if !token.IsExported(r.newName) && grm.packageMeta != target.packageMeta {
+			return fmt.Errorf("'%v' is referenced from package '%v' at %v, unexported '%v' would be inaccessible", target.ID(), grm.packageMeta.importPath, grm.Pos(), r.newName)
+		}
+		switch target.kind {
+		case SELECTOR_KIND_FUNC, SELECTOR_KIND_STRUCT, SELECTOR_KIND_INTERFACE, SELECTOR_KIND_VAR, SELECTOR_KIND_CONST:
+			if r.shadowed(grm.path, grm.node.(*ast.Ident)) {
+				return fmt.Errorf("reference to '%v' at %v would be shadowed by local '%v'", target.ID(), grm.Pos(), r.newName)
+			}
+		}
+	}
+	return nil
+}
+
+// checkUnresolved 检查是否存在无法推断 receiver 类型的同名 selector，这些位置无法判断是否需要修改
+func (r *renamer) checkUnresolved(target *GoSelectorMatch, unresolved []*GoReferenceMeta) error {
+	if len(unresolved) == 0 {
+		return nil
+	}
+	sites := make([]string, 0, len(unresolved))
+	for _, grm := range unresolved {
+		sites = append(sites, grm.Pos().String())
+	}
+	return fmt.Errorf("can not rename '%v', the receiver type of '%v' can not be inferred at %v", target.ID(), r.oldName, strings.Join(sites, ", "))
+}
+
+// shadowed 新的标识在标识所在的作用域内是否已经被声明为局部标识
+// - 限定标识 pkg.F 和 x.f 中的 F 和 f 不属于任何作用域
+func (r *renamer) shadowed(path string, ident *ast.Ident) bool {
+	gfm := r.fileMetas[path]
+	if gfm == nil {
+		return false
+	}
+	for _, decl := range gfm.node.(*ast.File).Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || !nodeContains(funcDecl, ident.Pos()) {
+			continue
+		}
+		info := r.scopeInfos[funcDecl]
+		if info == nil {
+			info = newScopeInfo(funcDecl)
+			r.scopeInfos[funcDecl] = info
+		}
+		s := info.useScopes[ident]
+		return s != nil && s.lookup(r.newName) != nil
+	}
+	return false
+}
+
+// renamePackage 重命名 package 子句，以及项目内未使用别名的 import 的引用
+func (r *renamer) renamePackage(packageMeta *GoPackageMeta) error {
+	r.oldName = packageMeta.ident
+	if r.oldName == "main" {
+		return fmt.Errorf("package main can not be renamed")
+	}
+	if r.oldName == r.newName {
+		return fmt.Errorf("package '%v' is already named '%v'", packageMeta.importPath, r.newName)
+	}
+	for _, gfm := range packageMeta.Files() {
+		r.addIdent(gfm.path, gfm.node.(*ast.File).Name)
+	}
+
+	for _, importerMeta := range r.projectMeta.Packages() {
+		for _, gfm := range importerMeta.Files() {
+			gim := gfm.SearchImport(r.oldName)
+			if gim == nil || gim.packageMeta != packageMeta || len(gim.alias) > 0 {
+				continue
+			}
+			if err := r.checkPackageScope(importerMeta, gfm.ident); err != nil {
+				return err
+			}
+			for _, decl := range gfm.node.(*ast.File).Decls {
+				if err := r.renameQualifiers(importerMeta, gfm, decl); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	r.renameDocLinks(func(link *docLinkRef) []int {
+		if link.packageMeta != packageMeta || link.qualifierOffset < 0 || (link.importMeta != nil && len(link.importMeta.alias) > 0) {
+			return nil
+		}
+		return []int{link.qualifierOffset}
+	})
+	return nil
+}
+
+// renameQualifiers 重命名 package 级声明内作为 selector 限定符的 package 名称: pkg.F
+func (r *renamer) renameQualifiers(packageMeta *GoPackageMeta, gfm *GoFileMeta, decl ast.Decl) error {
+	ctx := newCallContext(packageMeta, gfm.path, nil)
+	switch d := decl.(type) {
+	case *ast.GenDecl:
+		if d.Tok == token.IMPORT {
+			return nil
+		}
+	case *ast.FuncDecl:
+		ctx = newCallContext(packageMeta, gfm.path, newScopeInfo(d))
+	}
+	var err error
+	ast.Inspect(decl, func(n ast.Node) bool {
+		selectorExpr, ok := n.(*ast.SelectorExpr)
+		if !ok || err != nil {
+			return err == nil
+		}
+		ident, ok := selectorExpr.X.(*ast.Ident)
+		if !ok || ident.Name != r.oldName || !ctx.isImport(ident) {
+			return true
+		}
+		if s := ctx.scopeInfo.useScopes[ident]; s != nil && s.lookup(r.newName) != nil {
+			err = fmt.Errorf("reference to package '%v' at %v would be shadowed by local '%v'", r.oldName, gfm.position(ident.Pos()), r.newName)
+			return false
+		}
+		r.addIdent(gfm.path, ident)
+		return true
+	})
+	return err
+}
+
+// renameDocLinks 重命名项目内所有文档注释中的文档链接，match 返回需要重命名的部分的字节偏移
+func (r *renamer) renameDocLinks(match func(link *docLinkRef) []int) {
+	for _, packageMeta := range r.projectMeta.Packages() {
+		for _, gfm := range packageMeta.Files() {
+			for _, commentGroup := range gfm.node.(*ast.File).Comments {
+				for _, c := range commentGroup.List {
+					for _, link := range r.docLinks(packageMeta, gfm, c) {
+						for _, offset := range match(link) {
+							r.addEdit(gfm, offset, offset+len(r.oldName))
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// docLinks 解析注释中的所有文档链接，规则与 go/doc 一致
+// - [A.B] 中 A 为当前 package 的类型时为 method 或字段，否则为 package 限定的声明
+func (r *renamer) docLinks(packageMeta *GoPackageMeta, gfm *GoFileMeta, c *ast.Comment) []*docLinkRef {
+	links := make([]*docLinkRef, 0)
+	commentOffset := gfm.offset(c.Slash)
+	for _, match := range docLinkPattern.FindAllStringSubmatchIndex(c.Text, -1) {
+		if match[1] < len(c.Text) && c.Text[match[1]] == ':' {
+			// 链接定义: [Text]: URL
+			continue
+		}
+		if match[0] > 0 && isIdentByte(c.Text[match[0]-1]) {
+			// 索引表达式: m[k]
+			continue
+		}
+		text, textOffset := c.Text[match[2]:match[3]], commentOffset+match[2]
+		link := &docLinkRef{qualifierOffset: -1, recvOffset: -1, nameOffset: -1}
+
+		// 导入路径限定: [encoding/json.Marshal]
+		qualified := false
+		if slash := strings.LastIndex(text, "/"); slash >= 0 {
+			dot := strings.Index(text[slash:], ".")
+			if dot < 0 {
+				continue
+			}
+			link.packageMeta = r.projectMeta.searchPackageMetaByImportPath(text[:slash+dot])
+			text, textOffset, qualified = text[slash+dot+1:], textOffset+slash+dot+1, true
+		}
+
+		parts := strings.Split(text, ".")
+		offsets := make([]int, len(parts))
+		for index := 1; index < len(parts); index++ {
+			offsets[index] = offsets[index-1] + len(parts[index-1]) + 1
+		}
+		_, isType := packageMeta.typeIdents()[parts[0]]
+		switch {
+		case qualified && len(parts) <= 2, !qualified && len(parts) == 1, !qualified && len(parts) == 2 && isType:
+			if !qualified {
+				link.packageMeta = packageMeta
+			}
+		case !qualified && len(parts) <= 3:
+			link.qualifierOffset = textOffset
+			if link.importMeta = gfm.SearchImport(parts[0]); link.importMeta != nil {
+				link.packageMeta = link.importMeta.packageMeta
+			} else if parts[0] == packageMeta.ident {
+				link.packageMeta = packageMeta
+			} else {
+				continue
+			}
+			parts, offsets = parts[1:], offsets[1:]
+		default:
+			continue
+		}
+		if len(parts) == 2 {
+			link.recv, link.recvOffset = parts[0], textOffset+offsets[0]
+		}
+		link.name, link.nameOffset = parts[len(parts)-1], textOffset+offsets[len(parts)-1]
+		links = append(links, link)
+	}
+	return links
+}
+
+// matchDocLink 获取文档链接中指向声明的部分的字节偏移
+// - [T]，[pkg.T] 以及 [T.M] 中的 T 指向 struct 或 interface T
+func matchDocLink(target *GoSelectorMatch, link *docLinkRef) []int {
+	if link.packageMeta != target.packageMeta {
+		return nil
+	}
+	switch target.kind {
+	case SELECTOR_KIND_FUNC, SELECTOR_KIND_VAR, SELECTOR_KIND_CONST:
+		if len(link.recv) == 0 && link.name == target.ident {
+			return []int{link.nameOffset}
+		}
+	case SELECTOR_KIND_STRUCT, SELECTOR_KIND_INTERFACE:
+		if len(link.recv) == 0 && link.name == target.ident {
+			return []int{link.nameOffset}
+		}
+		if link.recv == target.ident {
+			return []int{link.recvOffset}
+		}
+	case SELECTOR_KIND_FIELD, SELECTOR_KIND_METHOD, SELECTOR_KIND_INTERFACE_METHOD:
+		if link.recv == target.owner && link.name == target.ident {
+			return []int{link.nameOffset}
+		}
+	}
+	return nil
+}
+
+// addIdent 添加将标识替换为新的标识的 文本替换
+func (r *renamer) addIdent(path string, ident *ast.Ident) {
+	if gfm := r.fileMetas[path]; gfm != nil && ident != nil && ident.Name == r.oldName {
+		offset := gfm.offset(ident.Pos())
+		r.addEdit(gfm, offset, offset+len(ident.Name))
+	}
+}
+
+// addDocLeadingName 文档注释以声明标识开头时添加替换该标识的 文本替换: // Player 玩家
+func (r *renamer) addDocLeadingName(path string, doc *ast.CommentGroup) {
+	gfm := r.fileMetas[path]
+	if gfm == nil || doc == nil || !strings.HasPrefix(doc.List[0].Text, "//") {
+		return
+	}
+	text := doc.List[0].Text[2:]
+	index := len(text) - len(strings.TrimLeft(text, " \t"))
+	if !strings.HasPrefix(text[index:], r.oldName) {
+		return
+	}
+	if end := index + len(r.oldName); end < len(text) && isIdentByte(text[end]) {
+		return
+	}
+	offset := gfm.offset(doc.List[0].Slash) + 2 + index
+	r.addEdit(gfm, offset, offset+len(r.oldName))
+}
+
+// addEdit 添加将文件内 [offset, end) 替换为新的标识的 文本替换，同一位置只替换一次
+func (r *renamer) addEdit(gfm *GoFileMeta, offset, end int) {
+	key := fmt.Sprintf("%v:%v", gfm.path, offset)
+	if _, has := r.edits[key]; !has {
+		r.edits[key] = newGoTextEdit(gfm.path, gfm.content, offset, end, r.newName)
+	}
+}
+
+// isIdentByte 字节是否可以作为标识的一部分
+func isIdentByte(b byte) bool {
+	return b == '_' || ('0' <= b && b <= '9') || ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z')
+}
+
+// structs 获取项目内所有 struct 的 meta 数据
+func (r *renamer) structs() []*GoStructMeta {
+	structMetas := make([]*GoStructMeta, 0)
+	for _, packageMeta := range r.projectMeta.Packages() {
+		structMetas = append(structMetas, packageMeta.Structs()...)
+	}
+	return structMetas
+}
+
+// interfaces 获取项目内所有 interface 的 meta 数据
+func (r *renamer) interfaces() []*GoInterfaceMeta {
+	interfaceMetas := make([]*GoInterfaceMeta, 0)
+	for _, packageMeta := range r.projectMeta.Packages() {
+		interfaceMetas = append(interfaceMetas, packageMeta.Interfaces()...)
+	}
+	return interfaceMetas
+}
@@ -0,0 +1,526 @@
+package extractor
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// declRange 文件内可修改的声明的范围
+// - 修改声明时保留文档注释和行尾注释，新的源码带有注释时才替换原有的注释
+type declRange struct {
+	// 声明的 ast 节点
+	// - *ast.FuncDecl
+	// - 非括号形式的 *ast.GenDecl: type T struct{}，var v = 1
+	// - 括号形式的 *ast.GenDecl 内的 *ast.TypeSpec，*ast.ValueSpec
+	// - struct 的 member 或 interface 的 method 的 *ast.Field
+	node ast.Node
+
+	// 声明的文档注释，没有时为 nil
+	doc *ast.CommentGroup
+
+	// 声明的行尾注释，没有时为 nil
+	comment *ast.CommentGroup
+}
+
+// newDeclRange 通过 ast 节点构造 声明的范围
+func newDeclRange(node ast.Node) *declRange {
+	dr := &declRange{node: node}
+	switch n := node.(type) {
+	case *ast.FuncDecl:
+		dr.doc = n.Doc
+	case *ast.GenDecl:
+		dr.doc = n.Doc
+		if len(n.Specs) == 1 {
+			dr.comment = newCommentMeta(n.Specs[0]).comment
+		}
+	default:
+		cm := newCommentMeta(node)
+		dr.doc, dr.comment = cm.doc, cm.comment
+	}
+	return dr
+}
+
+// -------------------------------- extractor --------------------------------
+
+// ReplaceDecl 使用源码替换文件内 func，method，struct，interface，package 级 var，const 的声明
+// - src 为完整的顶层声明: func F() {}，type T struct{}，var v = 1，种类需要与原声明一致
+// - 括号形式的声明内只替换该声明，src 仍然使用非括号形式
+// - 与其他标识共享同一个声明时整体替换，src 需要声明相同的标识: var a, b = 1, 2
+// - src 没有文档注释或行尾注释时，保留原声明的注释
+// - 修改在调用 Save 后写回文件
+func (gfm *GoFileMeta) ReplaceDecl(target any, src string) error {
+	dr, err := gfm.declRange(target)
+	if err != nil {
+		return err
+	}
+	if _, ok := dr.node.(*ast.Field); ok {
+		return fmt.Errorf("target is a field, use ReplaceField instead")
+	}
+	fileAST, srcOffset, err := parseSource("package p\n\n", src, "")
+	if err != nil {
+		return err
+	}
+	if len(fileAST.Decls) != 1 {
+		return fmt.Errorf("source must contain exactly one declaration, got %v", len(fileAST.Decls))
+	}
+
+	srcDecl := fileAST.Decls[0]
+	switch node := dr.node.(type) {
+	case *ast.FuncDecl:
+		if srcFuncDecl, ok := srcDecl.(*ast.FuncDecl); !ok || (srcFuncDecl.Recv == nil) != (node.Recv == nil) {
+			return fmt.Errorf("source is not a %v declaration", funcDeclKind(node))
+		}
+	case *ast.GenDecl, ast.Spec:
+		srcGenDecl, ok := srcDecl.(*ast.GenDecl)
+		if !ok || srcGenDecl.Tok != gfm.genDeclOf(dr.node).Tok || len(srcGenDecl.Specs) != 1 || srcGenDecl.Lparen.IsValid() {
+			return fmt.Errorf("source is not a single %v declaration", gfm.genDeclOf(dr.node).Tok)
+		}
+		if names := valueSpecNames(dr.node); len(names) > 1 && strings.Join(names, ", ") != strings.Join(valueSpecNames(srcGenDecl.Specs[0]), ", ") {
+			return fmt.Errorf("target shares its declaration with other names, source must declare %v", strings.Join(names, ", "))
+		}
+		if _, ok := dr.node.(ast.Spec); ok {
+			// 括号形式的声明内只替换 spec，源码的 GenDecl 的文档注释作为 spec 的文档注释
+			srcRange := newDeclRange(srcGenDecl.Specs[0])
+			srcRange.doc = srcGenDecl.Doc
+			return gfm.replaceRange(dr, srcRange, src, srcOffset)
+		}
+	}
+	return gfm.replaceRange(dr, newDeclRange(srcDecl), src, srcOffset)
+}
+
+// RemoveDecl 删除文件内 func，method，struct，interface，package 级 var，const 的声明，以及其文档注释和行尾注释
+// - 括号形式的声明内只删除该声明
+// - 与其他标识共享同一个声明时无法删除: var a, b = 1, 2
+func (gfm *GoFileMeta) RemoveDecl(target any) error {
+	dr, err := gfm.declRange(target)
+	if err != nil {
+		return err
+	}
+	switch node := dr.node.(type) {
+	case *ast.Field:
+		return fmt.Errorf("target is a field, use RemoveField instead")
+	case *ast.GenDecl:
+		if valueSpec, ok := node.Specs[0].(*ast.ValueSpec); ok && len(valueSpec.Names) > 1 {
+			return fmt.Errorf("target shares its declaration with other names")
+		}
+	case *ast.ValueSpec:
+		if len(node.Names) > 1 {
+			return fmt.Errorf("target shares its declaration with other names")
+		}
+	}
+	return gfm.removeRange(dr)
+}
+
+// AppendDecl 在文件末尾添加顶层声明，src 可以包含多个声明及其注释
+func (gfm *GoFileMeta) AppendDecl(src string) error {
+	if gfm.content == nil {
+		return fmt.Errorf("file '%v' has no content snapshot", gfm.path)
+	}
+	fileAST, _, err := parseSource("package p\n\n", src, "")
+	if err != nil {
+		return err
+	}
+	if len(fileAST.Decls) == 0 {
+		return fmt.Errorf("source contains no declaration")
+	}
+	return gfm.addEdit(len(gfm.content), len(gfm.content), "\n\n"+strings.TrimSpace(src)+"\n")
+}
+
+// ReplaceBody 使用语句替换 func 或 method 的函数体，body 为不包含 {} 的语句
+// - 函数体外的注释保持不变，函数体内的注释随函数体替换
+func (gfm *GoFileMeta) ReplaceBody(target any, body string) error {
+	dr, err := gfm.declRange(target)
+	if err != nil {
+		return err
+	}
+	funcDecl, ok := dr.node.(*ast.FuncDecl)
+	if !ok {
+		return fmt.Errorf("target is not a func or method")
+	}
+	if funcDecl.Body == nil {
+		return fmt.Errorf("func '%v' has no body", funcDecl.Name.Name)
+	}
+	if _, _, err = parseSource("package p\n\nfunc _() {\n", body, "\n}\n"); err != nil {
+		return err
+	}
+	return gfm.addEdit(gfm.offset(funcDecl.Body.Lbrace), gfm.offset(funcDecl.Body.Rbrace)+1, "{\n"+body+"\n}")
+}
+
+// ReplaceDoc 替换声明的文档注释，lines 为不包含注释符号的文本，每行一个元素
+// - 没有文档注释时在声明上方添加
+// - lines 为空时删除文档注释
+// - 支持 func，method，struct，interface，package 级 var，const，struct 的 member，interface 的 method
+func (gfm *GoFileMeta) ReplaceDoc(target any, lines ...string) error {
+	dr, err := gfm.declRange(target)
+	if err != nil {
+		return err
+	}
+	if len(lines) == 0 {
+		if dr.doc == nil {
+			return nil
+		}
+		offset, end := gfm.lineRange(gfm.offset(dr.doc.Pos()), gfm.offset(dr.doc.End()))
+		return gfm.addEdit(offset, end, "")
+	}
+
+	builder := strings.Builder{}
+	for index, line := range lines {
+		if index > 0 {
+			builder.WriteByte('\n')
+		}
+		builder.WriteString(strings.TrimRight("// "+line, " "))
+	}
+	if dr.doc != nil {
+		return gfm.addEdit(gfm.offset(dr.doc.Pos()), gfm.offset(dr.doc.End()), builder.String())
+	}
+	offset := gfm.offset(dr.node.Pos())
+	return gfm.addEdit(offset, offset, builder.String()+"\n")
+}
+
+// AddField 在 struct 的末尾添加 member，src 为 struct 内的 member 声明，可以包含注释和 tag
+func (gfm *GoFileMeta) AddField(gsm *GoStructMeta, src string) error {
+	if _, err := gfm.declRange(gsm); err != nil {
+		return err
+	}
+	fieldList, _, err := parseFields(src)
+	if err != nil {
+		return err
+	}
+	for _, field := range fieldList.List {
+		for _, name := range field.Names {
+			if gsm.SearchMemberMeta(name.Name) != nil {
+				return fmt.Errorf("struct '%v' already has member '%v'", gsm.ident, name.Name)
+			}
+		}
+	}
+
+	structType := gsm.node.(*ast.TypeSpec).Type.(*ast.StructType)
+	offset := gfm.offset(structType.Fields.Closing)
+	lineStart := offset
+	for lineStart > 0 && (gfm.content[lineStart-1] == ' ' || gfm.content[lineStart-1] == '\t') {
+		lineStart--
+	}
+	if lineStart > 0 && gfm.content[lineStart-1] == '\n' {
+		// 右括号独占一行时插入到右括号所在行的行首
+		return gfm.addEdit(lineStart, lineStart, src+"\n")
+	}
+	return gfm.addEdit(offset, offset, "\n"+src+"\n")
+}
+
+// ReplaceField 使用源码替换 struct 的 member 的声明，src 为 struct 内的一个 member 声明
+// - 与其他标识共享同一个声明时一起替换: A, B int
+// - src 没有文档注释或行尾注释时，保留原 member 的注释
+func (gfm *GoFileMeta) ReplaceField(member *GoVarMeta, src string) error {
+	dr, err := gfm.declRange(member)
+	if err != nil {
+		return err
+	}
+	if _, ok := dr.node.(*ast.Field); !ok {
+		return fmt.Errorf("member '%v' is not a struct field", member.ident)
+	}
+	fieldList, srcOffset, err := parseFields(src)
+	if err != nil {
+		return err
+	}
+	if len(fieldList.List) != 1 {
+		return fmt.Errorf("source must contain exactly one field, got %v", len(fieldList.List))
+	}
+	return gfm.replaceRange(dr, newDeclRange(fieldList.List[0]), src, srcOffset)
+}
+
+// RemoveField 删除 struct 的 member，以及其文档注释和行尾注释
+// - 与其他标识共享同一个声明时只删除该标识: A, B int -> B int
+func (gfm *GoFileMeta) RemoveField(member *GoVarMeta) error {
+	dr, err := gfm.declRange(member)
+	if err != nil {
+		return err
+	}
+	field, ok := dr.node.(*ast.Field)
+	if !ok {
+		return fmt.Errorf("member '%v' is not a struct field", member.ident)
+	}
+	if len(field.Names) < 2 {
+		return gfm.removeRange(dr)
+	}
+	for index, name := range field.Names {
+		if name.Name != member.ident {
+			continue
+		}
+		if index == 0 {
+			return gfm.addEdit(gfm.offset(name.Pos()), gfm.offset(field.Names[1].Pos()), "")
+		}
+		return gfm.addEdit(gfm.offset(field.Names[index-1].End()), gfm.offset(name.End()), "")
+	}
+	return fmt.Errorf("member '%v' is not declared in field", member.ident)
+}
+
+// Preview 获取应用所有尚未写回的修改后，gofmt 格式化的文件内容
+func (gfm *GoFileMeta) Preview() ([]byte, error) {
+	if gfm.content == nil {
+		return nil, fmt.Errorf("file '%v' has no content snapshot", gfm.path)
+	}
+	newContent, err := applyTextEdits(gfm.content, gfm.pendingEdits)
+	if err != nil {
+		return nil, err
+	}
+	formatted, err := format.Source(newContent)
+	if err != nil {
+		return nil, fmt.Errorf("format source occurs error: %v", err)
+	}
+	return formatted, nil
+}
+
+// Save 将所有尚未写回的修改以 gofmt 格式写回文件
+// - 文件内容与提取时不一致时，视为文件已被修改，不会写入
+// - 写回后基于旧文件内容提取的 meta 数据将失效，需要重新提取
+func (gfm *GoFileMeta) Save() error {
+	fileStat, err := os.Stat(gfm.path)
+	if err != nil {
+		return err
+	}
+	diskContent, err := os.ReadFile(gfm.path)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(diskContent, gfm.content) {
+		return fmt.Errorf("file '%v' changed on disk since extraction", gfm.path)
+	}
+	formatted, err := gfm.Preview()
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(gfm.path, formatted, fileStat.Mode().Perm()); err != nil {
+		return err
+	}
+	gfm.pendingEdits = nil
+	return nil
+}
+
+// DiscardEdits 丢弃所有尚未写回的修改
+func (gfm *GoFileMeta) DiscardEdits() {
+	gfm.pendingEdits = nil
+}
+
+// declRange 获取文件内 meta 数据的声明的范围
+func (gfm *GoFileMeta) declRange(target any) (*declRange, error) {
+	var m *meta
+	switch t := target.(type) {
+	case *GoFuncMeta:
+		m = t.meta
+	case *GoMethodMeta:
+		m = t.meta
+	case *GoStructMeta:
+		m = t.meta
+	case *GoInterfaceMeta:
+		m = t.meta
+	case *GoInterfaceMethodMeta:
+		m = t.meta
+	case *GoVarMeta:
+		m = t.meta
+	default:
+		return nil, fmt.Errorf("unsupported target type %T", target)
+	}
+	if gfm.content == nil {
+		return nil, fmt.Errorf("file '%v' has no content snapshot", gfm.path)
+	}
+	if m == nil || m.node == nil || filepath.Clean(m.path) != filepath.Clean(gfm.path) {
+		return nil, fmt.Errorf("target is not declared in file '%v'", gfm.path)
+	}
+
+	fileAST := gfm.node.(*ast.File)
+	switch node := m.node.(type) {
+	case *ast.FuncDecl:
+		for _, decl := range fileAST.Decls {
+			if decl == node {
+				return newDeclRange(node), nil
+			}
+		}
+	case *ast.TypeSpec, *ast.ValueSpec:
+		if genDecl := gfm.genDeclOf(node); genDecl != nil {
+			if !genDecl.Lparen.IsValid() {
+				return newDeclRange(genDecl), nil
+			}
+			return newDeclRange(node), nil
+		}
+	case *ast.Field:
+		// 只支持 struct 的 member 和 interface 的 method，不支持 func 的参数
+		found := false
+		ast.Inspect(fileAST, func(n ast.Node) bool {
+			var fieldList *ast.FieldList
+			switch typeNode := n.(type) {
+			case *ast.StructType:
+				fieldList = typeNode.Fields
+			case *ast.InterfaceType:
+				fieldList = typeNode.Methods
+			case *ast.FuncType:
+				return false
+			}
+			if fieldList != nil {
+				for _, field := range fieldList.List {
+					found = found || field == node
+				}
+			}
+			return !found
+		})
+		if found {
+			return newDeclRange(node), nil
+		}
+	}
+	return nil, fmt.Errorf("target is not a declaration of file '%v'", gfm.path)
+}
+
+// genDeclOf 获取文件内包含 ast 节点的顶层 *ast.GenDecl，节点本身为 *ast.GenDecl 时返回自身
+func (gfm *GoFileMeta) genDeclOf(node ast.Node) *ast.GenDecl {
+	for _, decl := range gfm.node.(*ast.File).Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		if ast.Node(genDecl) == node {
+			return genDecl
+		}
+		for _, spec := range genDecl.Specs {
+			if ast.Node(spec) == node {
+				return genDecl
+			}
+		}
+	}
+	return nil
+}
+
+// valueSpecNames 获取 var，const 声明的所有标识，其他声明为 nil
+func valueSpecNames(node ast.Node) []string {
+	if genDecl, ok := node.(*ast.GenDecl); ok && len(genDecl.Specs) == 1 {
+		node = genDecl.Specs[0]
+	}
+	valueSpec, ok := node.(*ast.ValueSpec)
+	if !ok {
+		return nil
+	}
+	names := make([]string, 0, len(valueSpec.Names))
+	for _, name := range valueSpec.Names {
+		names = append(names, name.Name)
+	}
+	return names
+}
+
+// replaceRange 使用源码中的声明替换文件内的声明
+// - 源码中的声明有文档注释时替换原有的文档注释，否则保留
+// - 源码中的声明有行尾注释时替换原有的行尾注释，否则保留
+func (gfm *GoFileMeta) replaceRange(dr, srcRange *declRange, src string, srcOffset func(token.Pos) int) error {
+	offset, end := gfm.offset(dr.node.Pos()), gfm.offset(dr.node.End())
+	srcStart, srcEnd := srcOffset(srcRange.node.Pos()), srcOffset(srcRange.node.End())
+	newText := src[srcStart:srcEnd]
+	if srcRange.doc != nil {
+		newText = src[srcOffset(srcRange.doc.Pos()):srcOffset(srcRange.doc.End())] + "\n" + newText
+		if dr.doc != nil {
+			offset = gfm.offset(dr.doc.Pos())
+		}
+	}
+	if srcRange.comment != nil {
+		newText += src[srcEnd:srcOffset(srcRange.comment.End())]
+		if dr.comment != nil {
+			end = gfm.offset(dr.comment.End())
+		}
+	}
+	return gfm.addEdit(offset, end, newText)
+}
+
+// removeRange 删除文件内的声明，以及其文档注释和行尾注释，声明独占的行一并删除
+func (gfm *GoFileMeta) removeRange(dr *declRange) error {
+	offset, end := gfm.offset(dr.node.Pos()), gfm.offset(dr.node.End())
+	if dr.doc != nil {
+		offset = gfm.offset(dr.doc.Pos())
+	}
+	if dr.comment != nil {
+		end = gfm.offset(dr.comment.End())
+	}
+	offset, end = gfm.lineRange(offset, end)
+	return gfm.addEdit(offset, end, "")
+}
+
+// addEdit 添加尚未写回的 文本替换，与已有的修改范围重叠时返回错误
+func (gfm *GoFileMeta) addEdit(offset, end int, newText string) error {
+	edit := newGoTextEdit(gfm.path, gfm.content, offset, end, newText)
+	for _, pendingEdit := range gfm.pendingEdits {
+		if pendingEdit.overlaps(edit) {
+			return fmt.Errorf("edit at offset %v overlaps with pending edit at offset %v", offset, pendingEdit.offset)
+		}
+	}
+	gfm.pendingEdits = append(gfm.pendingEdits, edit)
+	return nil
+}
+
+// offset 获取文件内位置的字节偏移
+func (gfm *GoFileMeta) offset(pos token.Pos) int {
+	return gfm.fileSet.Position(pos).Offset
+}
+
+// lineRange 范围前后在同一行内只有空白时，扩展到整行，包含行尾的换行符
+func (gfm *GoFileMeta) lineRange(offset, end int) (int, int) {
+	lineStart := offset
+	for lineStart > 0 && (gfm.content[lineStart-1] == ' ' || gfm.content[lineStart-1] == '\t') {
+		lineStart--
+	}
+	lineEnd := end
+	for lineEnd < len(gfm.content) && (gfm.content[lineEnd] == ' ' || gfm.content[lineEnd] == '\t' || gfm.content[lineEnd] == '\r') {
+		lineEnd++
+	}
+	if (lineStart > 0 && gfm.content[lineStart-1] != '\n') || (lineEnd < len(gfm.content) && gfm.content[lineEnd] != '\n') {
+		return offset, end
+	}
+	if lineEnd < len(gfm.content) {
+		lineEnd++
+	}
+	return lineStart, lineEnd
+}
+
+// overlaps 两个 文本替换 的范围是否重叠，插入位于另一个替换范围的边界时不视为重叠
+func (gte *GoTextEdit) overlaps(other *GoTextEdit) bool {
+	if gte.offset == gte.end || other.offset == other.end {
+		return (gte.offset > other.offset && gte.offset < other.end) || (other.offset > gte.offset && other.offset < gte.end)
+	}
+	return gte.offset < other.end && other.offset < gte.end
+}
+
+// parseSource 将源码片段包裹为完整的文件并解析，返回将位置转换为源码片段内的字节偏移的方法
+func parseSource(prefix, src, suffix string) (*ast.File, func(token.Pos) int, error) {
+	fileSet := token.NewFileSet()
+	fileAST, err := parser.ParseFile(fileSet, "", prefix+src+suffix, parser.ParseComments)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse source occurs error: %v", err)
+	}
+	return fileAST, func(pos token.Pos) int { return fileSet.Position(pos).Offset - len(prefix) }, nil
+}
+
+// parseFields 解析 struct 内的 member 声明
+func parseFields(src string) (*ast.FieldList, func(token.Pos) int, error) {
+	fileAST, srcOffset, err := parseSource("package p\n\ntype _ struct {\n", src, "\n}\n")
+	if err != nil {
+		return nil, nil, err
+	}
+	fieldList := fileAST.Decls[0].(*ast.GenDecl).Specs[0].(*ast.TypeSpec).Type.(*ast.StructType).Fields
+	if len(fieldList.List) == 0 {
+		return nil, nil, fmt.Errorf("source contains no field")
+	}
+	return fieldList, srcOffset, nil
+}
+
+// funcDeclKind func 声明的种类
+func funcDeclKind(funcDecl *ast.FuncDecl) string {
+	if funcDecl.Recv != nil {
+		return "method"
+	}
+	return "func"
+}
+
+// -------------------------------- unit test --------------------------------
+
+func (gfm *GoFileMeta) PendingEdits() []*GoTextEdit { return gfm.pendingEdits }
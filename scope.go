@@ -0,0 +1,512 @@
+package extractor
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// scopeObjectKind 函数内局部标识的种类
+type scopeObjectKind int
+
+const (
+	scopeObjectReceiver scopeObjectKind = iota + 1 // method 的 receiver
+	scopeObjectParam                               // 参数
+	scopeObjectResult                              // 命名返回值
+	scopeObjectVar                                 // 局部变量: :=，var，range，type switch
+	scopeObjectConst                               // 局部常量
+	scopeObjectType                                // 局部类型以及类型参数
+)
+
+// scopeObject 函数内声明的局部标识
+type scopeObject struct {
+	// 标识的种类
+	kind scopeObjectKind
+
+	// 声明标识的 ast 节点
+	ident *ast.Ident
+
+	// 声明所在的 ast 节点: *ast.Field，*ast.AssignStmt，*ast.ValueSpec，*ast.RangeStmt，*ast.TypeSwitchStmt，*ast.TypeSpec
+	decl ast.Node
+
+	// 声明的类型表达式，未声明类型时为 nil
+	typeExpr ast.Expr
+
+	// 初始值表达式，未赋初始值时为 nil
+	// - a, b := f() -> f()，valueIndex 为返回值的下标
+	// - for k, v := range x -> x，valueIndex 为 0 或 1
+	// - switch v := x.(type) -> x
+	value ast.Expr
+
+	// 初始值为多返回值或 range 时的下标
+	valueIndex int
+
+	// 标识所属的作用域
+	scope *scope
+
+	// 读取该标识的所有 ast 节点，按照源码顺序
+	reads []*ast.Ident
+
+	// 写入该标识的所有 ast 节点，按照源码顺序，不包含声明
+	writes []*ast.Ident
+}
+
+// scope 函数内的词法作用域
+type scope struct {
+	parent *scope
+
+	// 作用域对应的 ast 节点: *ast.FuncDecl，*ast.FuncLit，*ast.BlockStmt，*ast.IfStmt，*ast.ForStmt，*ast.RangeStmt，*ast.SwitchStmt，*ast.TypeSwitchStmt，*ast.CaseClause，*ast.CommClause
+	node ast.Node
+
+	// 作用域内声明的标识
+	// - key: 标识名称
+	objects map[string]*scopeObject
+
+	children []*scope
+}
+
+// scopeInfo 函数的作用域分析结果，不依赖已废弃的 ast.Object
+type scopeInfo struct {
+	root *scope
+
+	// 所有局部标识，按照声明顺序
+	objects []*scopeObject
+
+	// 声明标识的 ast 节点到局部标识
+	defs map[*ast.Ident]*scopeObject
+
+	// 使用标识的 ast 节点到局部标识，不在其中的标识为 package 级标识，import 或内置标识
+	uses map[*ast.Ident]*scopeObject
+
+	// 使用标识的 ast 节点所在的作用域
+	useScopes map[*ast.Ident]*scope
+
+	// ast 节点对应的作用域
+	scopes map[ast.Node]*scope
+}
+
+// newScopeInfo 分析 *ast.FuncDecl 或 *ast.FuncLit 内的所有作用域
+func newScopeInfo(node ast.Node) *scopeInfo {
+	r := &scopeResolver{info: &scopeInfo{
+		objects:   make([]*scopeObject, 0),
+		defs:      make(map[*ast.Ident]*scopeObject),
+		uses:      make(map[*ast.Ident]*scopeObject),
+		useScopes: make(map[*ast.Ident]*scope),
+		scopes:    make(map[ast.Node]*scope),
+	}}
+	switch n := node.(type) {
+	case *ast.FuncDecl:
+		r.walkFunc(n, n.Recv, n.Type, n.Body)
+	case *ast.FuncLit:
+		r.walkFunc(n, nil, n.Type, n.Body)
+	}
+	r.info.root = r.info.scopes[node]
+	return r.info
+}
+
+// lookup 在作用域链上查找标识
+func (s *scope) lookup(name string) *scopeObject {
+	for ; s != nil; s = s.parent {
+		if obj, has := s.objects[name]; has {
+			return obj
+		}
+	}
+	return nil
+}
+
+// isAncestorOf 作用域是否是另一个作用域自身或其祖先
+func (s *scope) isAncestorOf(other *scope) bool {
+	for ; other != nil; other = other.parent {
+		if other == s {
+			return true
+		}
+	}
+	return false
+}
+
+// pos 作用域的起始位置
+func (s *scope) pos() token.Pos { return s.node.Pos() }
+
+// end 作用域的结束位置
+func (s *scope) end() token.Pos { return s.node.End() }
+
+// scopeResolver 按照源码顺序遍历函数，声明之后的标识才可见
+type scopeResolver struct {
+	info    *scopeInfo
+	current *scope
+}
+
+func (r *scopeResolver) open(node ast.Node) {
+	s := &scope{parent: r.current, node: node, objects: make(map[string]*scopeObject)}
+	if r.current != nil {
+		r.current.children = append(r.current.children, s)
+	}
+	r.current = s
+	r.info.scopes[node] = s
+}
+
+func (r *scopeResolver) close() {
+	r.current = r.current.parent
+}
+
+// declare 在当前作用域内声明标识，_ 不会被声明
+func (r *scopeResolver) declare(kind scopeObjectKind, ident *ast.Ident, decl ast.Node, typeExpr, value ast.Expr, valueIndex int) *scopeObject {
+	if ident == nil || ident.Name == "_" {
+		return nil
+	}
+	obj := &scopeObject{kind: kind, ident: ident, decl: decl, typeExpr: typeExpr, value: value, valueIndex: valueIndex, scope: r.current}
+	r.current.objects[ident.Name] = obj
+	r.info.objects = append(r.info.objects, obj)
+	if _, has := r.info.defs[ident]; !has {
+		r.info.defs[ident] = obj
+	}
+	return obj
+}
+
+// use 解析标识的使用
+func (r *scopeResolver) use(ident *ast.Ident, write bool) {
+	r.info.useScopes[ident] = r.current
+	obj := r.current.lookup(ident.Name)
+	if obj == nil {
+		return
+	}
+	r.info.uses[ident] = obj
+	if write {
+		obj.writes = append(obj.writes, ident)
+	} else {
+		obj.reads = append(obj.reads, ident)
+	}
+}
+
+// declareFieldList 声明参数表内的所有标识
+func (r *scopeResolver) declareFieldList(kind scopeObjectKind, fieldList *ast.FieldList) {
+	if fieldList == nil {
+		return
+	}
+	for _, field := range fieldList.List {
+		r.walkExpr(field.Type)
+	}
+	for _, field := range fieldList.List {
+		for _, name := range field.Names {
+			r.declare(kind, name, field, field.Type, nil, 0)
+		}
+	}
+}
+
+func (r *scopeResolver) walkFunc(node ast.Node, recv *ast.FieldList, funcType *ast.FuncType, body *ast.BlockStmt) {
+	r.open(node)
+	if funcType.TypeParams != nil {
+		for _, field := range funcType.TypeParams.List {
+			for _, name := range field.Names {
+				r.declare(scopeObjectType, name, field, field.Type, nil, 0)
+			}
+		}
+		for _, field := range funcType.TypeParams.List {
+			r.walkExpr(field.Type)
+		}
+	}
+	if recv != nil {
+		// receiver 的类型参数: func (s *S[T]) M()
+		for _, field := range recv.List {
+			typeExpr := field.Type
+			if starExpr, ok := typeExpr.(*ast.StarExpr); ok {
+				typeExpr = starExpr.X
+			}
+			switch indexExpr := typeExpr.(type) {
+			case *ast.IndexExpr:
+				if ident, ok := indexExpr.Index.(*ast.Ident); ok {
+					r.declare(scopeObjectType, ident, field, nil, nil, 0)
+				}
+			case *ast.IndexListExpr:
+				for _, index := range indexExpr.Indices {
+					if ident, ok := index.(*ast.Ident); ok {
+						r.declare(scopeObjectType, ident, field, nil, nil, 0)
+					}
+				}
+			}
+			for _, name := range field.Names {
+				r.declare(scopeObjectReceiver, name, field, field.Type, nil, 0)
+			}
+		}
+	}
+	r.declareFieldList(scopeObjectParam, funcType.Params)
+	r.declareFieldList(scopeObjectResult, funcType.Results)
+	if body != nil {
+		// 函数体与参数属于同一个作用域
+		r.walkStmtList(body.List)
+	}
+	r.close()
+}
+
+func (r *scopeResolver) walkStmtList(stmts []ast.Stmt) {
+	for _, stmt := range stmts {
+		r.walkStmt(stmt)
+	}
+}
+
+func (r *scopeResolver) walkStmt(stmt ast.Stmt) {
+	switch s := stmt.(type) {
+	case *ast.BlockStmt:
+		r.open(s)
+		r.walkStmtList(s.List)
+		r.close()
+	case *ast.ExprStmt:
+		r.walkExpr(s.X)
+	case *ast.AssignStmt:
+		r.walkAssign(s)
+	case *ast.DeclStmt:
+		r.walkDecl(s.Decl.(*ast.GenDecl))
+	case *ast.IncDecStmt:
+		r.walkLhs(s.X, true)
+	case *ast.ReturnStmt:
+		for _, result := range s.Results {
+			r.walkExpr(result)
+		}
+	case *ast.IfStmt:
+		r.open(s)
+		if s.Init != nil {
+			r.walkStmt(s.Init)
+		}
+		r.walkExpr(s.Cond)
+		r.walkStmt(s.Body)
+		if s.Else != nil {
+			r.walkStmt(s.Else)
+		}
+		r.close()
+	case *ast.ForStmt:
+		r.open(s)
+		if s.Init != nil {
+			r.walkStmt(s.Init)
+		}
+		if s.Cond != nil {
+			r.walkExpr(s.Cond)
+		}
+		if s.Post != nil {
+			r.walkStmt(s.Post)
+		}
+		r.walkStmt(s.Body)
+		r.close()
+	case *ast.RangeStmt:
+		r.walkExpr(s.X)
+		r.open(s)
+		if s.Tok == token.DEFINE {
+			for index, expr := range []ast.Expr{s.Key, s.Value} {
+				if ident, ok := expr.(*ast.Ident); ok {
+					r.declare(scopeObjectVar, ident, s, nil, s.X, index)
+				}
+			}
+		} else {
+			for _, expr := range []ast.Expr{s.Key, s.Value} {
+				if expr != nil {
+					r.walkLhs(expr, false)
+				}
+			}
+		}
+		r.walkStmt(s.Body)
+		r.close()
+	case *ast.SwitchStmt:
+		r.open(s)
+		if s.Init != nil {
+			r.walkStmt(s.Init)
+		}
+		if s.Tag != nil {
+			r.walkExpr(s.Tag)
+		}
+		for _, clause := range s.Body.List {
+			caseClause := clause.(*ast.CaseClause)
+			for _, expr := range caseClause.List {
+				r.walkExpr(expr)
+			}
+			r.open(caseClause)
+			r.walkStmtList(caseClause.Body)
+			r.close()
+		}
+		r.close()
+	case *ast.TypeSwitchStmt:
+		r.open(s)
+		if s.Init != nil {
+			r.walkStmt(s.Init)
+		}
+		var (
+			binding *ast.Ident
+			x       ast.Expr
+		)
+		switch assign := s.Assign.(type) {
+		case *ast.AssignStmt:
+			binding = assign.Lhs[0].(*ast.Ident)
+			x = assign.Rhs[0].(*ast.TypeAssertExpr).X
+		case *ast.ExprStmt:
+			x = assign.X.(*ast.TypeAssertExpr).X
+		}
+		r.walkExpr(x)
+		for _, clause := range s.Body.List {
+			caseClause := clause.(*ast.CaseClause)
+			for _, expr := range caseClause.List {
+				r.walkExpr(expr)
+			}
+			r.open(caseClause)
+			if binding != nil {
+				// 只有一个类型的 case 中绑定的变量为该类型，否则为 x 的类型
+				var typeExpr ast.Expr
+				if len(caseClause.List) == 1 {
+					if ident, ok := caseClause.List[0].(*ast.Ident); !ok || ident.Name != "nil" {
+						typeExpr = caseClause.List[0]
+					}
+				}
+				r.declare(scopeObjectVar, binding, s, typeExpr, x, 0)
+			}
+			r.walkStmtList(caseClause.Body)
+			r.close()
+		}
+		r.close()
+	case *ast.SelectStmt:
+		for _, clause := range s.Body.List {
+			commClause := clause.(*ast.CommClause)
+			r.open(commClause)
+			if commClause.Comm != nil {
+				r.walkStmt(commClause.Comm)
+			}
+			r.walkStmtList(commClause.Body)
+			r.close()
+		}
+	case *ast.LabeledStmt:
+		r.walkStmt(s.Stmt)
+	case *ast.GoStmt:
+		r.walkExpr(s.Call)
+	case *ast.DeferStmt:
+		r.walkExpr(s.Call)
+	case *ast.SendStmt:
+		r.walkExpr(s.Chan)
+		r.walkExpr(s.Value)
+	}
+}
+
+func (r *scopeResolver) walkAssign(s *ast.AssignStmt) {
+	for _, rhs := range s.Rhs {
+		r.walkExpr(rhs)
+	}
+	if s.Tok != token.DEFINE {
+		for _, lhs := range s.Lhs {
+			r.walkLhs(lhs, s.Tok != token.ASSIGN)
+		}
+		return
+	}
+	for index, lhs := range s.Lhs {
+		ident, ok := lhs.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		if _, redeclared := r.current.objects[ident.Name]; redeclared {
+			// := 左侧已在当前作用域声明的标识视为赋值
+			r.use(ident, true)
+			continue
+		}
+		value, valueIndex := ast.Expr(nil), 0
+		if len(s.Lhs) == len(s.Rhs) {
+			value = s.Rhs[index]
+		} else if len(s.Rhs) == 1 {
+			value, valueIndex = s.Rhs[0], index
+		}
+		r.declare(scopeObjectVar, ident, s, nil, value, valueIndex)
+	}
+}
+
+// walkLhs 遍历赋值语句的左值，标识视为写入，op 为 true 时同时视为读取: a += 1
+func (r *scopeResolver) walkLhs(lhs ast.Expr, op bool) {
+	ident, ok := lhs.(*ast.Ident)
+	if !ok {
+		// a.b = 1，a[i] = 1，*p = 1 视为读取 a，p
+		r.walkExpr(lhs)
+		return
+	}
+	if op {
+		r.use(ident, false)
+	}
+	r.use(ident, true)
+}
+
+func (r *scopeResolver) walkDecl(genDecl *ast.GenDecl) {
+	for _, spec := range genDecl.Specs {
+		switch s := spec.(type) {
+		case *ast.ValueSpec:
+			kind := scopeObjectVar
+			if genDecl.Tok == token.CONST {
+				kind = scopeObjectConst
+			}
+			if s.Type != nil {
+				r.walkExpr(s.Type)
+			}
+			for _, value := range s.Values {
+				r.walkExpr(value)
+			}
+			for index, name := range s.Names {
+				value, valueIndex := ast.Expr(nil), 0
+				if len(s.Names) == len(s.Values) {
+					value = s.Values[index]
+				} else if len(s.Values) == 1 {
+					value, valueIndex = s.Values[0], index
+				}
+				r.declare(kind, name, s, s.Type, value, valueIndex)
+			}
+		case *ast.TypeSpec:
+			// 类型的作用域从标识开始，允许递归类型
+			r.declare(scopeObjectType, s.Name, s, s.Type, nil, 0)
+			r.walkExpr(s.Type)
+		}
+	}
+}
+
+func (r *scopeResolver) walkExpr(expr ast.Expr) {
+	if expr == nil {
+		return
+	}
+	ast.Inspect(expr, func(n ast.Node) bool {
+		switch e := n.(type) {
+		case *ast.Ident:
+			r.use(e, false)
+		case *ast.FuncLit:
+			r.walkFunc(e, nil, e.Type, e.Body)
+			return false
+		case *ast.SelectorExpr:
+			// 只有 X 可能是局部标识
+			r.walkExpr(e.X)
+			return false
+		case *ast.CompositeLit:
+			r.walkExpr(e.Type)
+			_, isMap := e.Type.(*ast.MapType)
+			for _, elt := range e.Elts {
+				if keyValue, ok := elt.(*ast.KeyValueExpr); ok {
+					// struct 字面量的 key 是字段名称
+					if _, isIdent := keyValue.Key.(*ast.Ident); !isIdent || isMap {
+						r.walkExpr(keyValue.Key)
+					}
+					r.walkExpr(keyValue.Value)
+					continue
+				}
+				r.walkExpr(elt)
+			}
+			return false
+		case *ast.StructType:
+			r.walkFieldTypes(e.Fields)
+			return false
+		case *ast.InterfaceType:
+			r.walkFieldTypes(e.Methods)
+			return false
+		case *ast.FuncType:
+			r.walkFieldTypes(e.TypeParams)
+			r.walkFieldTypes(e.Params)
+			r.walkFieldTypes(e.Results)
+			return false
+		}
+		return true
+	})
+}
+
+// walkFieldTypes 只遍历字段的类型，字段名称不是局部标识
+func (r *scopeResolver) walkFieldTypes(fieldList *ast.FieldList) {
+	if fieldList == nil {
+		return
+	}
+	for _, field := range fieldList.List {
+		r.walkExpr(field.Type)
+	}
+}
@@ -0,0 +1,417 @@
+package extractor
+
+import (
+	"fmt"
+	"go/token"
+	"path"
+	"regexp"
+	"strings"
+)
+
+type SelectorKind int
+
+const (
+	SELECTOR_KIND_FUNC             = iota + 1 // package 级 func
+	SELECTOR_KIND_METHOD                      // struct 的 method
+	SELECTOR_KIND_STRUCT                      // struct
+	SELECTOR_KIND_INTERFACE                   // interface
+	SELECTOR_KIND_INTERFACE_METHOD            // interface 的 method
+	SELECTOR_KIND_FIELD                       // struct 的字段
+	SELECTOR_KIND_VAR                         // package 级 var
+	SELECTOR_KIND_CONST                       // package 级 const
+)
+
+// selectorKindMap 选择器中 kind: 的取值
+var selectorKindMap = map[string]SelectorKind{
+	"func":      SELECTOR_KIND_FUNC,
+	"method":    SELECTOR_KIND_METHOD,
+	"struct":    SELECTOR_KIND_STRUCT,
+	"interface": SELECTOR_KIND_INTERFACE,
+	"imethod":   SELECTOR_KIND_INTERFACE_METHOD,
+	"field":     SELECTOR_KIND_FIELD,
+	"var":       SELECTOR_KIND_VAR,
+	"const":     SELECTOR_KIND_CONST,
+}
+
+// GoSelector 解析后的 meta 选择器，所有条件同时满足时匹配
+// - 路径: pkg/module.*Service.Handle* -> package 为 pkg/module，struct/interface 为 *Service，method/字段为 Handle*
+// - 路径: pkg/module.New* -> package 为 pkg/module，func/struct/interface/var/const 为 New*
+// - 路径: pkg/....*Service.Handle* -> package 为 pkg 及其所有子 package
+// - 条件: kind:method,func，pkg:pkg/...，name:Handle*，owner:*Service，recv:*Player，exported，unexported
+// - recv:*Player 仅匹配 receiver 为 *Player 的 method，recv:Player 匹配 receiver 为 Player 或 *Player 的 method
+// - 名称使用 path.Match 的通配符匹配，使用 /.../ 包围时为正则表达式匹配: name:/^(Get|Set)[A-Z]/
+// - package 使用 path.Match 的通配符匹配导入路径或相对于模块的路径，以 /... 结尾时同时匹配所有子 package
+// - 模块 github.com/acme/game 内的 pkg/module.*Service 与 github.com/acme/game/pkg/module.*Service 等价
+type GoSelector struct {
+	// 原始的选择器
+	selector string
+
+	// 允许的种类，每组内为并集，组之间为交集，为空时允许所有种类
+	kindSets []map[SelectorKind]struct{}
+
+	// package 导入路径的匹配条件
+	packagePatterns []string
+
+	// 标识的匹配条件
+	nameMatchers []selectorMatcher
+
+	// method 的 receiver 或字段和 interface method 所属的类型的匹配条件
+	ownerMatchers []selectorMatcher
+
+	// method 的 receiver 表达式的匹配条件: *Player 仅匹配指针 receiver，Player 匹配所有 receiver
+	recvMatchers []selectorMatcher
+
+	// 是否导出，为 nil 时不限制
+	exported *bool
+}
+
+// selectorMatcher 标识的匹配条件
+type selectorMatcher func(string) bool
+
+// GoSelectorMatch 选择器匹配到的 meta 数据，根据种类获取对应的 meta 数据
+type GoSelectorMatch struct {
+	// 匹配到的种类
+	kind SelectorKind
+
+	// 所属的 package 的 meta 数据
+	packageMeta *GoPackageMeta
+
+	// 所属的 package 的导入路径，与匹配 package 条件的路径一致
+	importPath string
+
+	// 标识
+	ident string
+
+	// method 的 receiver 类型，字段和 interface method 所属的类型
+	owner string
+
+	// method 的 receiver 表达式: *Player，Player
+	recv string
+
+	funcMeta            *GoFuncMeta
+	methodMeta          *GoMethodMeta
+	structMeta          *GoStructMeta
+	interfaceMeta       *GoInterfaceMeta
+	interfaceMethodMeta *GoInterfaceMethodMeta
+	varMeta             *GoVarMeta
+}
+
+// -------------------------------- extractor --------------------------------
+
+// ParseSelector 解析 meta 选择器
+func ParseSelector(selector string) (*GoSelector, error) {
+	gs := &GoSelector{selector: selector}
+	for _, term := range strings.Fields(selector) {
+		switch term {
+		case "exported", "unexported":
+			exported := term == "exported"
+			if gs.exported != nil && *gs.exported != exported {
+				return nil, fmt.Errorf("selector %q can not be both exported and unexported", selector)
+			}
+			gs.exported = &exported
+			continue
+		}
+		key, value, hasKey := strings.Cut(term, ":")
+		if !hasKey {
+			if err := gs.parsePath(term); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if len(value) == 0 {
+			return nil, fmt.Errorf("selector %q has empty value for %v", selector, key)
+		}
+		switch key {
+		case "kind":
+			kinds := make([]SelectorKind, 0)
+			for _, kindValue := range strings.Split(value, ",") {
+				kind, has := selectorKindMap[kindValue]
+				if !has {
+					return nil, fmt.Errorf("selector %q has unknown kind %q", selector, kindValue)
+				}
+				kinds = append(kinds, kind)
+			}
+			gs.addKinds(kinds...)
+		case "pkg":
+			if err := checkPackagePattern(value); err != nil {
+				return nil, err
+			}
+			gs.packagePatterns = append(gs.packagePatterns, value)
+		case "name", "owner", "recv":
+			pattern := value
+			if key == "recv" {
+				pattern = strings.TrimPrefix(value, "*")
+			}
+			matcher, err := newSelectorMatcher(pattern)
+			if err != nil {
+				return nil, err
+			}
+			switch key {
+			case "name":
+				gs.nameMatchers = append(gs.nameMatchers, matcher)
+			case "owner":
+				gs.ownerMatchers = append(gs.ownerMatchers, matcher)
+			case "recv":
+				gs.recvMatchers = append(gs.recvMatchers, newRecvMatcher(value, matcher))
+				gs.addKinds(SELECTOR_KIND_METHOD)
+			}
+		default:
+			return nil, fmt.Errorf("selector %q has unknown key %q", selector, key)
+		}
+	}
+	return gs, nil
+}
+
+// parsePath 解析路径形式的条件，package 为最后一个 / 之后的第一个 . 之前的部分
+// - pkg/...: 之后的部分以 . 分隔: pkg/....*Service.Handle*
+func (gs *GoSelector) parsePath(term string) error {
+	var packagePattern string
+	var segments []string
+	if index := strings.Index(term, "/...."); index >= 0 {
+		packagePattern, segments = term[:index+4], strings.Split(term[index+5:], ".")
+	} else {
+		packagePart, rest := "", term
+		if index := strings.LastIndex(term, "/"); index >= 0 {
+			packagePart, rest = term[:index+1], term[index+1:]
+		}
+		segments = strings.Split(rest, ".")
+		packagePattern, segments = packagePart+segments[0], segments[1:]
+	}
+	if len(segments) < 1 || len(segments) > 2 {
+		return fmt.Errorf("selector %q has invalid path %q", gs.selector, term)
+	}
+	if err := checkPackagePattern(packagePattern); err != nil {
+		return err
+	}
+	gs.packagePatterns = append(gs.packagePatterns, packagePattern)
+	matchers := make([]selectorMatcher, 0, len(segments))
+	for _, segment := range segments {
+		matcher, err := newSelectorMatcher(segment)
+		if err != nil {
+			return err
+		}
+		matchers = append(matchers, matcher)
+	}
+	if len(matchers) == 1 {
+		gs.nameMatchers = append(gs.nameMatchers, matchers[0])
+		gs.addKinds(SELECTOR_KIND_FUNC, SELECTOR_KIND_STRUCT, SELECTOR_KIND_INTERFACE, SELECTOR_KIND_VAR, SELECTOR_KIND_CONST)
+	} else {
+		gs.ownerMatchers = append(gs.ownerMatchers, matchers[0])
+		gs.nameMatchers = append(gs.nameMatchers, matchers[1])
+		gs.addKinds(SELECTOR_KIND_METHOD, SELECTOR_KIND_FIELD, SELECTOR_KIND_INTERFACE_METHOD)
+	}
+	return nil
+}
+
+// addKinds 添加一组允许的种类，组内为并集，组之间为交集
+func (gs *GoSelector) addKinds(kinds ...SelectorKind) {
+	kindSet := make(map[SelectorKind]struct{}, len(kinds))
+	for _, kind := range kinds {
+		kindSet[kind] = struct{}{}
+	}
+	gs.kindSets = append(gs.kindSets, kindSet)
+}
+
+func checkPackagePattern(pattern string) error {
+	if _, err := path.Match(strings.TrimSuffix(pattern, "/..."), ""); err != nil {
+		return fmt.Errorf("invalid package pattern %q: %v", pattern, err)
+	}
+	return nil
+}
+
+// newSelectorMatcher 构造标识的匹配条件，/.../ 包围时为正则表达式，否则为通配符
+func newSelectorMatcher(pattern string) (selectorMatcher, error) {
+	if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid regexp %q: %v", pattern, err)
+		}
+		return re.MatchString, nil
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %v", pattern, err)
+	}
+	return func(s string) bool {
+		matched, _ := path.Match(pattern, s)
+		return matched
+	}, nil
+}
+
+// newRecvMatcher 构造 receiver 的匹配条件
+// - *Player 仅匹配指针 receiver，Player 同时匹配指针和值 receiver
+func newRecvMatcher(pattern string, matcher selectorMatcher) selectorMatcher {
+	if strings.HasPrefix(pattern, "*") && len(pattern) > 1 {
+		return func(recv string) bool {
+			typeIdent, pointer := strings.CutPrefix(recv, "*")
+			return pointer && matcher(typeIdent)
+		}
+	}
+	return func(recv string) bool { return matcher(strings.TrimPrefix(recv, "*")) }
+}
+
+// matchPackage 判断 package 是否满足所有 package 条件，paths 为 package 的导入路径以及相对于模块的路径
+func (gs *GoSelector) matchPackage(paths []string) bool {
+	for _, pattern := range gs.packagePatterns {
+		if !matchPackagePatternPaths(pattern, paths) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchPackagePatternPaths 任意一个路径满足 package 条件时匹配
+func matchPackagePatternPaths(pattern string, paths []string) bool {
+	for _, p := range paths {
+		if matchPackagePattern(pattern, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPackagePattern 使用 path.Match 的通配符匹配导入路径，以 /... 结尾时同时匹配所有子 package
+func matchPackagePattern(pattern, importPath string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "/..."); ok {
+		if matched, _ := path.Match(prefix, importPath); matched {
+			return true
+		}
+		return strings.HasPrefix(importPath, prefix+"/")
+	}
+	matched, _ := path.Match(pattern, importPath)
+	return matched
+}
+
+// Match 判断匹配结果是否满足选择器的所有条件
+func (gs *GoSelector) Match(gsm *GoSelectorMatch) bool {
+	for _, kindSet := range gs.kindSets {
+		if _, has := kindSet[gsm.kind]; !has {
+			return false
+		}
+	}
+	if gs.exported != nil && token.IsExported(gsm.ident) != *gs.exported {
+		return false
+	}
+	for _, matcher := range gs.nameMatchers {
+		if !matcher(gsm.ident) {
+			return false
+		}
+	}
+	for _, matcher := range gs.ownerMatchers {
+		if len(gsm.owner) == 0 || !matcher(gsm.owner) {
+			return false
+		}
+	}
+	for _, matcher := range gs.recvMatchers {
+		if len(gsm.recv) == 0 || !matcher(gsm.recv) {
+			return false
+		}
+	}
+	return true
+}
+
+// Select 使用选择器搜索项目内的 meta 数据
+// - 按照 package 的导入路径排序，package 内按照 func，struct 及其字段和 method，interface 及其 method，var，const 的顺序，同类按照文件名称，声明顺序排序
+func (gpm *GoProjectMeta) Select(selector string) ([]*GoSelectorMatch, error) {
+	gs, err := ParseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	return gpm.SelectBy(gs), nil
+}
+
+// SelectBy 使用已解析的选择器搜索项目内的 meta 数据
+func (gpm *GoProjectMeta) SelectBy(gs *GoSelector) []*GoSelectorMatch {
+	matches := make([]*GoSelectorMatch, 0)
+	add := func(gsm *GoSelectorMatch) {
+		if gs.Match(gsm) {
+			matches = append(matches, gsm)
+		}
+	}
+	for _, packageMeta := range gpm.Packages() {
+		importPath := gpm.packageImportPath(packageMeta)
+		if !gs.matchPackage(gpm.patternPaths(importPath)) {
+			continue
+		}
+		for _, funcMeta := range packageMeta.Funcs() {
+			add(&GoSelectorMatch{kind: SELECTOR_KIND_FUNC, packageMeta: packageMeta, importPath: importPath, ident: funcMeta.Ident(), funcMeta: funcMeta})
+		}
+		for _, structMeta := range packageMeta.Structs() {
+			structIdent := structMeta.Ident()
+			add(&GoSelectorMatch{kind: SELECTOR_KIND_STRUCT, packageMeta: packageMeta, importPath: importPath, ident: structIdent, structMeta: structMeta})
+			for _, memberMeta := range structMeta.Members() {
+				add(&GoSelectorMatch{kind: SELECTOR_KIND_FIELD, packageMeta: packageMeta, importPath: importPath, ident: memberMeta.Ident(), owner: structIdent, structMeta: structMeta, varMeta: memberMeta})
+			}
+			for _, methodMeta := range structMeta.Methods() {
+				recv := structIdent
+				if _, pointerReceiver := extractMethodRecvStruct(methodMeta.funcDecl()); pointerReceiver {
+					recv = "*" + recv
+				}
+				add(&GoSelectorMatch{kind: SELECTOR_KIND_METHOD, packageMeta: packageMeta, importPath: importPath, ident: methodMeta.Ident(), owner: structIdent, recv: recv, structMeta: structMeta, methodMeta: methodMeta, funcMeta: methodMeta.GoFuncMeta})
+			}
+		}
+		for _, interfaceMeta := range packageMeta.Interfaces() {
+			interfaceIdent := interfaceMeta.Ident()
+			add(&GoSelectorMatch{kind: SELECTOR_KIND_INTERFACE, packageMeta: packageMeta, importPath: importPath, ident: interfaceIdent, interfaceMeta: interfaceMeta})
+			for _, interfaceMethodMeta := range interfaceMeta.Methods() {
+				add(&GoSelectorMatch{kind: SELECTOR_KIND_INTERFACE_METHOD, packageMeta: packageMeta, importPath: importPath, ident: interfaceMethodMeta.Ident(), owner: interfaceIdent, interfaceMeta: interfaceMeta, interfaceMethodMeta: interfaceMethodMeta})
+			}
+		}
+		for _, varMeta := range packageMeta.Vars() {
+			add(&GoSelectorMatch{kind: SELECTOR_KIND_VAR, packageMeta: packageMeta, importPath: importPath, ident: varMeta.Ident(), varMeta: varMeta})
+		}
+		for _, constMeta := range packageMeta.Consts() {
+			add(&GoSelectorMatch{kind: SELECTOR_KIND_CONST, packageMeta: packageMeta, importPath: importPath, ident: constMeta.Ident(), varMeta: constMeta})
+		}
+	}
+	return matches
+}
+
+// ID 匹配结果的唯一标识: importPath.F，importPath.(*T).M，importPath.T.Field
+func (gsm *GoSelectorMatch) ID() string {
+	packageIdent := gsm.importPath
+	switch {
+	case len(gsm.recv) > 0 && strings.HasPrefix(gsm.recv, "*"):
+		return fmt.Sprintf("%v.(%v).%v", packageIdent, gsm.recv, gsm.ident)
+	case len(gsm.owner) > 0:
+		return fmt.Sprintf("%v.%v.%v", packageIdent, gsm.owner, gsm.ident)
+	}
+	return fmt.Sprintf("%v.%v", packageIdent, gsm.ident)
+}
+
+// declMeta 匹配到的声明的基本 meta 数据
+func (gsm *GoSelectorMatch) declMeta() *meta {
+	switch gsm.kind {
+	case SELECTOR_KIND_FUNC, SELECTOR_KIND_METHOD:
+		return gsm.funcMeta.meta
+	case SELECTOR_KIND_STRUCT:
+		return gsm.structMeta.meta
+	case SELECTOR_KIND_INTERFACE:
+		return gsm.interfaceMeta.meta
+	case SELECTOR_KIND_INTERFACE_METHOD:
+		return gsm.interfaceMethodMeta.meta
+	}
+	return gsm.varMeta.meta
+}
+
+// Pos 匹配到的 meta 数据在文件中的位置
+func (gsm *GoSelectorMatch) Pos() token.Position {
+	m := gsm.declMeta()
+	return m.position(m.node.Pos())
+}
+
+// -------------------------------- unit test --------------------------------
+
+func (gs *GoSelector) Selector() string                      { return gs.selector }
+func (gsm *GoSelectorMatch) Kind() SelectorKind              { return gsm.kind }
+func (gsm *GoSelectorMatch) PackageMeta() *GoPackageMeta     { return gsm.packageMeta }
+func (gsm *GoSelectorMatch) Ident() string                   { return gsm.ident }
+func (gsm *GoSelectorMatch) Owner() string                   { return gsm.owner }
+func (gsm *GoSelectorMatch) Recv() string                    { return gsm.recv }
+func (gsm *GoSelectorMatch) FuncMeta() *GoFuncMeta           { return gsm.funcMeta }
+func (gsm *GoSelectorMatch) MethodMeta() *GoMethodMeta       { return gsm.methodMeta }
+func (gsm *GoSelectorMatch) StructMeta() *GoStructMeta       { return gsm.structMeta }
+func (gsm *GoSelectorMatch) InterfaceMeta() *GoInterfaceMeta { return gsm.interfaceMeta }
+func (gsm *GoSelectorMatch) InterfaceMethodMeta() *GoInterfaceMethodMeta {
+	return gsm.interfaceMethodMeta
+}
+func (gsm *GoSelectorMatch) VarMeta() *GoVarMeta { return gsm.varMeta }
@@ -23,7 +23,8 @@ type GoStructMeta struct {
 	// - key: method 标识
 	methodMetaMap map[string]*GoMethodMeta
 
-	commentGroup *ast.CommentGroup
+	// struct 的文档注释和行尾注释
+	commentMeta
 }
 
 // newGoStructMeta 通过 ast 构造 struct 的 meta 数据
@@ -33,6 +34,7 @@ func newGoStructMeta(m *meta, ident string, stopExtract ...bool) *GoStructMeta {
 		ident:         ident,
 		memberMetaMap: make(map[string]*GoVarMeta),
 		methodMetaMap: make(map[string]*GoMethodMeta),
+		commentMeta:   newCommentMeta(m.node),
 	}
 	if len(stopExtract) == 0 {
 		gsm.ExtractAll()
@@ -75,12 +77,12 @@ func (gsm *GoStructMeta) ExtractAll() {
 			// 非匿名成员
 			for _, name := range member.Names {
 				memberIdent := name.String()
-				gsm.memberMetaMap[memberIdent] = newGoVarMeta(newMeta(member, gsm.path), memberIdent)
+				gsm.memberMetaMap[memberIdent] = newGoVarMeta(gsm.copyMeta(member), memberIdent)
 			}
 		} else {
 			// 匿名成员
 			// TODO: 使用 GoVariableMeta
-			gvm := newGoVarMeta(newMeta(member, gsm.path), "")
+			gvm := newGoVarMeta(gsm.copyMeta(member), "")
 			gvm.ident = gvm.typeIdent
 			gsm.memberMetaMap[gvm.ident] = gvm
 			// var (
@@ -146,6 +148,40 @@ func (gsm *GoStructMeta) SearchMethodMeta(method string) *GoMethodMeta {
 	return gsm.methodMetaMap[method]
 }
 
+// searchPromotedMethodMeta 搜索 struct 的 method，包括匿名成员提升的 method
+// - 与 memberOwner 相同，按照嵌入的深度逐层搜索，被较浅的同名字段屏蔽的 method 不会被提升
+func (gsm *GoStructMeta) searchPromotedMethodMeta(method string) *GoMethodMeta {
+	if owner := gsm.memberOwner(method); owner != nil {
+		return owner.methodMetaMap[method]
+	}
+	return nil
+}
+
+// implements struct 是否实现了 interface 的所有 method，仅根据 method 标识判断
+// - 包括 interface 嵌入的 interface 的 method，method 集合为空时不视为实现
+func (gsm *GoStructMeta) implements(gim *GoInterfaceMeta) bool {
+	methodIdents := gim.methodIdents()
+	if len(methodIdents) == 0 {
+		return false
+	}
+	for methodIdent := range methodIdents {
+		if gsm.searchPromotedMethodMeta(methodIdent) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Directives 获取 struct 的文档注释内的 编译指令
+func (gsm *GoStructMeta) Directives() []*GoDirectiveMeta {
+	return extractDirectiveMetas(gsm.meta, gsm.doc)
+}
+
+// Annotations 使用注解解析器获取 struct 的文档注释内的所有注解
+func (gsm *GoStructMeta) Annotations(parsers ...AnnotationParser) ([]*GoAnnotationMeta, []*GoAnnotationError) {
+	return extractAnnotationMetas(gsm.meta, gsm, gsm.doc, parsers)
+}
+
 // func SearchGoStructMeta(gfm *GoFileMeta, structName string) *GoStructMeta {
 // 	var structDecl *ast.TypeSpec
 // 	var commentDecl *ast.CommentGroup
@@ -189,17 +225,6 @@ func (gsm *GoStructMeta) MethodMetaMap() map[string]*GoMethodMeta {
 
 // -------------------------------- unit test --------------------------------
 
-func (gsm *GoStructMeta) Doc() []string {
-	if gsm.node == nil || gsm.commentGroup == nil || len(gsm.commentGroup.List) == 0 {
-		return nil
-	}
-	commentSlice := make([]string, 0, len(gsm.commentGroup.List))
-	for _, comment := range gsm.commentGroup.List {
-		commentSlice = append(commentSlice, comment.Text)
-	}
-	return commentSlice
-}
-
 // func (gsm *GoStructMeta) TypeParams() []*GoVarMeta {
 // 	if gsm.node == nil || gsm.node.(*ast.TypeSpec).TypeParams == nil || len(gsm.node.(*ast.TypeSpec).TypeParams.List) == 0 {
 // 		return nil
@@ -39,7 +39,12 @@ func (gmm *GoMethodMeta) extractReceiver() {
 	if len(receiverNode.Names) == 1 {
 		receiverName = receiverNode.Names[0].String()
 	}
-	gmm.receiver = newGoVarMeta(newMeta(receiverNode, gmm.path), receiverName)
+	gmm.receiver = newGoVarMeta(gmm.copyMeta(receiverNode), receiverName)
+}
+
+// Annotations 使用注解解析器获取 method 的文档注释内的所有注解
+func (gmm *GoMethodMeta) Annotations(parsers ...AnnotationParser) ([]*GoAnnotationMeta, []*GoAnnotationError) {
+	return extractAnnotationMetas(gmm.meta, gmm, gmm.doc, parsers)
 }
 
 func extractMethodRecvStruct(methodDecl *ast.FuncDecl) (string, bool) {
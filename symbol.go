@@ -0,0 +1,291 @@
+package extractor
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// symbolKindMap 各种类的声明在 ctags 和符号索引中的种类
+// - ctags 的种类与 universal-ctags 的 go 解析器一致
+// - 符号索引的种类与 SCIP 的 SymbolInformation.Kind 一致
+var symbolKindMap = map[SelectorKind]struct{ ctags, index string }{
+	SELECTOR_KIND_FUNC:             {"f", "Function"},
+	SELECTOR_KIND_METHOD:           {"f", "Method"},
+	SELECTOR_KIND_STRUCT:           {"s", "Struct"},
+	SELECTOR_KIND_INTERFACE:        {"i", "Interface"},
+	SELECTOR_KIND_INTERFACE_METHOD: {"n", "MethodSpecification"},
+	SELECTOR_KIND_FIELD:            {"m", "Field"},
+	SELECTOR_KIND_VAR:              {"v", "Variable"},
+	SELECTOR_KIND_CONST:            {"c", "Constant"},
+}
+
+// 符号索引中引用的角色，与 SCIP 的 SymbolRole 一致
+const (
+	symbolRoleDefinition  = 1
+	symbolRoleWriteAccess = 4
+	symbolRoleReadAccess  = 8
+)
+
+// symbolIndexJSON 符号索引的 JSON 格式，参考 SCIP 的 Index
+type symbolIndexJSON struct {
+	Metadata  *symbolIndexMetadataJSON   `json:"metadata"`
+	Documents []*symbolIndexDocumentJSON `json:"documents"`
+}
+
+type symbolIndexMetadataJSON struct {
+	Version              int                      `json:"version"`
+	ToolInfo             *symbolIndexToolInfoJSON `json:"toolInfo"`
+	ProjectRoot          string                   `json:"projectRoot"`
+	TextDocumentEncoding string                   `json:"textDocumentEncoding"`
+}
+
+type symbolIndexToolInfoJSON struct {
+	Name string `json:"name"`
+}
+
+// symbolIndexDocumentJSON 文件内的所有定义和引用，参考 SCIP 的 Document
+type symbolIndexDocumentJSON struct {
+	RelativePath string                       `json:"relativePath"`
+	Language     string                       `json:"language"`
+	Occurrences  []*symbolIndexOccurrenceJSON `json:"occurrences"`
+	Symbols      []*symbolIndexSymbolJSON     `json:"symbols"`
+}
+
+// symbolIndexOccurrenceJSON 定义或引用的位置，参考 SCIP 的 Occurrence
+// - range 从 0 开始: [起始行，起始列，结束列]，列按照字节计算
+type symbolIndexOccurrenceJSON struct {
+	Range       []int  `json:"range"`
+	Symbol      string `json:"symbol"`
+	SymbolRoles int    `json:"symbolRoles,omitempty"`
+}
+
+// symbolIndexSymbolJSON 定义在文件内的符号，参考 SCIP 的 SymbolInformation
+type symbolIndexSymbolJSON struct {
+	Symbol          string   `json:"symbol"`
+	Kind            string   `json:"kind"`
+	DisplayName     string   `json:"displayName"`
+	Documentation   []string `json:"documentation,omitempty"`
+	EnclosingSymbol string   `json:"enclosingSymbol,omitempty"`
+}
+
+// -------------------------------- extractor --------------------------------
+
+// nameIdent 匹配到的声明的标识的 ast 节点，匿名成员为类型的标识
+func (gsm *GoSelectorMatch) nameIdent() *ast.Ident {
+	var names []*ast.Ident
+	switch node := gsm.declMeta().node.(type) {
+	case *ast.FuncDecl:
+		return node.Name
+	case *ast.TypeSpec:
+		return node.Name
+	case *ast.ValueSpec:
+		names = node.Names
+	case *ast.Field:
+		if len(node.Names) == 0 {
+			// 匿名成员: T，*T，pkg.T，T[int]
+			typeExpr := node.Type
+			for {
+				switch expr := typeExpr.(type) {
+				case *ast.StarExpr:
+					typeExpr = expr.X
+				case *ast.IndexExpr:
+					typeExpr = expr.X
+				case *ast.IndexListExpr:
+					typeExpr = expr.X
+				case *ast.SelectorExpr:
+					return expr.Sel
+				case *ast.Ident:
+					return expr
+				default:
+					return nil
+				}
+			}
+		}
+		names = node.Names
+	}
+	for _, name := range names {
+		if name.Name == gsm.ident {
+			return name
+		}
+	}
+	return nil
+}
+
+// CTags 以 ctags 的扩展格式输出项目内所有声明，文件路径为相对于项目的路径
+// - 按照标签名称排序，与 vim 的二分查找一致
+// - method 和字段带有 struct:T，interface 的 method 带有 interface:T
+func (gpm *GoProjectMeta) CTags() []byte {
+	lines := make([]string, 0)
+	for _, gsm := range gpm.SelectBy(&GoSelector{}) {
+		ident := gsm.nameIdent()
+		if ident == nil {
+			continue
+		}
+		position := gsm.declMeta().position(ident.Pos())
+		line := fmt.Sprintf("%v\t%v\t%v;\"\t%v\tline:%v", gsm.ident, gpm.relativePath(position.Filename), position.Line, symbolKindMap[gsm.kind].ctags, position.Line)
+		switch gsm.kind {
+		case SELECTOR_KIND_METHOD, SELECTOR_KIND_FIELD:
+			line += "\tstruct:" + gsm.owner
+		case SELECTOR_KIND_INTERFACE_METHOD:
+			line += "\tinterface:" + gsm.owner
+		}
+		lines = append(lines, line)
+	}
+	sort.Strings(lines)
+	builder := &strings.Builder{}
+	builder.WriteString("!_TAG_FILE_FORMAT\t2\t/extended format; --format=1 will not append ;\" to lines/\n")
+	builder.WriteString("!_TAG_FILE_SORTED\t1\t/0=unsorted, 1=sorted, 2=foldcase/\n")
+	builder.WriteString("!_TAG_PROGRAM_NAME\tgo-extractor\t//\n")
+	for _, line := range lines {
+		builder.WriteString(line)
+		builder.WriteString("\n")
+	}
+	return []byte(builder.String())
+}
+
+// relativePath 获取相对于项目的路径，使用 / 分隔
+func (gpm *GoProjectMeta) relativePath(path string) string {
+	if rel, err := filepath.Rel(gpm.absolutePath, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// SymbolIndex 以参考 SCIP 的 JSON 格式输出项目的符号索引，包括所有声明的定义和引用
+// - 符号: go-extractor gomod <模块名称> . `<导入路径>`/T#M().
+// - 文档按照相对路径排序，文档内的定义和引用按照位置排序，符号按照符号排序
+// - 引用与 GoProjectMeta.References 一致，所有声明的引用在一次遍历中搜索
+func (gpm *GoProjectMeta) SymbolIndex() ([]byte, error) {
+	documentMap := make(map[string]*symbolIndexDocumentJSON)
+	document := func(path string) *symbolIndexDocumentJSON {
+		relPath := gpm.relativePath(path)
+		if documentMap[relPath] == nil {
+			documentMap[relPath] = &symbolIndexDocumentJSON{
+				RelativePath: relPath,
+				Language:     "go",
+				Occurrences:  make([]*symbolIndexOccurrenceJSON, 0),
+				Symbols:      make([]*symbolIndexSymbolJSON, 0),
+			}
+		}
+		return documentMap[relPath]
+	}
+	matches := gpm.SelectBy(&GoSelector{})
+	referenceMap := gpm.referencesOfAll(matches)
+	for _, gsm := range matches {
+		ident := gsm.nameIdent()
+		if ident == nil {
+			continue
+		}
+		path := gsm.declMeta().path
+		symbol := gpm.symbol(gsm)
+		doc := document(path)
+		doc.Occurrences = append(doc.Occurrences, &symbolIndexOccurrenceJSON{Range: identRange(gsm.declMeta(), ident), Symbol: symbol, SymbolRoles: symbolRoleDefinition})
+		info := &symbolIndexSymbolJSON{Symbol: symbol, Kind: symbolKindMap[gsm.kind].index, DisplayName: gsm.ident}
+		if text := gsm.docText(); len(text) > 0 {
+			info.Documentation = []string{text}
+		}
+		if len(gsm.owner) > 0 {
+			info.EnclosingSymbol = gpm.symbol(&GoSelectorMatch{kind: SELECTOR_KIND_STRUCT, packageMeta: gsm.packageMeta, ident: gsm.owner})
+		}
+		doc.Symbols = append(doc.Symbols, info)
+
+		for _, grm := range referenceMap[gsm] {
+			occurrence := &symbolIndexOccurrenceJSON{Range: identRange(grm.meta, grm.node.(*ast.Ident)), Symbol: symbol}
+			switch grm.referenceType {
+			case REFERENCE_READ:
+				occurrence.SymbolRoles = symbolRoleReadAccess
+			case REFERENCE_WRITE:
+				occurrence.SymbolRoles = symbolRoleWriteAccess
+			}
+			refDoc := document(grm.path)
+			refDoc.Occurrences = append(refDoc.Occurrences, occurrence)
+		}
+	}
+
+	index := &symbolIndexJSON{
+		Metadata: &symbolIndexMetadataJSON{
+			ToolInfo:             &symbolIndexToolInfoJSON{Name: "go-extractor"},
+			ProjectRoot:          "file://" + filepath.ToSlash(gpm.absolutePath),
+			TextDocumentEncoding: "UTF8",
+		},
+		Documents: make([]*symbolIndexDocumentJSON, 0, len(documentMap)),
+	}
+	for _, relPath := range sortedKeys(documentMap) {
+		doc := documentMap[relPath]
+		sort.SliceStable(doc.Occurrences, func(i, j int) bool {
+			ri, rj := doc.Occurrences[i].Range, doc.Occurrences[j].Range
+			if ri[0] != rj[0] {
+				return ri[0] < rj[0]
+			}
+			return ri[1] < rj[1]
+		})
+		sort.SliceStable(doc.Symbols, func(i, j int) bool { return doc.Symbols[i].Symbol < doc.Symbols[j].Symbol })
+		index.Documents = append(index.Documents, doc)
+	}
+	return json.MarshalIndent(index, "", "  ")
+}
+
+// symbol 声明在符号索引中的符号，与 SCIP 的符号格式一致
+// - func: F().，method: T#M().，struct 和 interface: T#，字段: T#f.，interface 的 method: I#M().，var 和 const: V.
+func (gpm *GoProjectMeta) symbol(gsm *GoSelectorMatch) string {
+	moduleName := gpm.moduleName
+	if len(moduleName) == 0 {
+		moduleName = "."
+	}
+	packageIdent := gpm.packageImportPath(gsm.packageMeta)
+	var descriptor string
+	switch gsm.kind {
+	case SELECTOR_KIND_FUNC:
+		descriptor = symbolDescriptor(gsm.ident) + "()."
+	case SELECTOR_KIND_METHOD, SELECTOR_KIND_INTERFACE_METHOD:
+		descriptor = symbolDescriptor(gsm.owner) + "#" + symbolDescriptor(gsm.ident) + "()."
+	case SELECTOR_KIND_STRUCT, SELECTOR_KIND_INTERFACE:
+		descriptor = symbolDescriptor(gsm.ident) + "#"
+	case SELECTOR_KIND_FIELD:
+		descriptor = symbolDescriptor(gsm.owner) + "#" + symbolDescriptor(gsm.ident) + "."
+	default:
+		descriptor = symbolDescriptor(gsm.ident) + "."
+	}
+	return fmt.Sprintf("go-extractor gomod %v . %v/%v", moduleName, symbolDescriptor(packageIdent), descriptor)
+}
+
+// symbolDescriptor 包含标识字符以外的字符时使用 ` 包围
+func symbolDescriptor(name string) string {
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '+' && r != '-' && r != '$' {
+			return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+		}
+	}
+	return name
+}
+
+// identRange 标识在文件中的范围，从 0 开始: [行，起始列，结束列]
+func identRange(m *meta, ident *ast.Ident) []int {
+	position := m.position(ident.Pos())
+	return []int{position.Line - 1, position.Column - 1, position.Column - 1 + len(ident.Name)}
+}
+
+// docText 匹配到的声明的文档注释文本
+func (gsm *GoSelectorMatch) docText() string {
+	return strings.TrimSpace(gsm.commentMeta().DocText())
+}
+
+// commentMeta 匹配到的声明的注释
+func (gsm *GoSelectorMatch) commentMeta() *commentMeta {
+	switch gsm.kind {
+	case SELECTOR_KIND_FUNC, SELECTOR_KIND_METHOD:
+		return &gsm.funcMeta.commentMeta
+	case SELECTOR_KIND_STRUCT:
+		return &gsm.structMeta.commentMeta
+	case SELECTOR_KIND_INTERFACE:
+		return &gsm.interfaceMeta.commentMeta
+	case SELECTOR_KIND_INTERFACE_METHOD:
+		return &gsm.interfaceMethodMeta.commentMeta
+	}
+	return &gsm.varMeta.commentMeta
+}
@@ -0,0 +1,390 @@
+package extractor
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"strconv"
+	"strings"
+)
+
+var (
+	errTagSyntax      = errors.New("bad syntax for struct tag pair")
+	errTagKeySyntax   = errors.New("bad syntax for struct tag key")
+	errTagValueSyntax = errors.New("bad syntax for struct tag value")
+	errTagValueSpace  = errors.New("suspicious space in struct tag value")
+	errTagSpace       = errors.New("key:\"value\" pairs not separated by spaces")
+)
+
+// checkTagSpaces 需要检查值内空格的 tag key，与 go vet 一致
+var checkTagSpaces = map[string]bool{"json": true, "xml": true, "asn1": true}
+
+// checkTagDups 需要检查同一 struct 内名称是否重复的 tag key，与 go vet 一致
+var checkTagDups = map[string]bool{"json": true, "xml": true}
+
+// GoStructTag struct member 的 tag 模型
+// - `json:"name,omitempty" db:"name"` -> [{json name,omitempty} {db name}]
+type GoStructTag struct {
+	// 按照源码顺序的所有 key:"value"
+	entries []*GoStructTagEntry
+}
+
+// GoStructTagEntry tag 内的一个 key:"value"
+type GoStructTagEntry struct {
+	key, value string
+}
+
+// ParseGoStructTag 解析去除引号后的 tag 内容
+// - 遇到语法错误时返回已解析的部分以及错误，错误与 go vet 的 structtag 检查一致
+func ParseGoStructTag(tag string) (*GoStructTag, error) {
+	gst := &GoStructTag{entries: make([]*GoStructTagEntry, 0)}
+	for n := 0; tag != ""; n++ {
+		if n > 0 && tag[0] != ' ' {
+			return gst, errTagSpace
+		}
+		// 跳过空格
+		tag = strings.TrimLeft(tag, " ")
+		if tag == "" {
+			break
+		}
+		// 扫描到 :
+		i := 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 {
+			return gst, errTagKeySyntax
+		}
+		if i+1 >= len(tag) || tag[i] != ':' {
+			return gst, errTagSyntax
+		}
+		if tag[i+1] != '"' {
+			return gst, errTagValueSyntax
+		}
+		key := tag[:i]
+		tag = tag[i+1:]
+		// 扫描带引号的值
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			return gst, errTagValueSyntax
+		}
+		value, err := strconv.Unquote(tag[:i+1])
+		if err != nil {
+			return gst, errTagValueSyntax
+		}
+		tag = tag[i+1:]
+		gst.entries = append(gst.entries, &GoStructTagEntry{key: key, value: value})
+	}
+	return gst, nil
+}
+
+// validate 按照 go vet 的规则检查 tag
+func (gst *GoStructTag) validate() error {
+	for _, entry := range gst.entries {
+		if !checkTagSpaces[entry.key] {
+			continue
+		}
+		value := entry.value
+		switch entry.key {
+		case "xml":
+			// 首尾或多个空格均视为可疑
+			if strings.Trim(value, " ") != value || strings.Count(value, " ") > 1 {
+				return errTagValueSpace
+			}
+			comma := strings.IndexRune(value, ',')
+			if comma < 0 {
+				continue
+			}
+			if comma > 0 && value[comma-1] == ' ' {
+				return errTagValueSpace
+			}
+			value = value[comma+1:]
+		case "json":
+			// json 的名称允许包含空格
+			comma := strings.IndexRune(value, ',')
+			if comma < 0 {
+				continue
+			}
+			value = value[comma+1:]
+		}
+		if strings.IndexByte(value, ' ') >= 0 {
+			return errTagValueSpace
+		}
+	}
+	return nil
+}
+
+// -------------------------------- extractor --------------------------------
+
+// Get 根据 key 获取 tag 的值
+func (gst *GoStructTag) Get(key string) (string, bool) {
+	if entry := gst.Entry(key); entry != nil {
+		return entry.value, true
+	}
+	return "", false
+}
+
+// Entry 根据 key 获取 tag 内的 key:"value"
+func (gst *GoStructTag) Entry(key string) *GoStructTagEntry {
+	for _, entry := range gst.entries {
+		if entry.key == key {
+			return entry
+		}
+	}
+	return nil
+}
+
+// Keys 按照源码顺序获取 tag 的所有 key
+func (gst *GoStructTag) Keys() []string {
+	keys := make([]string, 0, len(gst.entries))
+	for _, entry := range gst.entries {
+		keys = append(keys, entry.key)
+	}
+	return keys
+}
+
+// Set 设置 key 的值，key 已存在时保持其位置，否则追加到末尾
+func (gst *GoStructTag) Set(key, value string) {
+	if entry := gst.Entry(key); entry != nil {
+		entry.value = value
+		return
+	}
+	gst.entries = append(gst.entries, &GoStructTagEntry{key: key, value: value})
+}
+
+// Delete 删除 key，返回 key 是否存在
+func (gst *GoStructTag) Delete(key string) bool {
+	for index, entry := range gst.entries {
+		if entry.key == key {
+			gst.entries = append(gst.entries[:index], gst.entries[index+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Rename 将 key 重命名为 newKey 并保持其位置
+// - key 不存在或 newKey 已存在时不做修改并返回 false
+func (gst *GoStructTag) Rename(key, newKey string) bool {
+	entry := gst.Entry(key)
+	if entry == nil || gst.Entry(newKey) != nil {
+		return false
+	}
+	entry.key = newKey
+	return true
+}
+
+// String 输出规范格式的 tag 内容，不包含外层引号: json:"name,omitempty" db:"name"
+func (gst *GoStructTag) String() string {
+	builder := strings.Builder{}
+	for index, entry := range gst.entries {
+		if index > 0 {
+			builder.WriteByte(' ')
+		}
+		builder.WriteString(entry.key)
+		builder.WriteByte(':')
+		builder.WriteString(strconv.Quote(entry.value))
+	}
+	return builder.String()
+}
+
+// Literal 输出 tag 的字面量，优先使用 `...` 形式
+func (gst *GoStructTag) Literal() string {
+	tag := gst.String()
+	if strings.ContainsRune(tag, '`') {
+		return strconv.Quote(tag)
+	}
+	return "`" + tag + "`"
+}
+
+// Name tag 值中第一个逗号之前的名称: "name,omitempty" -> name
+func (gste *GoStructTagEntry) Name() string {
+	name, _, _ := strings.Cut(gste.value, ",")
+	return name
+}
+
+// Options tag 值中第一个逗号之后的所有选项: "name,omitempty,string" -> [omitempty string]
+func (gste *GoStructTagEntry) Options() []string {
+	_, options, found := strings.Cut(gste.value, ",")
+	if !found {
+		return nil
+	}
+	return strings.Split(options, ",")
+}
+
+// HasOption 是否包含指定的选项
+func (gste *GoStructTagEntry) HasOption(option string) bool {
+	for _, o := range gste.Options() {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// -------------------------------- extractor --------------------------------
+
+// Tag 解析 struct member 的 tag，member 没有 tag 或不是 struct member 时返回 nil
+// - 每次调用返回新的 tag 模型，修改不会影响 meta 数据
+// - tag 存在语法错误时返回已解析的部分
+func (gvm *GoVarMeta) Tag() *GoStructTag {
+	field, ok := gvm.node.(*ast.Field)
+	if !ok || field.Tag == nil {
+		return nil
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return &GoStructTag{entries: make([]*GoStructTagEntry, 0)}
+	}
+	gst, _ := ParseGoStructTag(tag)
+	return gst
+}
+
+// ValidateTags 按照 go vet 的 structtag 检查规则检查 struct 内所有 member 的 tag
+// - tag 的语法以及值内的空格
+// - 同一 struct 内重复的 json，xml 名称，包括匿名成员
+func (gsm *GoStructMeta) ValidateTags() []*GoDiagnostic {
+	diagnostics := make([]*GoDiagnostic, 0)
+	seen := make(map[string]map[string]*ast.Field)
+	for _, field := range gsm.fields() {
+		if field.Tag == nil {
+			continue
+		}
+		pos, end := gsm.position(field.Tag.Pos()), gsm.position(field.Tag.End())
+		tag, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			continue
+		}
+		gst, err := ParseGoStructTag(tag)
+		if err == nil {
+			err = gst.validate()
+		}
+		if err != nil {
+			diagnostics = append(diagnostics, newGoDiagnostic(pos, end, "structtag", "struct field tag %#q not compatible with reflect.StructTag.Get: %v", tag, err))
+			continue
+		}
+		for _, entry := range gst.entries {
+			if !checkTagDups[entry.key] {
+				continue
+			}
+			name := entry.Name()
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				// 没有指定名称时使用 member 的标识，匿名成员为其类型标识
+				name = gsm.fieldMemberMeta(field).ident
+			}
+			if seen[entry.key] == nil {
+				seen[entry.key] = make(map[string]*ast.Field)
+			}
+			if other, has := seen[entry.key][name]; has {
+				diagnostics = append(diagnostics, newGoDiagnostic(pos, end, "structtag", "struct field %v repeats %v tag %q also at %v", gsm.fieldMemberMeta(field).ident, entry.key, name, gsm.position(other.Pos())))
+				continue
+			}
+			seen[entry.key][name] = field
+		}
+	}
+	return diagnostics
+}
+
+// EditTags 遍历 struct 内所有 member 的 tag 进行修改，返回修改后的 文本替换
+// - 同一行声明的多个 member 共享同一个 tag，只会以第一个 member 回调一次
+// - member 没有 tag 时回调空的 tag，修改后为空的 tag 会被移除
+// - 基于提取时的文件内容构造，通过 ApplyTextEdits 写回文件，文件在提取后被修改时拒绝写入
+func (gsm *GoStructMeta) EditTags(f func(member *GoVarMeta, tag *GoStructTag)) ([]*GoTextEdit, error) {
+	content := gsm.content()
+	if content == nil {
+		return nil, fmt.Errorf("can not read content of file '%v'", gsm.path)
+	}
+	edits := make([]*GoTextEdit, 0)
+	for _, field := range gsm.fields() {
+		member := gsm.fieldMemberMeta(field)
+		if member == nil {
+			continue
+		}
+		gst := member.Tag()
+		if gst == nil {
+			gst = &GoStructTag{entries: make([]*GoStructTagEntry, 0)}
+		}
+		origin := gst.String()
+		f(member, gst)
+		if gst.String() == origin {
+			continue
+		}
+		switch {
+		case field.Tag == nil:
+			// 添加 tag
+			offset := int(field.Type.End()) - 1
+			edits = append(edits, newGoTextEdit(gsm.path, content, offset, offset, " "+gst.Literal()))
+		case len(gst.entries) == 0:
+			// 移除 tag 以及之前的空白
+			edits = append(edits, newGoTextEdit(gsm.path, content, int(field.Type.End())-1, int(field.Tag.End())-1, ""))
+		default:
+			edits = append(edits, newGoTextEdit(gsm.path, content, int(field.Tag.Pos())-1, int(field.Tag.End())-1, gst.Literal()))
+		}
+	}
+	return edits, nil
+}
+
+// AddTagKey 为 struct 内所有没有该 key 的 member 添加 key:"value"
+// - value 返回空字符串时跳过该 member
+func (gsm *GoStructMeta) AddTagKey(key string, value func(member *GoVarMeta) string) ([]*GoTextEdit, error) {
+	return gsm.EditTags(func(member *GoVarMeta, tag *GoStructTag) {
+		if _, has := tag.Get(key); has {
+			return
+		}
+		if v := value(member); len(v) > 0 {
+			tag.Set(key, v)
+		}
+	})
+}
+
+// RemoveTagKey 移除 struct 内所有 member 的 tag 中的 key
+func (gsm *GoStructMeta) RemoveTagKey(key string) ([]*GoTextEdit, error) {
+	return gsm.EditTags(func(_ *GoVarMeta, tag *GoStructTag) { tag.Delete(key) })
+}
+
+// RenameTagKey 将 struct 内所有 member 的 tag 中的 key 重命名为 newKey
+// - 已存在 newKey 的 member 不做修改
+func (gsm *GoStructMeta) RenameTagKey(key, newKey string) ([]*GoTextEdit, error) {
+	return gsm.EditTags(func(_ *GoVarMeta, tag *GoStructTag) { tag.Rename(key, newKey) })
+}
+
+// fields 按照源码顺序获取 struct 内所有 member 的 ast 节点
+func (gsm *GoStructMeta) fields() []*ast.Field {
+	structType, ok := gsm.node.(*ast.TypeSpec).Type.(*ast.StructType)
+	if !ok || structType.Fields == nil {
+		return nil
+	}
+	return structType.Fields.List
+}
+
+// fieldMemberMeta 获取 ast 节点对应的 member 的 meta 数据，同一行声明的多个 member 取第一个
+func (gsm *GoStructMeta) fieldMemberMeta(field *ast.Field) *GoVarMeta {
+	if len(field.Names) > 0 {
+		return gsm.memberMetaMap[field.Names[0].Name]
+	}
+	for _, gvm := range gsm.memberMetaMap {
+		if gvm.node == field {
+			return gvm
+		}
+	}
+	return nil
+}
+
+// -------------------------------- unit test --------------------------------
+
+func (gste *GoStructTagEntry) Key() string   { return gste.key }
+func (gste *GoStructTagEntry) Value() string { return gste.value }
+func (gst *GoStructTag) Entries() []*GoStructTagEntry {
+	return gst.entries
+}
+
+// -------------------------------- unit test --------------------------------
@@ -0,0 +1,330 @@
+package extractor
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"sort"
+	"strings"
+)
+
+// GoTextEdit 对源文件内容的一次文本替换
+// - 替换范围为 [offset, end) 的字节区间，offset == end 时为插入
+// - 记录替换前的原始文本，应用时用于检测文件是否已经被修改
+type GoTextEdit struct {
+	// 文件的绝对路径
+	path string
+
+	// 替换范围的起止字节偏移
+	offset, end int
+
+	// 替换前的原始文本
+	oldText string
+
+	// 替换后的文本
+	newText string
+}
+
+// GoEditSet 跨文件的 文本替换 的集合，可以预览为 diff 或应用到源文件
+type GoEditSet struct {
+	// 所有 文本替换，按照文件路径，替换位置排序
+	edits []*GoTextEdit
+}
+
+// diffLine diff 中的一行
+type diffLine struct {
+	// ' ' 未修改，'-' 删除，'+' 添加
+	op byte
+
+	// 不包含换行符的行内容
+	text string
+}
+
+// newGoTextEdit 通过文件内容构造 文本替换
+func newGoTextEdit(path string, content []byte, offset, end int, newText string) *GoTextEdit {
+	return &GoTextEdit{
+		path:    path,
+		offset:  offset,
+		end:     end,
+		oldText: string(content[offset:end]),
+		newText: newText,
+	}
+}
+
+// -------------------------------- extractor --------------------------------
+
+// ApplyTextEdits 将 文本替换 按照文件分组应用到磁盘上的源文件，并以 gofmt 格式写回
+// - 同一文件内的替换范围不能重叠
+// - 替换范围内的原始文本与磁盘上的文件内容不一致时，视为文件已被修改，所有文件都不会被写入
+// - 应用后基于旧文件内容提取的 meta 数据将失效，需要重新提取
+func ApplyTextEdits(edits []*GoTextEdit) error {
+	return writeTextEdits(edits, func(path string, content []byte) ([]byte, error) {
+		formatted, err := format.Source(content)
+		if err != nil {
+			return nil, fmt.Errorf("%v: format source occurs error: %v", path, err)
+		}
+		return formatted, nil
+	})
+}
+
+// writeTextEdits 将 文本替换 按照文件分组应用到磁盘上的源文件，所有文件的替换都成功且经过 finish 处理后才写入
+func writeTextEdits(edits []*GoTextEdit, finish func(path string, content []byte) ([]byte, error)) error {
+	pathEdits, paths := groupTextEdits(edits)
+	sort.Strings(paths)
+
+	perms := make(map[string]os.FileMode, len(paths))
+	contents := make(map[string][]byte, len(paths))
+	for _, path := range paths {
+		fileStat, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		newContent, err := applyTextEdits(content, pathEdits[path])
+		if err != nil {
+			return fmt.Errorf("%v: %v", path, err)
+		}
+		if newContent, err = finish(path, newContent); err != nil {
+			return err
+		}
+		perms[path], contents[path] = fileStat.Mode().Perm(), newContent
+	}
+	for _, path := range paths {
+		if err := os.WriteFile(path, contents[path], perms[path]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// groupTextEdits 将 文本替换 按照文件分组，paths 为首次出现的顺序
+func groupTextEdits(edits []*GoTextEdit) (map[string][]*GoTextEdit, []string) {
+	pathEdits := make(map[string][]*GoTextEdit)
+	paths := make([]string, 0)
+	for _, edit := range edits {
+		if _, has := pathEdits[edit.path]; !has {
+			paths = append(paths, edit.path)
+		}
+		pathEdits[edit.path] = append(pathEdits[edit.path], edit)
+	}
+	return pathEdits, paths
+}
+
+// checkSource 检查替换后的文件内容是否仍然可以被解析
+func checkSource(path string, content []byte) ([]byte, error) {
+	if _, err := parser.ParseFile(token.NewFileSet(), path, content, parser.ParseComments); err != nil {
+		return nil, fmt.Errorf("%v: edited source can not be parsed: %v", path, err)
+	}
+	return content, nil
+}
+
+// applyTextEdits 将同一文件的 文本替换 应用到文件内容上
+func applyTextEdits(content []byte, edits []*GoTextEdit) ([]byte, error) {
+	sorted := make([]*GoTextEdit, len(edits))
+	copy(sorted, edits)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].offset < sorted[j].offset })
+
+	buffer := &bytes.Buffer{}
+	last := 0
+	for _, edit := range sorted {
+		if edit.offset < last {
+			return nil, fmt.Errorf("edit at offset %v overlaps with previous edit", edit.offset)
+		}
+		if edit.end > len(content) || string(content[edit.offset:edit.end]) != edit.oldText {
+			return nil, fmt.Errorf("file changed on disk since extraction at offset %v", edit.offset)
+		}
+		buffer.Write(content[last:edit.offset])
+		buffer.WriteString(edit.newText)
+		last = edit.end
+	}
+	buffer.Write(content[last:])
+	return buffer.Bytes(), nil
+}
+
+// newGoEditSet 通过 文本替换 构造 文本替换 的集合，按照文件路径，替换位置排序
+func newGoEditSet(edits []*GoTextEdit) *GoEditSet {
+	sorted := make([]*GoTextEdit, len(edits))
+	copy(sorted, edits)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].path != sorted[j].path {
+			return sorted[i].path < sorted[j].path
+		}
+		return sorted[i].offset < sorted[j].offset
+	})
+	return &GoEditSet{edits: sorted}
+}
+
+// Apply 将集合内所有 文本替换 原样应用到磁盘上的源文件，不重新格式化
+// - 与 ApplyTextEdits 相同，文件已被修改时拒绝写入
+// - 任意文件替换后无法被解析时拒绝写入所有文件
+func (ges *GoEditSet) Apply() error {
+	return writeTextEdits(ges.edits, checkSource)
+}
+
+// Diff 以 unified diff 格式预览集合内所有 文本替换 原样应用后的文件内容，不会修改文件
+// - 按照文件路径排序，每个文件以 --- 和 +++ 开头，文件路径为绝对路径
+// - 与 Apply 相同，替换后无法被解析时返回错误
+func (ges *GoEditSet) Diff() (string, error) {
+	pathEdits, paths := groupTextEdits(ges.edits)
+
+	builder := strings.Builder{}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		newContent, err := applyTextEdits(content, pathEdits[path])
+		if err != nil {
+			return "", fmt.Errorf("%v: %v", path, err)
+		}
+		if _, err = checkSource(path, newContent); err != nil {
+			return "", err
+		}
+		builder.WriteString(unifiedDiff(path, content, newContent))
+	}
+	return builder.String(), nil
+}
+
+// unifiedDiff 获取文件内容修改前后的 unified diff，每个修改块包含前后 3 行上下文，内容相同时为空
+func unifiedDiff(path string, oldContent, newContent []byte) string {
+	const context = 3
+	lines := diffLines(splitLines(oldContent), splitLines(newContent))
+
+	// 每一行之前的旧文件和新文件的行数
+	oldIndexes, newIndexes := make([]int, len(lines)+1), make([]int, len(lines)+1)
+	changes := make([]int, 0)
+	for index, line := range lines {
+		oldIndexes[index+1], newIndexes[index+1] = oldIndexes[index], newIndexes[index]
+		if line.op != '+' {
+			oldIndexes[index+1]++
+		}
+		if line.op != '-' {
+			newIndexes[index+1]++
+		}
+		if line.op != ' ' {
+			changes = append(changes, index)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	builder := strings.Builder{}
+	fmt.Fprintf(&builder, "--- %v\n+++ %v\n", path, path)
+	for index := 0; index < len(changes); {
+		start, end := max(changes[index]-context, 0), changes[index]+context+1
+		// 上下文重叠的修改合并到同一个修改块
+		for index++; index < len(changes) && changes[index]-context <= end; index++ {
+			end = changes[index] + context + 1
+		}
+		end = min(end, len(lines))
+
+		oldStart, oldCount := oldIndexes[start], oldIndexes[end]-oldIndexes[start]
+		newStart, newCount := newIndexes[start], newIndexes[end]-newIndexes[start]
+		if oldCount > 0 {
+			oldStart++
+		}
+		if newCount > 0 {
+			newStart++
+		}
+		fmt.Fprintf(&builder, "@@ -%v,%v +%v,%v @@\n", oldStart, oldCount, newStart, newCount)
+		for _, line := range lines[start:end] {
+			builder.WriteByte(line.op)
+			builder.WriteString(line.text)
+			builder.WriteByte('\n')
+		}
+	}
+	return builder.String()
+}
+
+// splitLines 将文件内容按行拆分，不包含换行符
+func splitLines(content []byte) []string {
+	text := strings.TrimSuffix(string(content), "\n")
+	if len(text) == 0 {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// diffLines 使用 Myers 算法计算两组行之间最短的编辑序列
+func diffLines(a, b []string) []*diffLine {
+	n, m := len(a), len(b)
+	offset := n + m
+	v := make([]int, 2*offset+2)
+	trace := make([][]int, 0)
+	found := false
+	for d := 0; d <= n+m && !found; d++ {
+		// 记录每一步开始前各条对角线上到达的最远位置，用于回溯
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	reversed := make([]*diffLine, 0, n+m)
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x, y = x-1, y-1
+			reversed = append(reversed, &diffLine{op: ' ', text: a[x]})
+		}
+		if x == prevX {
+			y--
+			reversed = append(reversed, &diffLine{op: '+', text: b[y]})
+		} else {
+			x--
+			reversed = append(reversed, &diffLine{op: '-', text: a[x]})
+		}
+	}
+	for x > 0 && y > 0 {
+		x, y = x-1, y-1
+		reversed = append(reversed, &diffLine{op: ' ', text: a[x]})
+	}
+
+	lines := make([]*diffLine, 0, len(reversed))
+	for index := len(reversed) - 1; index >= 0; index-- {
+		lines = append(lines, reversed[index])
+	}
+	return lines
+}
+
+// -------------------------------- extractor --------------------------------
+
+// -------------------------------- unit test --------------------------------
+
+func (ges *GoEditSet) Edits() []*GoTextEdit { return ges.edits }
+func (gte *GoTextEdit) Path() string        { return gte.path }
+func (gte *GoTextEdit) Offset() int         { return gte.offset }
+func (gte *GoTextEdit) End() int            { return gte.end }
+func (gte *GoTextEdit) OldText() string     { return gte.oldText }
+func (gte *GoTextEdit) NewText() string     { return gte.newText }
+
+// -------------------------------- unit test --------------------------------
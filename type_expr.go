@@ -0,0 +1,227 @@
+package extractor
+
+import (
+	"go/ast"
+	"go/types"
+)
+
+// GoTypeExpr go 类型表达式 的 meta 数据
+// - 递归描述类型表达式，生成器无需再次解析字符串
+// - *pkg.ExampleStruct[map[string]T] -> POINTER{ IDENT{ from: pkg, ident: ExampleStruct, typeArgs: [ MAP{ key: IDENT{string}, elem: IDENT{T} } ] } }
+type GoTypeExpr struct {
+	// 组合基本 meta 数据
+	// ast 节点，要求为 ast.Expr
+	// 以 ast 节点 为单位执行 AST/PrintAST/Expression/Format
+	*meta
+
+	// 类型表达式的种类
+	kind UnderlyingType
+
+	// 命名类型的标识: pkg.ExampleStruct[T] -> ExampleStruct
+	ident string
+
+	// 命名类型的包限定符: pkg.ExampleStruct[T] -> pkg
+	from string
+
+	// 命名类型所属 package 的完整导入路径，根据所属文件的 import 解析
+	// - 内置类型，类型参数以及无法解析的类型为空
+	importPath string
+
+	// 命名类型所属 package 的 meta 数据，仅当其在项目内时存在
+	packageMeta *GoPackageMeta
+
+	// 命名类型对应的 struct 的 meta 数据，仅当其在项目内时存在
+	structMeta *GoStructMeta
+
+	// 命名类型对应的 interface 的 meta 数据，仅当其在项目内时存在
+	interfaceMeta *GoInterfaceMeta
+
+	// 命名类型的实例化类型参数: ExampleStruct[K, V] -> K, V
+	typeArgs []*GoTypeExpr
+
+	// 元素类型
+	// - 指针: *T -> T
+	// - 切片/数组: []T / [N]T -> T
+	// - map: map[K]V -> V
+	// - chan: chan T -> T
+	// - 可变参数: ...T -> T
+	elem *GoTypeExpr
+
+	// map 的 key 类型: map[K]V -> K
+	key *GoTypeExpr
+
+	// 数组长度表达式: [N]T -> N，[...]T -> ...
+	length string
+
+	// chan 的方向: ast.SEND | ast.RECV 表示双向
+	chanDir ast.ChanDir
+
+	// func 类型的参数
+	params []*GoVarMeta
+
+	// func 类型的返回值
+	returns []*GoVarMeta
+}
+
+// newGoTypeExpr 通过 ast 构造 类型表达式 的 meta 数据
+func newGoTypeExpr(m *meta, stopExtract ...bool) *GoTypeExpr {
+	gte := &GoTypeExpr{meta: m}
+	if len(stopExtract) == 0 {
+		gte.ExtractAll()
+	}
+	return gte
+}
+
+// -------------------------------- extractor --------------------------------
+
+// ExtractAll 递归提取 类型表达式 的所有子类型表达式
+func (gte *GoTypeExpr) ExtractAll() {
+	expr, ok := gte.node.(ast.Expr)
+	if !ok {
+		return
+	}
+	gte.extract(expr)
+}
+
+func (gte *GoTypeExpr) extract(expr ast.Expr) {
+	switch typeExpr := expr.(type) {
+	case *ast.ParenExpr:
+		// (T) 等价于 T
+		gte.extract(typeExpr.X)
+	case *ast.Ident:
+		gte.kind, gte.ident = UNDERLYING_TYPE_IDENT, typeExpr.Name
+	case *ast.SelectorExpr:
+		gte.kind, gte.ident = UNDERLYING_TYPE_IDENT, typeExpr.Sel.Name
+		if fromIdent, ok := typeExpr.X.(*ast.Ident); ok {
+			gte.from = fromIdent.Name
+		}
+	case *ast.IndexExpr:
+		gte.extract(typeExpr.X)
+		gte.typeArgs = []*GoTypeExpr{newGoTypeExpr(gte.copyMeta(typeExpr.Index))}
+	case *ast.IndexListExpr:
+		gte.extract(typeExpr.X)
+		gte.typeArgs = make([]*GoTypeExpr, 0, len(typeExpr.Indices))
+		for _, index := range typeExpr.Indices {
+			gte.typeArgs = append(gte.typeArgs, newGoTypeExpr(gte.copyMeta(index)))
+		}
+	case *ast.StarExpr:
+		gte.kind, gte.elem = UNDERLYING_TYPE_POINTER, newGoTypeExpr(gte.copyMeta(typeExpr.X))
+	case *ast.ArrayType:
+		gte.kind, gte.elem = UNDERLYING_TYPE_SLICE, newGoTypeExpr(gte.copyMeta(typeExpr.Elt))
+		if typeExpr.Len != nil {
+			gte.kind, gte.length = UNDERLYING_TYPE_ARRAY, types.ExprString(typeExpr.Len)
+		}
+	case *ast.Ellipsis:
+		gte.kind = UNDERLYING_TYPE_ELLIPSIS
+		if typeExpr.Elt != nil {
+			gte.elem = newGoTypeExpr(gte.copyMeta(typeExpr.Elt))
+		}
+	case *ast.MapType:
+		gte.kind = UNDERLYING_TYPE_MAP
+		gte.key = newGoTypeExpr(gte.copyMeta(typeExpr.Key))
+		gte.elem = newGoTypeExpr(gte.copyMeta(typeExpr.Value))
+	case *ast.ChanType:
+		gte.kind, gte.chanDir = UNDERLYING_TYPE_CHAN, typeExpr.Dir
+		gte.elem = newGoTypeExpr(gte.copyMeta(typeExpr.Value))
+	case *ast.FuncType:
+		gte.kind = UNDERLYING_TYPE_FUNC
+		gte.params = extractFieldListVarMeta(gte.meta, typeExpr.Params)
+		gte.returns = extractFieldListVarMeta(gte.meta, typeExpr.Results)
+	case *ast.StructType:
+		gte.kind = UNDERLYING_TYPE_STRUCT
+	case *ast.InterfaceType:
+		gte.kind = UNDERLYING_TYPE_INTERFACE
+	}
+}
+
+// foreach 深度优先遍历 类型表达式 及其所有子类型表达式
+func (gte *GoTypeExpr) foreach(f func(*GoTypeExpr)) {
+	if gte == nil {
+		return
+	}
+	f(gte)
+	for _, typeArg := range gte.typeArgs {
+		typeArg.foreach(f)
+	}
+	gte.key.foreach(f)
+	gte.elem.foreach(f)
+	for _, gvm := range gte.params {
+		gvm.typeExpr.foreach(f)
+	}
+	for _, gvm := range gte.returns {
+		gvm.typeExpr.foreach(f)
+	}
+}
+
+// resolve 将命名类型的标识解析到其所属的 package
+func (gte *GoTypeExpr) resolve(importPath string, gpm *GoPackageMeta) {
+	gte.importPath, gte.packageMeta = importPath, gpm
+	if gpm == nil {
+		return
+	}
+	gte.structMeta = gpm.structMetaMap[gte.ident]
+	gte.interfaceMeta = gpm.interfaceMetaMap[gte.ident]
+}
+
+// -------------------------------- extractor --------------------------------
+
+// String 输出 类型表达式 的规范化字符串，不依赖源文件
+func (gte *GoTypeExpr) String() string {
+	if expr, ok := gte.node.(ast.Expr); ok {
+		return types.ExprString(expr)
+	}
+	return ""
+}
+
+// IsPointer 是否是指针类型
+func (gte *GoTypeExpr) IsPointer() bool { return gte.kind == UNDERLYING_TYPE_POINTER }
+
+// IsBuiltin 是否是内置类型: int, string, error, any 等
+// - 解析到 package 内同名类型的标识不是内置类型: type error struct{}
+func (gte *GoTypeExpr) IsBuiltin() bool {
+	return gte.kind == UNDERLYING_TYPE_IDENT && len(gte.from) == 0 && gte.packageMeta == nil && isPredeclaredType(gte.ident)
+}
+
+// IsNamed 是否是命名类型，包括内置类型，包限定类型以及泛型实例化类型
+func (gte *GoTypeExpr) IsNamed() bool { return gte.kind == UNDERLYING_TYPE_IDENT }
+
+// IsGenericInstance 是否是泛型实例化类型
+func (gte *GoTypeExpr) IsGenericInstance() bool { return len(gte.typeArgs) > 0 }
+
+// Named 剥离所有指针后的命名类型: **pkg.ExampleStruct[T] -> pkg.ExampleStruct[T]
+// - 剥离后不是命名类型时返回 nil
+func (gte *GoTypeExpr) Named() *GoTypeExpr {
+	named := gte
+	for named != nil && named.kind == UNDERLYING_TYPE_POINTER {
+		named = named.elem
+	}
+	if named == nil || named.kind != UNDERLYING_TYPE_IDENT {
+		return nil
+	}
+	return named
+}
+
+// -------------------------------- unit test --------------------------------
+
+func (gte *GoTypeExpr) Kind() UnderlyingType    { return gte.kind }
+func (gte *GoTypeExpr) Ident() string           { return gte.ident }
+func (gte *GoTypeExpr) From() string            { return gte.from }
+func (gte *GoTypeExpr) ImportPath() string      { return gte.importPath }
+func (gte *GoTypeExpr) PackageMeta() *GoPackageMeta {
+	return gte.packageMeta
+}
+func (gte *GoTypeExpr) StructMeta() *GoStructMeta {
+	return gte.structMeta
+}
+func (gte *GoTypeExpr) InterfaceMeta() *GoInterfaceMeta {
+	return gte.interfaceMeta
+}
+func (gte *GoTypeExpr) TypeArgs() []*GoTypeExpr { return gte.typeArgs }
+func (gte *GoTypeExpr) Elem() *GoTypeExpr       { return gte.elem }
+func (gte *GoTypeExpr) Key() *GoTypeExpr        { return gte.key }
+func (gte *GoTypeExpr) Len() string             { return gte.length }
+func (gte *GoTypeExpr) ChanDir() ast.ChanDir    { return gte.chanDir }
+func (gte *GoTypeExpr) Params() []*GoVarMeta    { return gte.params }
+func (gte *GoTypeExpr) Returns() []*GoVarMeta   { return gte.returns }
+
+// -------------------------------- unit test --------------------------------
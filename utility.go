@@ -2,12 +2,16 @@ package extractor
 
 import (
 	"fmt"
+	"go/types"
 	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"unicode"
 	"unicode/utf8"
 )
 
@@ -485,3 +489,45 @@ func CreateDir(directoryPath string) error {
 	}
 	return nil
 }
+
+// isPredeclaredType 判断标识是否是内置类型: int, string, error, any 等
+func isPredeclaredType(ident string) bool {
+	_, ok := types.Universe.Lookup(ident).(*types.TypeName)
+	return ok
+}
+
+// sortedKeys 获取 map 的所有 key 并排序
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// isPredeclaredFunc 判断标识是否是内置 func: len, append, panic 等
+func isPredeclaredFunc(ident string) bool {
+	_, ok := types.Universe.Lookup(ident).(*types.Builtin)
+	return ok
+}
+
+// importPathAssumedName 根据导入路径推测 package 名称
+// - github.com/xxx/go-yyy/v2 -> yyy
+func importPathAssumedName(importPath string) string {
+	base := path.Base(importPath)
+	if strings.HasPrefix(base, "v") {
+		if _, err := strconv.Atoi(base[1:]); err == nil {
+			if dir := path.Dir(importPath); dir != "." {
+				base = path.Base(dir)
+			}
+		}
+	}
+	base = strings.TrimPrefix(base, "go-")
+	if index := strings.IndexFunc(base, func(r rune) bool {
+		return !(r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r))
+	}); index >= 0 {
+		base = base[:index]
+	}
+	return base
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"go/ast"
 	"go/parser"
-	"strings"
 )
 
 // type VariableTypeEnum int
@@ -59,16 +58,28 @@ type GoVarMeta struct {
 	// 类型表达式: *ExampleStruct[T]
 	typeExpression string
 
+	// 结构化的类型表达式
+	typeExpr *GoTypeExpr
+
 	// 是否是接口
+	// - interface 字面量以及内置的 any，error
+	// - 项目内的命名 interface，在解析类型表达式后确定
+	// - 项目外的命名 interface 无法识别: io.Reader
 	isInterface bool
 
 	// 是否是指针
 	isPointer bool
+
+	// 是否是可变参数: ...T
+	isVariadic bool
+
+	// var 的文档注释和行尾注释
+	commentMeta
 }
 
 // newGoVarMeta 通过 ast 构造 var 的 meta 数据
 func newGoVarMeta(m *meta, ident string, stopExtract ...bool) *GoVarMeta {
-	gvm := &GoVarMeta{meta: m, ident: ident}
+	gvm := &GoVarMeta{meta: m, ident: ident, commentMeta: newCommentMeta(m.node)}
 	if len(stopExtract) == 0 {
 		gvm.ExtractAll()
 	}
@@ -80,6 +91,26 @@ func newGoVarMeta(m *meta, ident string, stopExtract ...bool) *GoVarMeta {
 func (gvm *GoVarMeta) Ident() string          { return gvm.ident }
 func (gvm *GoVarMeta) TypeIdent() string      { return gvm.typeIdent }
 func (gvm *GoVarMeta) TypeExpression() string { return gvm.typeExpression }
+func (gvm *GoVarMeta) TypeExpr() *GoTypeExpr  { return gvm.typeExpr }
+func (gvm *GoVarMeta) IsPointer() bool        { return gvm.isPointer }
+func (gvm *GoVarMeta) IsInterface() bool      { return gvm.isInterface }
+func (gvm *GoVarMeta) IsVariadic() bool       { return gvm.isVariadic }
+
+// IsUnnamed 是否是未命名的参数或返回值: func(int)
+func (gvm *GoVarMeta) IsUnnamed() bool { return len(gvm.ident) == 0 }
+
+// IsBlank 是否是空白标识符: func(_ int)
+func (gvm *GoVarMeta) IsBlank() bool { return gvm.ident == "_" }
+
+// Directives 获取 var 的文档注释内的 编译指令: //go:embed，//go:linkname 等
+func (gvm *GoVarMeta) Directives() []*GoDirectiveMeta {
+	return extractDirectiveMetas(gvm.meta, gvm.doc)
+}
+
+// Annotations 使用注解解析器获取 var 的文档注释内的所有注解
+func (gvm *GoVarMeta) Annotations(parsers ...AnnotationParser) ([]*GoAnnotationMeta, []*GoAnnotationError) {
+	return extractAnnotationMetas(gvm.meta, gvm, gvm.doc, parsers)
+}
 
 // -------------------------------- unit test --------------------------------
 
@@ -125,7 +156,9 @@ func (gvm *GoVarMeta) extractType() {
 		return
 	}
 	// 取 type expression
-	gvm.typeExpression = newMeta(typeExpr, gvm.path).Expression()
+	gvm.typeExpression = gvm.copyMeta(typeExpr).Expression()
+	// 取结构化的 type expression
+	gvm.extractTypeExpr(typeExpr)
 	// 取 type ident
 	var (
 		nodeHandler          func(n ast.Node, post ...func(ast.Node) bool) bool
@@ -216,6 +249,37 @@ func (gvm *GoVarMeta) extractType() {
 	})
 }
 
+// extractTypeExpr 提取结构化的类型表达式，不依赖源文件
+func (gvm *GoVarMeta) extractTypeExpr(typeExpr ast.Expr) {
+	gvm.typeExpr = newGoTypeExpr(gvm.copyMeta(typeExpr))
+	gvm.isPointer = gvm.typeExpr.IsPointer()
+	gvm.isVariadic = gvm.typeExpr.kind == UNDERLYING_TYPE_ELLIPSIS
+	gvm.isInterface = gvm.typeExpr.kind == UNDERLYING_TYPE_INTERFACE ||
+		(gvm.typeExpr.IsBuiltin() && (gvm.typeExpr.ident == "any" || gvm.typeExpr.ident == "error"))
+}
+
+// extractFieldListVarMeta 按照参数位置提取 field list 中所有 var 的 meta 数据
+// - 未命名: func(int, string) -> 2 个标识为空的 var
+// - 合并命名: func(a, b int) -> 2 个共享同一 *ast.Field 的 var
+// - 空白标识符: func(_ int) -> 标识为 _ 的 var
+// - 可变参数: func(vs ...int) -> IsVariadic
+func extractFieldListVarMeta(m *meta, fieldList *ast.FieldList) []*GoVarMeta {
+	if fieldList == nil || len(fieldList.List) == 0 {
+		return nil
+	}
+	gvms := make([]*GoVarMeta, 0, fieldList.NumFields())
+	for _, field := range fieldList.List {
+		if len(field.Names) > 0 {
+			for _, name := range field.Names {
+				gvms = append(gvms, newGoVarMeta(m.copyMeta(field), name.String()))
+			}
+		} else {
+			gvms = append(gvms, newGoVarMeta(m.copyMeta(field), ""))
+		}
+	}
+	return gvms
+}
+
 // -------------------------------- extractor --------------------------------
 
 // -------------------------------- maker --------------------------------
@@ -232,6 +296,7 @@ func MakeUpVarMeta(ident, typeExpression string) *GoVarMeta {
 	}
 
 	gvm := newGoVarMeta(newMeta(astField, ""), ident, true)
+	gvm.extractTypeExpr(typeExpr)
 
 	return gvm
 }
@@ -247,6 +312,10 @@ func (gvm *GoVarMeta) make() *ast.Field {
 	case *ast.Field:
 		typeExpr = node.Type
 	}
+	if len(gvm.ident) == 0 {
+		// 未命名的参数或返回值
+		return &ast.Field{Type: typeExpr}
+	}
 	return &ast.Field{
 		Names: []*ast.Ident{ast.NewIdent(gvm.ident)},
 		Type:  typeExpr,
@@ -255,42 +324,19 @@ func (gvm *GoVarMeta) make() *ast.Field {
 
 // -------------------------------- maker --------------------------------
 
-// func (gvm *GoVarMeta[T]) Tag() string {
-// 	if gvm.node.(*ast.Field).Tag == nil {
-// 		return ""
-// 	}
-// 	return gvm.node.(*ast.Field).Tag.Value
-// }
-
-// func (gvm *GoVarMeta[T]) Doc() []string {
-// 	if gvm.node.(*ast.Field).Doc == nil {
-// 		return nil
-// 	}
-// 	commentSlice := make([]string, 0, len(gvm.node.(*ast.Field).Doc.List))
-// 	for _, comment := range gvm.node.(*ast.Field).Doc.List {
-// 		commentSlice = append(commentSlice, comment.Text)
-// 	}
-// 	return commentSlice
-// }
-
-// func (gvm *GoVarMeta[T]) Comment() string {
-// 	if gvm.node.(*ast.Field).Comment == nil || len(gvm.node.(*ast.Field).Comment.List) == 0 {
-// 		return ""
-// 	}
-// 	return gvm.node.(*ast.Field).Comment.List[0].Text
-// }
-
 type UnderlyingType int
 
 const (
 	UNDERLYING_TYPE_IDENT     = iota + 1 // any others *ast.Ident
-	UNDERLYING_TYPE_ARRAY                // *ast.ArrayType
+	UNDERLYING_TYPE_ARRAY                // *ast.ArrayType with Len
 	UNDERLYING_TYPE_STRUCT               // *ast.StructType
 	UNDERLYING_TYPE_POINTER              // *ast.StarExpr
 	UNDERLYING_TYPE_FUNC                 // *ast.FuncType
 	UNDERLYING_TYPE_INTERFACE            // *ast.InterfaceType
 	UNDERLYING_TYPE_MAP                  // *ast.MapType
 	UNDERLYING_TYPE_CHAN                 // *ast.ChanType
+	UNDERLYING_TYPE_SLICE                // *ast.ArrayType without Len
+	UNDERLYING_TYPE_ELLIPSIS             // *ast.Ellipsis
 )
 
 // func (gvm *GoVarMeta[T]) Type() (string, string, UnderlyingType) {
@@ -316,18 +362,6 @@ const (
 // 	return gvm.typeMeta.Expression(), underlyingString, underlyingEnum
 // }
 
-func traitFrom(expression string, isPointer bool) string {
-	if isPointer {
-		expression = expression[1:]
-	}
-	expressionSlice := strings.Split(expression, ".")
-	l := len(expressionSlice)
-	if l > 1 {
-		return strings.Join(expressionSlice[:l-1], ".")
-	}
-	return ""
-}
-
 // func ExtractGoVariableMeta(extractFilepath string, variableName string) (*GoVariableMeta, error) {
 // 	goFileMeta, err := ExtractGoFileMeta(extractFilepath)
 // 	if err != nil {